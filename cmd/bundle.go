@@ -0,0 +1,230 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+	"github.com/ShadowZagrosDev/oblivion-helper/pkg/client"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// bundlePrefix names the debug bundles, which are written next to the executable as sbDebug-YYYYMMDD-HHMMSS.zip
+const bundlePrefix = "sbDebug-"
+
+// redactedKeys are config keys whose values are replaced in debug bundles
+var redactedKeys = map[string]bool{
+	"password":       true,
+	"uuid":           true,
+	"private_key":    true,
+	"pre_shared_key": true,
+	"psk":            true,
+	"token":          true,
+	"secret":         true,
+	"auth":           true,
+	"auth_str":       true,
+	"key":            true,
+	"license":        true,
+	"license_key":    true,
+}
+
+// redactConfig replaces secrets in a JSON config. Content that is not valid JSON is left out entirely.
+func redactConfig(content []byte) []byte {
+	var config any
+	if err := json.Unmarshal(content, &config); err != nil {
+		return []byte("(unreadable, omitted)\n")
+	}
+
+	var redact func(value any) any
+	redact = func(value any) any {
+		switch value := value.(type) {
+		case map[string]any:
+			for key, item := range value {
+				if redactedKeys[strings.ToLower(key)] {
+					value[key] = "REDACTED"
+				} else {
+					value[key] = redact(item)
+				}
+			}
+		case []any:
+			for i, item := range value {
+				value[i] = redact(item)
+			}
+		}
+		return value
+	}
+
+	redacted, err := json.MarshalIndent(redact(config), "", "  ")
+	if err != nil {
+		return []byte("(unreadable, omitted)\n")
+	}
+	return redacted
+}
+
+// writeLogsBundle zips the given in-memory logs together with the helper's files in dirPath
+// and returns the path of the archive
+func writeLogsBundle(dirPath string, logs map[string]string) (string, error) {
+	bundlePath := filepath.Join(dirPath, bundlePrefix+time.Now().Format("20060102-150405")+".zip")
+	file, err := os.Create(bundlePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create bundle: %w", err)
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+	add := func(name string, content []byte) error {
+		writer, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = writer.Write(content)
+		return err
+	}
+
+	for name, content := range logs {
+		if err := add(name, []byte(content)); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	system := fmt.Sprintf("version: %s\nos: %s %s\narch: %s\ngo: %s\n", Version, runtime.GOOS, osVersion(), runtime.GOARCH, runtime.Version())
+	if err := add("system.txt", []byte(system)); err != nil {
+		return "", fmt.Errorf("failed to write system.txt: %w", err)
+	}
+
+	for _, name := range []string{configFileName, exportListFileName, stateFileName, reliabilityFileName, journalFileName} {
+		content, err := os.ReadFile(filepath.Join(dirPath, name))
+		if err != nil {
+			continue
+		}
+		if strings.HasSuffix(name, ".json") {
+			content = redactConfig(content)
+		}
+		if err := add(name, content); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return "", fmt.Errorf("failed to finish bundle: %w", err)
+	}
+	return bundlePath, nil
+}
+
+// ExportLogsBundle handles the gRPC ExportLogsBundle request, saving recent helper and core logs, the status history
+// and the redacted configs to a zip archive
+func (s *Server) ExportLogsBundle(ctx context.Context, req *pb.ExportLogsBundleRequest) (*pb.ExportLogsBundleResponse, error) {
+	files := map[string]string{
+		"helper.log":  helperLogs.String(),
+		"core.log":    s.coreLogs.String(),
+		"status.log":  s.statusHistory.String(),
+		"network.txt": describeInterfaces(),
+	}
+	if config, err := s.GetConfig(ctx, &pb.GetConfigRequest{Redact: true}); err == nil {
+		files["active-config.json"] = config.Config
+	} else {
+		files["active-config.json"] = fmt.Sprintf("(unavailable: %v)\n", err)
+	}
+	if rulesets, err := s.GetRulesetInfo(ctx, &pb.GetRulesetInfoRequest{}); err == nil {
+		content, _ := json.MarshalIndent(rulesets.Files, "", "  ")
+		files["rulesets.json"] = string(content)
+	} else {
+		files["rulesets.json"] = fmt.Sprintf("(unavailable: %v)\n", err)
+	}
+
+	bundlePath, err := writeLogsBundle(s.dirPath, files)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	s.logger.info.Printf("Debug bundle saved to %s", bundlePath)
+	return &pb.ExportLogsBundleResponse{Path: bundlePath}, nil
+}
+
+// describeInterfaces lists the network interfaces with their flags, MTU and addresses, and the one the OS
+// routes internet traffic through
+func describeInterfaces() string {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return fmt.Sprintf("(unavailable: %v)\n", err)
+	}
+
+	var builder strings.Builder
+	if iface, err := defaultInterface(); err == nil && iface != nil {
+		fmt.Fprintf(&builder, "default: %s\n\n", iface.Name)
+	}
+	for _, iface := range interfaces {
+		fmt.Fprintf(&builder, "%s (index %d, mtu %d, %s)\n", iface.Name, iface.Index, iface.MTU, iface.Flags)
+		addrs, _ := iface.Addrs()
+		for _, addr := range addrs {
+			fmt.Fprintf(&builder, "  %s\n", addr)
+		}
+	}
+	return builder.String()
+}
+
+// runBundleCommand implements the "bundle" command. It asks a running helper for a bundle so the in-memory logs
+// are included, and falls back to bundling the files on disk. The helper is reached at the -listen address
+// and, with -tls, with the client certificate of the local CA, as the helper itself was started.
+func runBundleCommand(logger *Logger, args []string) {
+	commandFlags := flag.NewFlagSet("bundle", flag.ExitOnError)
+	listenAddress := commandFlags.String("listen", serverAddress, "gRPC address the helper listens on")
+	useTLS := commandFlags.Bool("tls", false, "connect over TLS, for a helper started with -tls")
+	commandFlags.Parse(args)
+
+	dirPath, err := getExecutableDir()
+	if err != nil {
+		logger.fatal.Fatalf("%v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	dialOptions := []client.Option{client.WithRetries(0, 0)}
+	if *useTLS {
+		dialOptions = append(dialOptions, client.WithTLS(filepath.Join(dirPath, tlsFolderName)))
+	}
+	c, err := client.Dial(*listenAddress, dialOptions...)
+	if err == nil {
+		var resp *pb.ExportLogsBundleResponse
+		resp, err = c.OblivionServiceClient.ExportLogsBundle(ctx, &pb.ExportLogsBundleRequest{})
+		c.Close()
+		if err == nil {
+			logger.info.Printf("Debug bundle saved to %s", resp.Path)
+			return
+		}
+	}
+	logger.warn.Printf("Helper not reachable, bundling files only: %v", err)
+
+	bundlePath, err := writeLogsBundle(dirPath, nil)
+	if err != nil {
+		logger.fatal.Fatalf("%v", err)
+	}
+	logger.info.Printf("Debug bundle saved to %s", bundlePath)
+}
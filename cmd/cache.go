@@ -0,0 +1,183 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	option "github.com/sagernet/sing-box/option"
+
+	"go.etcd.io/bbolt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultCacheFileName is the cache file sing-box uses when the config gives no path
+const defaultCacheFileName = "cache.db"
+
+// cacheFileOptions returns whether the cache file of the given options is enabled and its absolute path.
+// Relative paths are resolved like sing-box does, against the working directory.
+func cacheFileOptions(options *option.Options) (bool, string) {
+	var enabled bool
+	path := defaultCacheFileName
+	if options != nil && options.Experimental != nil && options.Experimental.CacheFile != nil {
+		enabled = options.Experimental.CacheFile.Enabled
+		if options.Experimental.CacheFile.Path != "" {
+			path = options.Experimental.CacheFile.Path
+		}
+	}
+	if absolute, err := filepath.Abs(path); err == nil {
+		path = absolute
+	}
+	return enabled, path
+}
+
+// setCacheFile turns the cache file of the config on or off
+func setCacheFile(config map[string]any, enabled bool) {
+	experimental, _ := config["experimental"].(map[string]any)
+	if experimental == nil {
+		experimental = make(map[string]any)
+		config["experimental"] = experimental
+	}
+	cacheFile, _ := experimental["cache_file"].(map[string]any)
+	if cacheFile == nil {
+		cacheFile = make(map[string]any)
+		experimental["cache_file"] = cacheFile
+	}
+	cacheFile["enabled"] = enabled
+}
+
+// cacheFileResponse describes the cache file of the given options: its size and, unless sing-box holds it open,
+// the number of entries in each bucket (selections, clash mode, fake-ip mappings, rule-sets)
+func cacheFileResponse(options *option.Options) *pb.CacheFileResponse {
+	enabled, path := cacheFileOptions(options)
+	response := &pb.CacheFileResponse{Enabled: enabled, Path: path}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return response
+	}
+	response.Exists = true
+	response.Size = info.Size()
+	response.ModifiedAt = info.ModTime().Unix()
+
+	db, err := bbolt.Open(path, 0o644, &bbolt.Options{Timeout: 200 * time.Millisecond, ReadOnly: true})
+	if err != nil {
+		response.InUse = true
+		return response
+	}
+	defer db.Close()
+
+	db.View(func(tx *bbolt.Tx) error {
+		return tx.ForEach(func(name []byte, bucket *bbolt.Bucket) error {
+			response.Buckets = append(response.Buckets, &pb.CacheBucket{Name: string(name), Keys: int64(bucket.Stats().KeyN)})
+			return nil
+		})
+	})
+	return response
+}
+
+// currentOptions returns the options of the running instance, or those of the config file while stopped
+func (s *Server) currentOptions() (*option.Options, error) {
+	s.mu.RLock()
+	options := s.core.Options()
+	running := s.core.Running()
+	s.mu.RUnlock()
+	if running {
+		return options, nil
+	}
+	return s.loadSingBoxConfig()
+}
+
+// GetCacheFile handles the gRPC GetCacheFile request, summarizing sing-box's cache file
+func (s *Server) GetCacheFile(ctx context.Context, req *pb.GetCacheFileRequest) (*pb.CacheFileResponse, error) {
+	options, err := s.currentOptions()
+	if err != nil {
+		return nil, err
+	}
+	return cacheFileResponse(options), nil
+}
+
+// ClearCacheFile handles the gRPC ClearCacheFile request, deleting sing-box's cache file.
+// A running instance is stopped while the file is deleted and started again with the same options.
+func (s *Server) ClearCacheFile(ctx context.Context, req *pb.ClearCacheFileRequest) (*pb.CacheFileResponse, error) {
+	options, err := s.currentOptions()
+	if err != nil {
+		return nil, err
+	}
+	_, path := cacheFileOptions(options)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	running := s.core.Running()
+	if running {
+		options = s.core.Options()
+		s.broadcastStatus("reloading")
+		if err := s.stopInstance(); err != nil {
+			return nil, err
+		}
+	}
+
+	removeErr := os.Remove(path)
+	if os.IsNotExist(removeErr) {
+		removeErr = nil
+	}
+
+	if running {
+		if err := s.startInstance(context.Background(), options); err != nil {
+			s.reliability.failed(err, true)
+			s.reliability.disconnected()
+			s.broadcastStopped(stopReasonCrash)
+			return nil, err
+		}
+		s.broadcastStatus("started")
+	}
+
+	if removeErr != nil {
+		return nil, status.Errorf(codes.Internal, "failed to delete the cache file: %v", removeErr)
+	}
+	s.logger.info.Printf("Cache file %s cleared", path)
+	return cacheFileResponse(options), nil
+}
+
+// SetCacheFile handles the gRPC SetCacheFile request, enabling or disabling sing-box's cache file.
+// The choice overrides the config, is persisted and is applied by restarting sing-box if it is running.
+func (s *Server) SetCacheFile(ctx context.Context, req *pb.SetCacheFileRequest) (*pb.CacheFileResponse, error) {
+	enabled := req.Enabled
+	err := s.updateState(func(state *HelperState) {
+		state.CacheFile = &enabled
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	s.logger.info.Printf("Cache file enabled: %v", enabled)
+	if err := s.restartIfRunning(); err != nil {
+		return nil, err
+	}
+
+	options, err := s.currentOptions()
+	if err != nil {
+		return nil, err
+	}
+	return cacheFileResponse(options), nil
+}
@@ -0,0 +1,199 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Transitions clients can hook into with RegisterHook
+const (
+	callbackPreStart  = "pre-start"  // Before sing-box starts; a veto cancels the start
+	callbackPostStart = "post-start" // After sing-box started; replies are not waited for
+	callbackPreStop   = "pre-stop"   // Before sing-box stops; a veto cancels a stop requested by the user
+)
+
+// Bounds of the time a transition waits for a client reply
+const (
+	callbackDefaultTimeout = 5 * time.Second
+	callbackMaxTimeout     = time.Minute
+)
+
+// callbackSubscriber is a client connected through RegisterHook
+type callbackSubscriber struct {
+	events  map[string]bool
+	timeout time.Duration
+	sendMu  sync.Mutex // Serializes sends on the stream
+	stream  pb.OblivionService_RegisterHookServer
+	mu      sync.Mutex
+	pending map[string]chan *pb.HookReply // Reply channels by event ID
+}
+
+// callbackRegistry holds the clients connected through RegisterHook
+type callbackRegistry struct {
+	mu          sync.Mutex
+	subscribers map[*callbackSubscriber]bool
+	nextID      atomic.Uint64
+}
+
+// send delivers an event to the subscriber, returning the channel its reply arrives on
+func (c *callbackSubscriber) send(event *pb.HookEvent) (chan *pb.HookReply, error) {
+	reply := make(chan *pb.HookReply, 1)
+	c.mu.Lock()
+	c.pending[event.Id] = reply
+	c.mu.Unlock()
+
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	if err := c.stream.Send(event); err != nil {
+		c.forget(event.Id)
+		return nil, err
+	}
+	return reply, nil
+}
+
+// forget drops the reply channel of an event
+func (c *callbackSubscriber) forget(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.pending, id)
+}
+
+// deliver routes a reply to the transition waiting for it
+func (c *callbackSubscriber) deliver(reply *pb.HookReply) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if pending, found := c.pending[reply.Id]; found {
+		pending <- reply
+		delete(c.pending, reply.Id)
+	}
+}
+
+// subscribersOf returns the clients hooked into a transition
+func (s *Server) subscribersOf(event string) []*callbackSubscriber {
+	s.callbacks.mu.Lock()
+	defer s.callbacks.mu.Unlock()
+
+	var subscribers []*callbackSubscriber
+	for subscriber := range s.callbacks.subscribers {
+		if subscriber.events[event] {
+			subscribers = append(subscribers, subscriber)
+		}
+	}
+	return subscribers
+}
+
+// runCallbacks sends a transition to the hooked clients one after another and waits for each reply,
+// up to the client's timeout, so clients can finish their own work first. If vetoable, a client that
+// vetoes stops the transition with an ABORTED error. A client that does not answer in time is skipped.
+func (s *Server) runCallbacks(event, detail string, vetoable bool) error {
+	for _, subscriber := range s.subscribersOf(event) {
+		id := strconv.FormatUint(s.callbacks.nextID.Add(1), 10)
+		reply, err := subscriber.send(&pb.HookEvent{Id: id, Event: event, Detail: detail, Vetoable: vetoable})
+		if err != nil {
+			s.logger.warn.Printf("Failed to send %s hook: %v", event, err)
+			continue
+		}
+
+		select {
+		case answer := <-reply:
+			if answer.Veto && vetoable {
+				s.logger.warn.Printf("Client vetoed %s: %s", event, answer.Reason)
+				return status.Errorf(codes.Aborted, "%s vetoed by client: %s", event, answer.Reason)
+			}
+		case <-time.After(subscriber.timeout):
+			subscriber.forget(id)
+			s.logger.warn.Printf("Client did not answer the %s hook within %s, continuing", event, subscriber.timeout)
+		}
+	}
+	return nil
+}
+
+// notifyCallbacks sends a transition to the hooked clients without waiting for replies
+func (s *Server) notifyCallbacks(event, detail string) {
+	for _, subscriber := range s.subscribersOf(event) {
+		id := strconv.FormatUint(s.callbacks.nextID.Add(1), 10)
+		if _, err := subscriber.send(&pb.HookEvent{Id: id, Event: event, Detail: detail}); err != nil {
+			s.logger.warn.Printf("Failed to send %s hook: %v", event, err)
+			continue
+		}
+		subscriber.forget(id)
+	}
+}
+
+// RegisterHook handles the gRPC RegisterHook stream. The client first sends the transitions it hooks into
+// (pre-start, post-start, pre-stop) and a reply timeout, then answers every HookEvent with a HookReply
+// carrying its ID. A transition waits for the reply, so the client can do its own work first, and a
+// vetoable transition is cancelled when the reply vetoes it. The hooks last until the stream ends.
+func (s *Server) RegisterHook(stream pb.OblivionService_RegisterHookServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	registration := first.GetRegister()
+	if registration == nil {
+		return status.Errorf(codes.InvalidArgument, "the first message must be a registration")
+	}
+
+	subscriber := &callbackSubscriber{
+		events:  make(map[string]bool),
+		timeout: callbackDefaultTimeout,
+		stream:  stream,
+		pending: make(map[string]chan *pb.HookReply),
+	}
+	for _, event := range registration.Events {
+		switch event {
+		case callbackPreStart, callbackPostStart, callbackPreStop:
+			subscriber.events[event] = true
+		default:
+			return status.Errorf(codes.InvalidArgument, "unknown hook %q, expected pre-start, post-start or pre-stop", event)
+		}
+	}
+	if registration.TimeoutMs > 0 {
+		subscriber.timeout = min(time.Duration(registration.TimeoutMs)*time.Millisecond, callbackMaxTimeout)
+	}
+
+	s.callbacks.mu.Lock()
+	if s.callbacks.subscribers == nil {
+		s.callbacks.subscribers = make(map[*callbackSubscriber]bool)
+	}
+	s.callbacks.subscribers[subscriber] = true
+	s.callbacks.mu.Unlock()
+	defer func() {
+		s.callbacks.mu.Lock()
+		delete(s.callbacks.subscribers, subscriber)
+		s.callbacks.mu.Unlock()
+	}()
+
+	s.logger.info.Printf("Client hooked into %v with a %s timeout", registration.Events, subscriber.timeout)
+	for {
+		message, err := stream.Recv()
+		if err != nil {
+			return nil // The client left; its hooks are removed
+		}
+		if reply := message.GetReply(); reply != nil {
+			subscriber.deliver(reply)
+		}
+	}
+}
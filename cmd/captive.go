@@ -0,0 +1,112 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Captive portal detection parameters
+const (
+	captivePortalProbeURL      = "http://connectivitycheck.gstatic.com/generate_204" // Returns 204 with no body on an open network
+	captivePortalProbeTimeout  = 5 * time.Second                                     // Timeout of a single probe
+	captivePortalRetryInterval = 5 * time.Second                                     // Delay between probes while a portal is active
+	captivePortalBodyLimit     = 4096                                                // Bytes of a 200 answer inspected for a login page
+)
+
+// detectCaptivePortal probes an endpoint that always answers 204 No Content. Only a redirect or a 200 answer
+// carrying an HTML page, which is how portals serve their login page, counts as a portal; other status codes
+// and reset connections are usually censorship or an outage, where holding the tunnel back would not help.
+func detectCaptivePortal(ctx context.Context) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, captivePortalProbeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, captivePortalProbeURL, nil)
+	if err != nil {
+		return false, err
+	}
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse // The redirect itself is the sign of a portal
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= http.StatusMultipleChoices && resp.StatusCode < http.StatusBadRequest:
+		return resp.Header.Get("Location") != "", nil
+	case resp.StatusCode == http.StatusOK:
+		body, err := io.ReadAll(io.LimitReader(resp.Body, captivePortalBodyLimit))
+		if err != nil {
+			return false, err
+		}
+		return isHTMLPage(resp.Header.Get("Content-Type"), body), nil
+	default:
+		return false, nil
+	}
+}
+
+// isHTMLPage reports whether a response is an HTML page, by its content type or by sniffing the body
+func isHTMLPage(contentType string, body []byte) bool {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return false
+	}
+	if contentType == "" {
+		contentType = http.DetectContentType(body)
+	}
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(contentType)), "text/html")
+}
+
+// waitForCaptivePortal returns once no captive portal intercepts the traffic. While a portal is
+// detected it broadcasts "captive-portal" so the user can complete the login page.
+// A failing probe is not treated as a portal, so an unreachable probe endpoint never blocks the start.
+func (s *Server) waitForCaptivePortal(ctx context.Context) error {
+	announced := false
+	for {
+		portal, err := detectCaptivePortal(ctx)
+		if err != nil && !announced {
+			return nil
+		}
+		if err == nil && !portal {
+			if announced {
+				s.logger.info.Println("Captive portal cleared")
+			}
+			return nil
+		}
+
+		if portal && !announced {
+			s.logger.warn.Println("Captive portal detected, waiting for login...")
+			s.broadcastStatus("captive-portal")
+			announced = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(captivePortalRetryInterval):
+		}
+	}
+}
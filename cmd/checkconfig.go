@@ -0,0 +1,178 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+	"github.com/ShadowZagrosDev/oblivion-helper/internal/config"
+
+	box "github.com/sagernet/sing-box"
+	option "github.com/sagernet/sing-box/option"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// checkConfig validates options by creating a sing-box instance without starting it, so no inbound, TUN device
+// or outbound connection is opened. It returns the failing component and the error, or empty strings if valid.
+func checkConfig(options *option.Options) (string, error) {
+	for _, ruleSet := range inspectRuleSets(options) {
+		if ruleSet.Type != "local" {
+			continue
+		}
+		if _, err := os.Stat(ruleSet.Path); err != nil {
+			return fmt.Sprintf("rule_set[%s]", ruleSet.Tag), fmt.Errorf("rule-set file unavailable: %w", err)
+		}
+	}
+
+	return checkInstance(options)
+}
+
+// checkInstance creates a sing-box instance from options without starting it, leaving out the rule-set files,
+// which sing-box only reads on start. It returns the failing component and the error, or empty strings if valid.
+func checkInstance(options *option.Options) (string, error) {
+	instance, err := box.New(box.Options{
+		Options: *options,
+		Context: context.Background(),
+	})
+	if err != nil {
+		// sing-box prefixes errors with the component, e.g. "parse outbound[2]: ..." or "initialize router: ..."
+		component, _, _ := strings.Cut(err.Error(), ": ")
+		return component, err
+	}
+	instance.Close()
+	return "", nil
+}
+
+// Severities of the issues reported by TestConfig
+const (
+	issueError   = "error"   // The config cannot start
+	issueWarning = "warning" // The config starts but probably not as intended
+)
+
+// configWarnings finds problems that do not prevent the options from starting
+func (s *Server) configWarnings(options *option.Options) []*pb.ConfigIssue {
+	var warnings []*pb.ConfigIssue
+	inbounds := inspectInbounds(options)
+	if len(inbounds) == 0 {
+		warnings = append(warnings, &pb.ConfigIssue{Severity: issueWarning, Component: "inbounds", Message: "no inbound is configured, no traffic will enter the tunnel"})
+	}
+
+	s.mu.RLock()
+	running := s.core.Running()
+	s.mu.RUnlock()
+	for i, inbound := range inbounds {
+		component := fmt.Sprintf("inbound[%d]", i)
+		if inbound.Tag != "" {
+			component = fmt.Sprintf("inbound[%s]", inbound.Tag)
+		}
+		if inbound.Type == "tun" && !s.elevated {
+			warnings = append(warnings, &pb.ConfigIssue{Severity: issueWarning, Component: component, Message: "a TUN inbound needs the helper to run elevated"})
+		}
+		// While running, the ports are held by the running instance itself
+		if !running && inbound.ListenPort > 0 {
+			if err := portAvailable(inbound.Listen, inbound.ListenPort); err != nil {
+				warnings = append(warnings, &pb.ConfigIssue{Severity: issueWarning, Component: component, Message: fmt.Sprintf("port %d is in use: %v", inbound.ListenPort, err)})
+			}
+		}
+	}
+
+	for _, ruleSet := range inspectRuleSets(options) {
+		if strings.HasSuffix(ruleSet.Path, fallbackSuffix) {
+			warnings = append(warnings, &pb.ConfigIssue{
+				Severity:  issueWarning,
+				Component: fmt.Sprintf("rule_set[%s]", ruleSet.Tag),
+				Message:   "rule-set file is missing, the bundled snapshot is used instead",
+			})
+		}
+	}
+	return warnings
+}
+
+// TestConfig handles the gRPC TestConfig request, checking a config without touching the running session.
+// The config is given as JSON; an empty config checks sbConfig.json. Besides the error that prevents a start,
+// if any, warnings are listed for problems that let the config start but probably not as intended.
+func (s *Server) TestConfig(ctx context.Context, req *pb.TestConfigRequest) (*pb.TestConfigResponse, error) {
+	var options *option.Options
+	var err error
+	if req.Config == "" {
+		options, err = s.loadSingBoxConfig()
+	} else {
+		options, err = config.Parse([]byte(req.Config), s.applyConfigOverrides, s.applyRuleSetFallbacks)
+	}
+	if err != nil {
+		return &pb.TestConfigResponse{
+			Component: "config",
+			Error:     err.Error(),
+			Issues:    []*pb.ConfigIssue{{Severity: issueError, Component: "config", Message: err.Error()}},
+		}, nil
+	}
+
+	response := &pb.TestConfigResponse{Valid: true}
+	if component, err := checkConfig(options); err != nil {
+		s.logger.warn.Printf("Config check failed in %s: %v", component, err)
+		response = &pb.TestConfigResponse{Component: component, Error: err.Error()}
+		response.Issues = append(response.Issues, &pb.ConfigIssue{Severity: issueError, Component: component, Message: err.Error()})
+	}
+	response.Issues = append(response.Issues, s.configWarnings(options)...)
+	return response, nil
+}
+
+// SetConfig handles the gRPC SetConfig request, replacing sbConfig.json with the given config.
+// The config is checked like TestConfig and rejected with the failing component if invalid, then written atomically.
+// With apply set, a running sing-box switches to it right away, keeping the previous config if it fails to start.
+func (s *Server) SetConfig(ctx context.Context, req *pb.SetConfigRequest) (*pb.SetConfigResponse, error) {
+	if strings.TrimSpace(req.Config) == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "config is empty")
+	}
+
+	options, err := config.Parse([]byte(req.Config), s.applyConfigOverrides, s.applyRuleSetFallbacks)
+	if err != nil {
+		return &pb.SetConfigResponse{Component: "config", Error: err.Error()}, nil
+	}
+	if component, err := checkConfig(options); err != nil {
+		s.logger.warn.Printf("Rejected config, check failed in %s: %v", component, err)
+		return &pb.SetConfigResponse{Component: component, Error: err.Error()}, nil
+	}
+
+	if err := writeFileAtomic(s.configPath(), []byte(req.Config)); err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+	s.logger.info.Printf("%s replaced through the API", filepath.Base(s.configPath()))
+
+	response := &pb.SetConfigResponse{Valid: true, Saved: true}
+	if !req.Apply {
+		return response, nil
+	}
+
+	s.mu.RLock()
+	running := s.core.Running()
+	s.mu.RUnlock()
+	if !running {
+		return response, nil
+	}
+	if err := s.reloadSingBox(false, "reloading"); err != nil {
+		return nil, err
+	}
+	response.Applied = true
+	return response, nil
+}
@@ -0,0 +1,220 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"strings"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	"github.com/sagernet/sing-box/adapter"
+	"github.com/sagernet/sing-box/experimental/clashapi"
+	option "github.com/sagernet/sing-box/option"
+	"github.com/sagernet/sing-box/outbound"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// enableClashAPI makes sure sing-box creates its clash API server, which provides the clash mode,
+// outbound selection and traffic accounting. Without an external controller no HTTP listener is opened.
+func enableClashAPI(options *option.Options) {
+	if options.Experimental == nil {
+		options.Experimental = &option.ExperimentalOptions{}
+	}
+	if options.Experimental.ClashAPI == nil {
+		options.Experimental.ClashAPI = &option.ClashAPIOptions{}
+	}
+}
+
+// clashServer returns the clash API server of the running instance; the caller must hold s.mu
+func (s *Server) clashServer() (*clashapi.Server, error) {
+	if !s.core.Running() {
+		return nil, status.Errorf(codes.FailedPrecondition, "sing-box is not running")
+	}
+
+	server, ok := s.core.Instance().Router().ClashServer().(*clashapi.Server)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "clash API is not available in this build")
+	}
+	return server, nil
+}
+
+// restoreSelections applies the persisted clash mode and outbound selections to the running instance;
+// the caller must hold s.mu
+func (s *Server) restoreSelections() {
+	state, err := s.loadState()
+	if err != nil {
+		s.logger.error.Printf("Failed to load saved selections: %v", err)
+		return
+	}
+
+	if state.ClashMode != "" {
+		if server, err := s.clashServer(); err == nil {
+			server.SetMode(state.ClashMode)
+		}
+	}
+
+	for group, selected := range state.Selections {
+		abstractGroup, loaded := s.core.Instance().Router().Outbound(group)
+		if !loaded {
+			continue
+		}
+		if selector, isSelector := abstractGroup.(*outbound.Selector); isSelector && !selector.SelectOutbound(selected) {
+			s.logger.warn.Printf("Saved outbound %s no longer exists in group %s", selected, group)
+		}
+	}
+}
+
+// GetClashMode handles the gRPC GetClashMode request, returning the current and available clash modes
+func (s *Server) GetClashMode(ctx context.Context, req *pb.GetClashModeRequest) (*pb.ClashModeResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	server, err := s.clashServer()
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ClashModeResponse{Mode: server.Mode(), Modes: clashModes(server.ModeList())}, nil
+}
+
+// SetClashMode handles the gRPC SetClashMode request, switching the clash mode and persisting the choice
+func (s *Server) SetClashMode(ctx context.Context, req *pb.SetClashModeRequest) (*pb.ClashModeResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	server, err := s.clashServer()
+	if err != nil {
+		return nil, err
+	}
+
+	if s.paused() {
+		return nil, status.Errorf(codes.FailedPrecondition, "the tunnel is paused, resume it first")
+	}
+
+	var mode string
+	for _, candidate := range clashModes(server.ModeList()) {
+		if strings.EqualFold(candidate, req.Mode) {
+			mode = candidate
+		}
+	}
+	if mode == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown clash mode %q, available: %s", req.Mode, strings.Join(clashModes(server.ModeList()), ", "))
+	}
+
+	server.SetMode(mode)
+	if err := s.updateState(func(state *HelperState) { state.ClashMode = mode }); err != nil {
+		s.logger.error.Printf("Failed to persist clash mode: %v", err)
+	}
+
+	s.logger.info.Printf("Clash mode set to %s", mode)
+	return &pb.ClashModeResponse{Mode: server.Mode(), Modes: clashModes(server.ModeList())}, nil
+}
+
+// GetOutboundGroups handles the gRPC GetOutboundGroups request, listing the selector and urltest groups
+func (s *Server) GetOutboundGroups(ctx context.Context, req *pb.GetOutboundGroupsRequest) (*pb.OutboundGroupsResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.core.Running() {
+		return nil, status.Errorf(codes.FailedPrecondition, "sing-box is not running")
+	}
+
+	resp := &pb.OutboundGroupsResponse{}
+	for _, detour := range s.core.Instance().Router().Outbounds() {
+		group, isGroup := detour.(adapter.OutboundGroup)
+		if !isGroup {
+			continue
+		}
+		resp.Groups = append(resp.Groups, &pb.OutboundGroup{
+			Tag:       detour.Tag(),
+			Type:      detour.Type(),
+			Selected:  group.Now(),
+			Outbounds: group.All(),
+		})
+	}
+	return resp, nil
+}
+
+// ListOutbounds handles the gRPC ListOutbounds request, listing every outbound of the running instance
+// with the groups it belongs to. Groups also report their selected outbound and whether it can be switched.
+func (s *Server) ListOutbounds(ctx context.Context, req *pb.ListOutboundsRequest) (*pb.ListOutboundsResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.core.Running() {
+		return nil, status.Errorf(codes.FailedPrecondition, "sing-box is not running")
+	}
+
+	outbounds := s.core.Instance().Router().Outbounds()
+	memberOf := make(map[string][]string) // Group tags by member tag
+	for _, detour := range outbounds {
+		if group, isGroup := detour.(adapter.OutboundGroup); isGroup {
+			for _, member := range group.All() {
+				memberOf[member] = append(memberOf[member], detour.Tag())
+			}
+		}
+	}
+
+	resp := &pb.ListOutboundsResponse{}
+	for _, detour := range outbounds {
+		info := &pb.OutboundInfo{Tag: detour.Tag(), Type: detour.Type(), Groups: memberOf[detour.Tag()]}
+		if group, isGroup := detour.(adapter.OutboundGroup); isGroup {
+			_, isSelector := detour.(*outbound.Selector)
+			info.Selected = group.Now()
+			info.Selectable = isSelector
+		}
+		resp.Outbounds = append(resp.Outbounds, info)
+	}
+	return resp, nil
+}
+
+// SelectOutbound handles the gRPC SelectOutbound request, switching a selector group without
+// restarting the tunnel and persisting the choice
+func (s *Server) SelectOutbound(ctx context.Context, req *pb.SelectOutboundRequest) (*pb.SelectOutboundResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.core.Running() {
+		return nil, status.Errorf(codes.FailedPrecondition, "sing-box is not running")
+	}
+
+	abstractGroup, loaded := s.core.Instance().Router().Outbound(req.Group)
+	if !loaded {
+		return nil, status.Errorf(codes.NotFound, "outbound group %s not found", req.Group)
+	}
+	selector, isSelector := abstractGroup.(*outbound.Selector)
+	if !isSelector {
+		return nil, status.Errorf(codes.InvalidArgument, "outbound %s is not a selector", req.Group)
+	}
+	if !selector.SelectOutbound(req.Outbound) {
+		return nil, status.Errorf(codes.NotFound, "outbound %s not found in group %s", req.Outbound, req.Group)
+	}
+
+	err := s.updateState(func(state *HelperState) {
+		if state.Selections == nil {
+			state.Selections = make(map[string]string)
+		}
+		state.Selections[req.Group] = req.Outbound
+	})
+	if err != nil {
+		s.logger.error.Printf("Failed to persist selection: %v", err)
+	}
+
+	s.logger.info.Printf("Selected outbound %s in group %s", req.Outbound, req.Group)
+	return &pb.SelectOutboundResponse{Message: "Outbound selected successfully."}, nil
+}
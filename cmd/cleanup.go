@@ -0,0 +1,135 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"google.golang.org/grpc"
+)
+
+// CleanupRegistry keeps track of system changes (system proxy, firewall rules, routes, temp files)
+// that must be reverted before the helper exits, whichever exit path is taken
+type CleanupRegistry struct {
+	mu     sync.Mutex    // Synchronizes access to the registered hooks
+	hooks  []cleanupHook // Registered cleanup actions, in registration order
+	logger *Logger       // Logger for cleanup messages
+}
+
+// cleanupHook is a named action that reverts a single system change
+type cleanupHook struct {
+	name string
+	fn   func() error
+}
+
+// NewCleanupRegistry creates an empty CleanupRegistry
+func NewCleanupRegistry(logger *Logger) *CleanupRegistry {
+	return &CleanupRegistry{logger: logger}
+}
+
+// Register adds a cleanup action, replacing any previous action with the same name
+func (c *CleanupRegistry) Register(name string, fn func() error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.remove(name)
+	c.hooks = append(c.hooks, cleanupHook{name: name, fn: fn})
+}
+
+// Unregister removes a cleanup action once the change it reverts has been undone
+func (c *CleanupRegistry) Unregister(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.remove(name)
+}
+
+// remove deletes the hook with the given name; the caller must hold c.mu
+func (c *CleanupRegistry) remove(name string) {
+	for i, hook := range c.hooks {
+		if hook.name == name {
+			c.hooks = append(c.hooks[:i], c.hooks[i+1:]...)
+			return
+		}
+	}
+}
+
+// Run executes all registered cleanup actions in reverse registration order.
+// Every action runs at most once, and a failing action does not prevent the remaining ones from running.
+func (c *CleanupRegistry) Run() {
+	c.mu.Lock()
+	hooks := c.hooks
+	c.hooks = nil
+	c.mu.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i].run(); err != nil {
+			c.logger.error.Printf("Cleanup '%s' failed: %v", hooks[i].name, err)
+			continue
+		}
+		c.logger.info.Printf("Cleanup '%s' completed", hooks[i].name)
+	}
+}
+
+// run executes the hook, converting a panic into an error so it cannot skip the other hooks
+func (h cleanupHook) run() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return h.fn()
+}
+
+// RunOnPanic runs the cleanup actions when the current goroutine is panicking and then re-panics.
+// It must be deferred directly so that recover can intercept the panic.
+func (c *CleanupRegistry) RunOnPanic() {
+	if r := recover(); r != nil {
+		c.logger.error.Printf("Panic: %v, running cleanup before exiting...", r)
+		c.Run()
+		panic(r)
+	}
+}
+
+// Go runs fn in a new goroutine and runs the cleanup actions if it panics, as a panic in any goroutine ends the helper
+func (c *CleanupRegistry) Go(fn func()) {
+	go func() {
+		defer c.RunOnPanic()
+		fn()
+	}()
+}
+
+// Fatalf logs a fatal error, runs the cleanup actions and exits; log.Fatalf would exit without reverting anything
+func (c *CleanupRegistry) Fatalf(format string, args ...any) {
+	c.logger.fatal.Printf(format, args...)
+	c.Run()
+	os.Exit(1)
+}
+
+// UnaryInterceptor runs the cleanup actions if a unary RPC handler panics
+func (c *CleanupRegistry) UnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	defer c.RunOnPanic()
+	return handler(ctx, req)
+}
+
+// StreamInterceptor runs the cleanup actions if a streaming RPC handler panics
+func (c *CleanupRegistry) StreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	defer c.RunOnPanic()
+	return handler(srv, ss)
+}
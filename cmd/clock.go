@@ -0,0 +1,107 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Clock skew check parameters
+const (
+	clockProbeTimeout = 5 * time.Second
+	clockSkewLimit    = 5 * time.Minute // Offset beyond which TLS certificates and WireGuard handshakes are rejected
+)
+
+// clockProbeURLs answer plain HTTP with a Date header, so the check itself does not depend on a correct clock
+var clockProbeURLs = []string{
+	captivePortalProbeURL,
+	"http://www.msftconnecttest.com/connecttest.txt",
+}
+
+// measureClockSkew estimates how far the system clock is from the Date header of a well-known server.
+// A positive offset means the system clock is behind. The first server that answers is used.
+func measureClockSkew(ctx context.Context) (time.Duration, error) {
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	var lastErr error
+	for _, target := range clockProbeURLs {
+		offset, err := probeClock(ctx, client, target)
+		if err == nil {
+			return offset, nil
+		}
+		lastErr = err
+	}
+	return 0, lastErr
+}
+
+// probeClock compares the Date header of a single server against the midpoint of the request
+func probeClock(ctx context.Context, client *http.Client, target string) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, clockProbeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, target, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	received := time.Now()
+
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return 0, fmt.Errorf("no usable Date header from %s", target)
+	}
+	// The header has a resolution of one second and is rounded down
+	remote := date.Add(500 * time.Millisecond)
+	local := sent.Add(received.Sub(sent) / 2)
+	return remote.Sub(local).Round(time.Second), nil
+}
+
+// checkClock refuses a start while the system clock is off by more than clockSkewLimit, broadcasting
+// "clock-skew" with the measured offset. A check that cannot reach any server lets the start go on.
+func (s *Server) checkClock(ctx context.Context) error {
+	offset, err := measureClockSkew(ctx)
+	if err != nil {
+		s.logger.warn.Printf("Clock check skipped: %v", err)
+		return nil
+	}
+	if offset.Abs() <= clockSkewLimit {
+		return nil
+	}
+
+	direction := "ahead"
+	if offset > 0 {
+		direction = "behind"
+	}
+	s.logger.error.Printf("System clock is %s %s", offset.Abs(), direction)
+	s.publishStatus("clock-skew", map[string]string{"offset_seconds": fmt.Sprintf("%+d", int64(offset.Seconds()))})
+	return status.Errorf(codes.FailedPrecondition, "CLOCK_SKEW: the system clock is %s %s, correct it before connecting", offset.Abs(), direction)
+}
@@ -0,0 +1,161 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"net/netip"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	"github.com/sagernet/sing-box/experimental/clashapi/trafficontrol"
+)
+
+// connectionMatches reports whether a live connection matches every field set in the filter
+func connectionMatches(metadata trafficontrol.TrackerMetadata, filter *pb.ConnectionFilter) bool {
+	if filter == nil {
+		return true
+	}
+
+	if filter.Id != "" && metadata.ID.String() != filter.Id {
+		return false
+	}
+
+	if filter.Domain != "" {
+		domain := strings.ToLower(metadata.Metadata.Domain)
+		want := strings.ToLower(strings.TrimSuffix(filter.Domain, "."))
+		if domain != want && !strings.HasSuffix(domain, "."+want) {
+			return false
+		}
+	}
+
+	if filter.Destination != "" && !destinationMatches(metadata, filter.Destination) {
+		return false
+	}
+
+	if filter.Process != "" {
+		if metadata.Metadata.ProcessInfo == nil {
+			return false
+		}
+		path := metadata.Metadata.ProcessInfo.ProcessPath
+		if !strings.EqualFold(path, filter.Process) && !strings.EqualFold(filepath.Base(path), filter.Process) {
+			return false
+		}
+	}
+
+	if filter.Outbound != "" && metadata.Outbound != filter.Outbound {
+		found := false
+		for _, tag := range metadata.Chain {
+			if tag == filter.Outbound {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// destinationMatches compares the destination of a connection with an address, address:port or CIDR prefix
+func destinationMatches(metadata trafficontrol.TrackerMetadata, destination string) bool {
+	if metadata.Metadata.Destination.String() == destination {
+		return true
+	}
+
+	addr := metadata.Metadata.Destination.Addr
+	if prefix, err := netip.ParsePrefix(destination); err == nil {
+		return prefix.Contains(addr)
+	}
+	if address, err := netip.ParseAddr(destination); err == nil {
+		return address == addr
+	}
+	return false
+}
+
+// GetConnections handles the gRPC GetConnections request, listing the live connections that match the filter
+// with the rule and outbound they were routed by and the bytes transferred so far, oldest first
+func (s *Server) GetConnections(ctx context.Context, req *pb.GetConnectionsRequest) (*pb.GetConnectionsResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	server, err := s.clashServer()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.GetConnectionsResponse{}
+	for _, connection := range server.TrafficManager().Snapshot().Connections {
+		metadata := connection.Metadata()
+		if !connectionMatches(metadata, req.Filter) {
+			continue
+		}
+
+		entry := &pb.Connection{
+			Id:          metadata.ID.String(),
+			Network:     metadata.Metadata.Network,
+			Source:      metadata.Metadata.Source.String(),
+			Destination: metadata.Metadata.Destination.String(),
+			Domain:      metadata.Metadata.Domain,
+			Rule:        "final",
+			Outbound:    metadata.Outbound,
+			Chain:       metadata.Chain,
+			Upload:      metadata.Upload.Load(),
+			Download:    metadata.Download.Load(),
+			StartedAt:   metadata.CreatedAt.Unix(),
+		}
+		if metadata.Rule != nil {
+			entry.Rule = metadata.Rule.String()
+		}
+		if metadata.Metadata.ProcessInfo != nil {
+			entry.Process = metadata.Metadata.ProcessInfo.ProcessPath
+		}
+		resp.Connections = append(resp.Connections, entry)
+	}
+	sort.Slice(resp.Connections, func(i, j int) bool { return resp.Connections[i].StartedAt < resp.Connections[j].StartedAt })
+	return resp, nil
+}
+
+// CloseConnections handles the gRPC CloseConnections request, terminating live connections that match the filter.
+// Fields left empty match everything, so an empty filter closes all connections.
+func (s *Server) CloseConnections(ctx context.Context, req *pb.CloseConnectionsRequest) (*pb.CloseConnectionsResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	server, err := s.clashServer()
+	if err != nil {
+		return nil, err
+	}
+
+	var closed uint32
+	for _, connection := range server.TrafficManager().Snapshot().Connections {
+		if !connectionMatches(connection.Metadata(), req.Filter) {
+			continue
+		}
+		if err := connection.Close(); err != nil {
+			s.logger.warn.Printf("Failed to close connection: %v", err)
+			continue
+		}
+		closed++
+	}
+
+	s.logger.info.Printf("Closed %d connections", closed)
+	return &pb.CloseConnectionsResponse{Closed: closed}, nil
+}
@@ -0,0 +1,154 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	box "github.com/sagernet/sing-box"
+	M "github.com/sagernet/sing/common/metadata"
+	N "github.com/sagernet/sing/common/network"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// publicIPURL answers with the IP address, country and network of the client as JSON
+const publicIPURL = "https://ipinfo.io/json"
+
+// TestConnectivity handles the gRPC TestConnectivity request, checking that traffic flows through the tunnel
+// by fetching a URL (a generate_204 endpoint by default) through an outbound, the default one unless named.
+// A failed probe is reported in the response with its reason rather than as an RPC error.
+func (s *Server) TestConnectivity(ctx context.Context, req *pb.TestConnectivityRequest) (*pb.TestConnectivityResponse, error) {
+	target := req.Url
+	if target == "" {
+		target = healthCheckURL
+	}
+	if parsed, err := url.Parse(target); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid URL %q, expected an http or https URL", target)
+	}
+
+	s.mu.RLock()
+	running := s.core.Running()
+	instance := s.core.Instance()
+	s.mu.RUnlock()
+	if !running {
+		return nil, status.Errorf(codes.FailedPrecondition, "sing-box is not running")
+	}
+
+	outbound := req.Outbound
+	if outbound == "" {
+		detour, err := instance.Router().DefaultOutbound("tcp")
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "%v", err)
+		}
+		outbound = detour.Tag()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	response := &pb.TestConnectivityResponse{Url: target, Outbound: outbound}
+	delay, err := testOutboundURL(ctx, instance, outbound, target)
+	if err != nil {
+		response.Error = err.Error()
+		return response, nil
+	}
+	response.Ok = true
+	response.LatencyMs = int64(delay)
+	return response, nil
+}
+
+// outboundHTTPClient returns an HTTP client whose connections go through an outbound of the given instance
+func outboundHTTPClient(instance *box.Box, tag string) (*http.Client, error) {
+	detour, loaded := instance.Router().Outbound(tag)
+	if !loaded {
+		return nil, fmt.Errorf("outbound %s not found", tag)
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+				return detour.DialContext(ctx, N.NetworkTCP, M.ParseSocksaddr(address))
+			},
+		},
+		Timeout: healthCheckTimeout,
+	}, nil
+}
+
+// GetPublicIP handles the gRPC GetPublicIP request, looking up the address the internet sees the user at,
+// with its country and ASN, through an outbound of the running instance, the default one unless named
+func (s *Server) GetPublicIP(ctx context.Context, req *pb.GetPublicIPRequest) (*pb.GetPublicIPResponse, error) {
+	s.mu.RLock()
+	running := s.core.Running()
+	instance := s.core.Instance()
+	s.mu.RUnlock()
+	if !running {
+		return nil, status.Errorf(codes.FailedPrecondition, "sing-box is not running")
+	}
+
+	outbound := req.Outbound
+	if outbound == "" {
+		detour, err := instance.Router().DefaultOutbound(N.NetworkTCP)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "%v", err)
+		}
+		outbound = detour.Tag()
+	}
+	client, err := outboundHTTPClient(instance, outbound)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "%v", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, publicIPURL, nil)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+	request.Header.Set("Accept", "application/json")
+	resp, err := client.Do(request)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "failed to reach %s through %s: %v", publicIPURL, outbound, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, status.Errorf(codes.Unavailable, "%s returned status %d", publicIPURL, resp.StatusCode)
+	}
+
+	var info struct {
+		IP      string `json:"ip"`
+		Country string `json:"country"`
+		Org     string `json:"org"` // "AS13335 Cloudflare, Inc."
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&info); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to decode the IP info: %v", err)
+	}
+
+	response := &pb.GetPublicIPResponse{Ip: info.IP, Country: info.Country, Outbound: outbound}
+	if asn, organization, found := strings.Cut(info.Org, " "); found && strings.HasPrefix(asn, "AS") {
+		response.Asn, response.Organization = asn, organization
+	} else {
+		response.Organization = info.Org
+	}
+	return response, nil
+}
@@ -0,0 +1,274 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	"github.com/sagernet/sing-box/experimental/clashapi/trafficontrol"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Connection log settings
+const (
+	connectionLogPrefix       = "sbConnections-" // Daily files are named sbConnections-YYYY-MM-DD.log
+	connectionLogSuffix       = ".log"
+	connectionLogInterval     = time.Second // How often closed connections are collected, sing-box keeps the last 1000
+	defaultConnectionLogDays  = 7           // Retention when the client does not specify one
+	maxConnectionLogRetention = 90          // Upper bound for the retention in days
+)
+
+// ConnectionLogSettings is the privacy toggle and retention of the connection log
+type ConnectionLogSettings struct {
+	Enabled       bool `json:"enabled"`
+	RetentionDays int  `json:"retention_days"`
+}
+
+// ConnectionLogEntry is the metadata of a single closed connection
+type ConnectionLogEntry struct {
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Network     string    `json:"network"`
+	Destination string    `json:"destination"`
+	Domain      string    `json:"domain,omitempty"`
+	Process     string    `json:"process,omitempty"`
+	Rule        string    `json:"rule"`
+	Outbound    string    `json:"outbound"`
+	Upload      int64     `json:"upload"`
+	Download    int64     `json:"download"`
+}
+
+// connectionLog records connections to daily files once they close. It is off unless enabled by the user.
+type connectionLog struct {
+	mu        sync.Mutex
+	dirPath   string
+	settings  ConnectionLogSettings
+	since     time.Time       // Connections closed before the log was enabled are not recorded
+	logged    map[string]bool // IDs of the recently closed connections already recorded
+	lastPrune string          // Date of the last retention cleanup
+}
+
+// newConnectionLog creates a disabled connection log writing to dirPath
+func newConnectionLog(dirPath string) *connectionLog {
+	return &connectionLog{
+		dirPath:  dirPath,
+		settings: ConnectionLogSettings{RetentionDays: defaultConnectionLogDays},
+	}
+}
+
+// configure applies new settings; enabling the log starts recording from now on
+func (c *connectionLog) configure(settings ConnectionLogSettings) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if settings.Enabled && !c.settings.Enabled {
+		c.since = time.Now()
+		c.logged = nil
+	}
+	c.settings = settings
+	c.lastPrune = ""
+}
+
+// update returns the connections among the recently closed ones that were not recorded yet; the caller must hold c.mu
+func (c *connectionLog) update(closed []trafficontrol.TrackerMetadata) []ConnectionLogEntry {
+	if !c.settings.Enabled {
+		return nil
+	}
+
+	// sing-box drops the oldest closed connections from its list, so only the IDs still listed need to be remembered
+	logged := make(map[string]bool, len(closed))
+	var entries []ConnectionLogEntry
+	for _, metadata := range closed {
+		id := metadata.ID.String()
+		logged[id] = true
+		if c.logged[id] || metadata.ClosedAt.Before(c.since) {
+			continue
+		}
+		entries = append(entries, newConnectionLogEntry(metadata))
+	}
+	c.logged = logged
+	return entries
+}
+
+// collect returns the closed connections that were not recorded yet
+func (c *connectionLog) collect(closed []trafficontrol.TrackerMetadata) []ConnectionLogEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.update(closed)
+}
+
+// newConnectionLogEntry converts tracker metadata to a log entry
+func newConnectionLogEntry(metadata trafficontrol.TrackerMetadata) ConnectionLogEntry {
+	entry := ConnectionLogEntry{
+		Start:       metadata.CreatedAt,
+		End:         metadata.ClosedAt,
+		Network:     metadata.Metadata.Network,
+		Destination: metadata.Metadata.Destination.String(),
+		Domain:      metadata.Metadata.Domain,
+		Rule:        "final",
+		Outbound:    metadata.Outbound,
+		Upload:      metadata.Upload.Load(),
+		Download:    metadata.Download.Load(),
+	}
+	if entry.End.IsZero() {
+		entry.End = time.Now() // Still open when the instance stops
+	}
+	if metadata.Rule != nil {
+		entry.Rule = metadata.Rule.String()
+	}
+	if metadata.Metadata.ProcessInfo != nil {
+		entry.Process = metadata.Metadata.ProcessInfo.ProcessPath
+	}
+	return entry
+}
+
+// write appends entries to today's file and removes files older than the retention period
+func (c *connectionLog) write(entries []ConnectionLogEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	today := time.Now().Format(usageDateFmt)
+	if c.lastPrune != today {
+		c.prune()
+		c.lastPrune = today
+	}
+
+	file, err := os.OpenFile(filepath.Join(c.dirPath, connectionLogPrefix+today+connectionLogSuffix), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prune deletes daily files that fall outside the retention period; the caller must hold c.mu
+func (c *connectionLog) prune() {
+	oldest := time.Now().AddDate(0, 0, -c.settings.RetentionDays+1).Format(usageDateFmt)
+	files, _ := filepath.Glob(filepath.Join(c.dirPath, connectionLogPrefix+"*"+connectionLogSuffix))
+	for _, file := range files {
+		date := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(file), connectionLogPrefix), connectionLogSuffix)
+		if date < oldest {
+			os.Remove(file)
+		}
+	}
+}
+
+// flush returns the closed connections that were not recorded yet along with the open ones, used when the instance stops
+func (c *connectionLog) flush(closed, open []trafficontrol.TrackerMetadata) []ConnectionLogEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries := c.update(closed)
+	if c.settings.Enabled {
+		for _, metadata := range open {
+			entries = append(entries, newConnectionLogEntry(metadata))
+		}
+	}
+	c.logged = nil // The next instance starts a new list
+	return entries
+}
+
+// watchConnections records closed connections while the connection log is enabled
+func (s *Server) watchConnections() {
+	ticker := time.NewTicker(connectionLogInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.mu.RLock()
+		server, err := s.clashServer()
+		var closed []trafficontrol.TrackerMetadata
+		if err == nil {
+			closed = server.TrafficManager().ClosedConnections()
+		}
+		s.mu.RUnlock()
+		if err != nil {
+			continue
+		}
+
+		if err := s.connLog.write(s.connLog.collect(closed)); err != nil {
+			s.logger.error.Printf("Failed to write connection log: %v", err)
+		}
+	}
+}
+
+// flushConnectionLog records the connections closed since the last check and the ones still open when the instance
+// stops; the caller must hold s.mu
+func (s *Server) flushConnectionLog() {
+	server, err := s.clashServer()
+	if err != nil {
+		return
+	}
+	manager := server.TrafficManager()
+	if err := s.connLog.write(s.connLog.flush(manager.ClosedConnections(), manager.Connections())); err != nil {
+		s.logger.error.Printf("Failed to write connection log: %v", err)
+	}
+}
+
+// loadConnectionLogSettings applies the persisted connection log settings
+func (s *Server) loadConnectionLogSettings() {
+	state, err := s.loadState()
+	if err != nil {
+		s.logger.error.Printf("Failed to load connection log settings: %v", err)
+		return
+	}
+	if state.ConnectionLog != nil {
+		s.connLog.configure(*state.ConnectionLog)
+	}
+}
+
+// SetConnectionLog handles the gRPC SetConnectionLog request, turning connection logging on or off
+func (s *Server) SetConnectionLog(ctx context.Context, req *pb.SetConnectionLogRequest) (*pb.ConnectionLogResponse, error) {
+	settings := ConnectionLogSettings{Enabled: req.Enabled, RetentionDays: int(req.RetentionDays)}
+	if settings.RetentionDays == 0 {
+		settings.RetentionDays = defaultConnectionLogDays
+	}
+	if settings.RetentionDays > maxConnectionLogRetention {
+		return nil, status.Errorf(codes.InvalidArgument, "retention cannot exceed %d days", maxConnectionLogRetention)
+	}
+
+	err := s.updateState(func(state *HelperState) {
+		state.ConnectionLog = &settings
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	s.connLog.configure(settings)
+	s.logger.info.Printf("Connection log enabled: %v (retention %d days)", settings.Enabled, settings.RetentionDays)
+	return &pb.ConnectionLogResponse{Enabled: settings.Enabled, RetentionDays: uint32(settings.RetentionDays)}, nil
+}
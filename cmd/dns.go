@@ -0,0 +1,200 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	dns "github.com/sagernet/sing-dns"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// dnsQueryTimeout bounds a DNS lookup made on behalf of a client
+const dnsQueryTimeout = 5 * time.Second
+
+// QueryDNS handles the gRPC QueryDNS request, resolving a domain through the running core.
+// The lookup uses the core's DNS rules and cache, so it shows what tunnelled applications currently get.
+func (s *Server) QueryDNS(ctx context.Context, req *pb.QueryDNSRequest) (*pb.QueryDNSResponse, error) {
+	if req.Domain == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "domain is required")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.core.Running() {
+		return nil, status.Errorf(codes.FailedPrecondition, "sing-box is not running")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, dnsQueryTimeout)
+	defer cancel()
+
+	addresses, err := s.core.Instance().Router().Lookup(ctx, req.Domain, dns.DomainStrategyAsIS)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "failed to resolve %s: %v", req.Domain, err)
+	}
+
+	resp := &pb.QueryDNSResponse{Domain: req.Domain}
+	for _, address := range addresses {
+		resp.Addresses = append(resp.Addresses, address.String())
+	}
+	return resp, nil
+}
+
+// FlushDNSCache handles the gRPC FlushDNSCache request, clearing the core's DNS cache and, if requested,
+// the fake-ip pool and the DNS cache of the OS. sing-box cannot evict single entries, so flushing a domain
+// clears the whole cache. The OS cache alone can be flushed while sing-box is stopped.
+func (s *Server) FlushDNSCache(ctx context.Context, req *pb.FlushDNSCacheRequest) (*pb.FlushDNSCacheResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var flushed []string
+	switch {
+	case s.core.Running():
+		if req.Fakeip {
+			store, err := s.fakeIPStore()
+			if err != nil {
+				return nil, err
+			}
+			if err := store.Reset(); err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to reset fake-ip store: %v", err)
+			}
+			flushed = append(flushed, "fake-ip pool")
+		}
+		s.core.Instance().Router().ClearDNSCache()
+		flushed = append(flushed, "DNS cache")
+	case !req.System:
+		return nil, status.Errorf(codes.FailedPrecondition, "sing-box is not running")
+	}
+
+	if req.System {
+		if err := flushSystemDNSCache(); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to flush the system DNS cache: %v", err)
+		}
+		flushed = append(flushed, "system DNS cache")
+	}
+
+	message := strings.Join(flushed, ", ") + " flushed"
+	s.logger.info.Println(message)
+	if req.Domain != "" {
+		message += " (single entries cannot be evicted, including " + req.Domain + ")"
+	}
+	return &pb.FlushDNSCacheResponse{Message: message}, nil
+}
+
+// defaultDNSServer returns the tag of the DNS server queries fall back to: dns.final, or the first server
+func defaultDNSServer(dnsConfig map[string]any) string {
+	if final, _ := dnsConfig["final"].(string); final != "" {
+		return final
+	}
+	servers, _ := dnsConfig["servers"].([]any)
+	if len(servers) > 0 {
+		if server, ok := servers[0].(map[string]any); ok {
+			tag, _ := server["tag"].(string)
+			return tag
+		}
+	}
+	return ""
+}
+
+// setDNSServers replaces the addresses of DNS servers of a config, by tag; the empty tag is the default server
+func setDNSServers(config map[string]any, addresses map[string]string) {
+	dnsConfig, _ := config["dns"].(map[string]any)
+	if dnsConfig == nil {
+		return
+	}
+	defaultTag := defaultDNSServer(dnsConfig)
+
+	servers, _ := dnsConfig["servers"].([]any)
+	for _, item := range servers {
+		server, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		tag, _ := server["tag"].(string)
+		address, found := addresses[tag]
+		if !found && tag == defaultTag {
+			address, found = addresses[""]
+		}
+		if !found {
+			continue
+		}
+		server["address"] = address
+	}
+}
+
+// SetDNSServers handles the gRPC SetDNSServers request, replacing the address of DNS servers of the config
+// (the default one, dns.final or the first server, unless tagged), e.g. with a DoH server of the user's choice.
+// The addresses use the sing-box format (8.8.8.8, tls://1.1.1.1, https://dns.google/dns-query...).
+// They are persisted as an override once the overridden config is checked, and applied by restarting sing-box
+// if it is running; a check or restart failure keeps the previous servers. An empty list restores the config's servers.
+func (s *Server) SetDNSServers(ctx context.Context, req *pb.SetDNSServersRequest) (*pb.DNSServersResponse, error) {
+	addresses := make(map[string]string, len(req.Servers))
+	for _, server := range req.Servers {
+		if server.Address == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "address of DNS server %q is empty", server.Tag)
+		}
+		addresses[server.Tag] = server.Address
+	}
+
+	if len(addresses) == 0 {
+		addresses = nil
+	}
+
+	// Check that every tag exists; the tags do not depend on the overridden addresses
+	current, err := s.loadSingBoxConfig()
+	if err != nil {
+		return nil, err
+	}
+	configured := make(map[string]bool)
+	if current.DNS != nil {
+		for _, server := range current.DNS.Servers {
+			configured[server.Tag] = true
+		}
+	}
+	if len(configured) == 0 && len(addresses) > 0 {
+		return nil, status.Errorf(codes.FailedPrecondition, "the config has no DNS servers")
+	}
+	for tag := range addresses {
+		if tag != "" && !configured[tag] {
+			return nil, status.Errorf(codes.NotFound, "DNS server %s not found", tag)
+		}
+	}
+
+	options, err := s.applyStateOverride(func(state *HelperState) func(*HelperState) {
+		previous := state.DNSServers
+		state.DNSServers = addresses
+		return func(state *HelperState) { state.DNSServers = previous }
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.logger.info.Printf("DNS server overrides set: %v", addresses)
+
+	response := &pb.DNSServersResponse{}
+	if options != nil && options.DNS != nil {
+		for _, server := range options.DNS.Servers {
+			response.Servers = append(response.Servers, &pb.DNSServer{Tag: server.Tag, Address: server.Address})
+		}
+	}
+	return response, nil
+}
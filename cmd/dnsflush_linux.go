@@ -0,0 +1,37 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// flushSystemDNSCache clears the cache of systemd-resolved. Systems without it have no
+// system-wide DNS cache, so there is nothing to flush.
+func flushSystemDNSCache() error {
+	for _, command := range [][]string{{"resolvectl", "flush-caches"}, {"systemd-resolve", "--flush-caches"}} {
+		if _, err := exec.LookPath(command[0]); err != nil {
+			continue
+		}
+		if output, err := exec.Command(command[0], command[1:]...).CombinedOutput(); err != nil {
+			return fmt.Errorf("%s: %w: %s", strings.Join(command, " "), err, strings.TrimSpace(string(output)))
+		}
+		return nil
+	}
+	return nil
+}
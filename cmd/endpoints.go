@@ -0,0 +1,277 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"time"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// EndpointPoolSettings holds the WireGuard endpoints the helper rotates through
+type EndpointPoolSettings struct {
+	Outbound  string              `json:"outbound"`          // Tag of the WireGuard outbound whose endpoint is rotated
+	Endpoints []string            `json:"endpoints"`         // Candidate endpoints as host:port, in order of preference
+	Current   string              `json:"current,omitempty"` // Endpoint applied to the config
+	Working   map[string][]string `json:"working,omitempty"` // Endpoints that passed a check, by network
+}
+
+// Endpoint rotation parameters
+const (
+	endpointCheckInterval = 30 * time.Second
+	endpointSlowDelay     = 3000 // Delay in milliseconds above which the endpoint is considered throttled
+)
+
+// currentEndpoint returns the endpoint in use, defaulting to the first of the pool
+func (p EndpointPoolSettings) currentEndpoint() string {
+	if p.Current != "" && slices.Contains(p.Endpoints, p.Current) {
+		return p.Current
+	}
+	if len(p.Endpoints) > 0 {
+		return p.Endpoints[0]
+	}
+	return ""
+}
+
+// nextEndpoint picks the endpoint that replaces the current one: those known to work on the
+// given network come first, then the rest of the pool in order
+func (p EndpointPoolSettings) nextEndpoint(network string) string {
+	current := p.currentEndpoint()
+	for _, endpoint := range p.Working[network] {
+		if endpoint != current && slices.Contains(p.Endpoints, endpoint) {
+			return endpoint
+		}
+	}
+
+	index := slices.Index(p.Endpoints, current)
+	for i := 1; i < len(p.Endpoints); i++ {
+		if endpoint := p.Endpoints[(index+i)%len(p.Endpoints)]; endpoint != current {
+			return endpoint
+		}
+	}
+	return ""
+}
+
+// networkKey identifies the current network in the record of working endpoints
+func networkKey() string {
+	fingerprint, err := networkFingerprint(nil)
+	if err != nil || fingerprint == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(fingerprint))
+	return hex.EncodeToString(sum[:8])
+}
+
+// setEndpoint points a WireGuard outbound of the config at the given endpoint.
+// Outbounds with a peer list have the endpoint of their first peer replaced.
+func setEndpoint(config map[string]any, tag, endpoint string) {
+	host, portText, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return
+	}
+	port, _ := strconv.Atoi(portText)
+
+	outbounds, _ := config["outbounds"].([]any)
+	for _, item := range outbounds {
+		outbound, ok := item.(map[string]any)
+		if !ok || outbound["tag"] != tag {
+			continue
+		}
+		target := outbound
+		if peers, _ := outbound["peers"].([]any); len(peers) > 0 {
+			if peer, ok := peers[0].(map[string]any); ok {
+				target = peer
+			}
+		}
+		target["server"] = host
+		target["server_port"] = port
+	}
+}
+
+// watchEndpoints periodically checks the WireGuard outbound of the endpoint pool and rotates to the next
+// endpoint when handshakes keep failing or the endpoint is throttled
+func (s *Server) watchEndpoints() {
+	ticker := time.NewTicker(endpointCheckInterval)
+	defer ticker.Stop()
+
+	failures := 0
+	for range ticker.C {
+		state, err := s.loadState()
+		if err != nil || state.EndpointPool == nil || len(state.EndpointPool.Endpoints) == 0 {
+			failures = 0
+			continue
+		}
+		pool := *state.EndpointPool
+
+		s.mu.RLock()
+		running := s.core.Running()
+		instance := s.core.Instance()
+		s.mu.RUnlock()
+		if !running {
+			failures = 0
+			continue
+		}
+
+		network := networkKey()
+		current := pool.currentEndpoint()
+		delay, err := testOutbound(instance, pool.Outbound)
+		if err == nil && delay <= endpointSlowDelay {
+			failures = 0
+			if !slices.Contains(pool.Working[network], current) {
+				s.recordWorkingEndpoint(network, current)
+			}
+			continue
+		}
+		if err == nil {
+			err = fmt.Errorf("throttled, %d ms", delay)
+		}
+		s.logger.warn.Printf("Endpoint %s of %s failed its check: %v", current, pool.Outbound, err)
+
+		failures++
+		if failures < healthFailureLimit {
+			continue
+		}
+		failures = 0
+		s.rotateEndpoint(pool, network)
+	}
+}
+
+// recordWorkingEndpoint remembers that an endpoint works on the given network
+func (s *Server) recordWorkingEndpoint(network, endpoint string) {
+	err := s.updateState(func(state *HelperState) {
+		if state.EndpointPool == nil {
+			return
+		}
+		if state.EndpointPool.Working == nil {
+			state.EndpointPool.Working = make(map[string][]string)
+		}
+		state.EndpointPool.Working[network] = append(state.EndpointPool.Working[network], endpoint)
+	})
+	if err != nil {
+		s.logger.error.Printf("Failed to record working endpoint: %v", err)
+	}
+}
+
+// rotateEndpoint switches the pool's outbound to the next endpoint, forgetting that the current one
+// works on this network, and reloads sing-box
+func (s *Server) rotateEndpoint(pool EndpointPoolSettings, network string) {
+	current, next := pool.currentEndpoint(), pool.nextEndpoint(network)
+	if next == "" {
+		s.broadcastEvent("endpoints-exhausted", map[string]string{"outbound": pool.Outbound})
+		return
+	}
+
+	err := s.updateState(func(state *HelperState) {
+		if state.EndpointPool == nil {
+			return
+		}
+		state.EndpointPool.Current = next
+		working := state.EndpointPool.Working[network]
+		if i := slices.Index(working, current); i >= 0 {
+			state.EndpointPool.Working[network] = slices.Delete(working, i, i+1)
+		}
+	})
+	if err != nil {
+		s.logger.error.Printf("Failed to persist endpoint rotation: %v", err)
+		return
+	}
+
+	s.logger.info.Printf("Rotating %s from endpoint %s to %s", pool.Outbound, current, next)
+	s.broadcastEvent("endpoint-rotated", map[string]string{"outbound": pool.Outbound, "from": current, "to": next})
+	if err := s.restartIfRunning(); err != nil {
+		s.logger.error.Printf("Endpoint rotation reload error: %v", err)
+	}
+}
+
+// endpointPoolResponse describes the endpoint pool and the endpoints known to work on the current network
+func endpointPoolResponse(pool *EndpointPoolSettings) *pb.EndpointPoolResponse {
+	if pool == nil {
+		return &pb.EndpointPoolResponse{}
+	}
+	return &pb.EndpointPoolResponse{
+		Outbound:  pool.Outbound,
+		Endpoints: pool.Endpoints,
+		Current:   pool.currentEndpoint(),
+		Working:   pool.Working[networkKey()],
+	}
+}
+
+// SetEndpointPool handles the gRPC SetEndpointPool request, setting the endpoints a WireGuard outbound
+// rotates through. An empty list disables rotation and restores the endpoint of the config.
+func (s *Server) SetEndpointPool(ctx context.Context, req *pb.SetEndpointPoolRequest) (*pb.EndpointPoolResponse, error) {
+	var pool *EndpointPoolSettings
+	if len(req.Endpoints) > 0 {
+		if req.Outbound == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "outbound is required")
+		}
+		for _, endpoint := range req.Endpoints {
+			_, port, err := net.SplitHostPort(endpoint)
+			if err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid endpoint %q: %v", endpoint, err)
+			}
+			if number, err := strconv.Atoi(port); err != nil || number < 1 || number > 65535 {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid port in endpoint %q", endpoint)
+			}
+		}
+
+		s.mu.RLock()
+		_, outbounds := inspectRoute(s.core.Options())
+		s.mu.RUnlock()
+		for _, outbound := range outbounds {
+			if outbound.Tag == req.Outbound && outbound.Type != "wireguard" {
+				return nil, status.Errorf(codes.InvalidArgument, "outbound %s is not a wireguard outbound", req.Outbound)
+			}
+		}
+		pool = &EndpointPoolSettings{Outbound: req.Outbound, Endpoints: req.Endpoints}
+	}
+
+	err := s.updateState(func(state *HelperState) {
+		// Keep what was learned about the endpoints that remain in the pool
+		if pool != nil && state.EndpointPool != nil && state.EndpointPool.Outbound == pool.Outbound {
+			pool.Current = state.EndpointPool.Current
+			pool.Working = state.EndpointPool.Working
+		}
+		state.EndpointPool = pool
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	if err := s.restartIfRunning(); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to apply the endpoint pool: %v", err)
+	}
+	s.logger.info.Printf("Endpoint pool set to %d endpoint(s) for %s", len(req.Endpoints), req.Outbound)
+	return endpointPoolResponse(pool), nil
+}
+
+// GetEndpointPool handles the gRPC GetEndpointPool request, returning the pool and the endpoint in use
+func (s *Server) GetEndpointPool(ctx context.Context, req *pb.GetEndpointPoolRequest) (*pb.EndpointPoolResponse, error) {
+	state, err := s.loadState()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+	return endpointPoolResponse(state.EndpointPool), nil
+}
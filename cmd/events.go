@@ -0,0 +1,188 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+)
+
+// eventStreamBuffer is the number of events a slow StreamEvents client may fall behind before events are dropped
+const eventStreamBuffer = 64
+
+// eventKind is the type and severity of a status or notification
+type eventKind struct {
+	eventType pb.EventType
+	severity  pb.EventSeverity
+}
+
+// eventKinds classifies the statuses and notifications; unlisted ones are lifecycle information
+var eventKinds = map[string]eventKind{
+	"download-failed":      {pb.EventType_EVENT_TYPE_RULESET, pb.EventSeverity_EVENT_SEVERITY_ERROR},
+	"rulesets-stale":       {pb.EventType_EVENT_TYPE_RULESET, pb.EventSeverity_EVENT_SEVERITY_WARNING},
+	"rulesets-rejected":    {pb.EventType_EVENT_TYPE_RULESET, pb.EventSeverity_EVENT_SEVERITY_ERROR},
+	"config-changed":       {pb.EventType_EVENT_TYPE_CONFIG, pb.EventSeverity_EVENT_SEVERITY_INFO},
+	"config-invalid":       {pb.EventType_EVENT_TYPE_CONFIG, pb.EventSeverity_EVENT_SEVERITY_ERROR},
+	"network-changed":      {pb.EventType_EVENT_TYPE_NETWORK, pb.EventSeverity_EVENT_SEVERITY_INFO},
+	"network-down":         {pb.EventType_EVENT_TYPE_NETWORK, pb.EventSeverity_EVENT_SEVERITY_WARNING},
+	"waiting-for-network":  {pb.EventType_EVENT_TYPE_NETWORK, pb.EventSeverity_EVENT_SEVERITY_INFO},
+	"captive-portal":       {pb.EventType_EVENT_TYPE_NETWORK, pb.EventSeverity_EVENT_SEVERITY_WARNING},
+	"interface-rebound":    {pb.EventType_EVENT_TYPE_NETWORK, pb.EventSeverity_EVENT_SEVERITY_WARNING},
+	"failover":             {pb.EventType_EVENT_TYPE_OUTBOUND, pb.EventSeverity_EVENT_SEVERITY_WARNING},
+	"outbounds-unhealthy":  {pb.EventType_EVENT_TYPE_OUTBOUND, pb.EventSeverity_EVENT_SEVERITY_ERROR},
+	"endpoint-rotated":     {pb.EventType_EVENT_TYPE_OUTBOUND, pb.EventSeverity_EVENT_SEVERITY_WARNING},
+	"endpoints-exhausted":  {pb.EventType_EVENT_TYPE_OUTBOUND, pb.EventSeverity_EVENT_SEVERITY_ERROR},
+	"usage-alert":          {pb.EventType_EVENT_TYPE_USAGE, pb.EventSeverity_EVENT_SEVERITY_WARNING},
+	"clock-skew":           {pb.EventType_EVENT_TYPE_SYSTEM, pb.EventSeverity_EVENT_SEVERITY_ERROR},
+	"needs-elevation":      {pb.EventType_EVENT_TYPE_SYSTEM, pb.EventSeverity_EVENT_SEVERITY_ERROR},
+	"repairing-tun-driver": {pb.EventType_EVENT_TYPE_SYSTEM, pb.EventSeverity_EVENT_SEVERITY_WARNING},
+	"process-exited":       {pb.EventType_EVENT_TYPE_SYSTEM, pb.EventSeverity_EVENT_SEVERITY_WARNING},
+	"retrying":             {pb.EventType_EVENT_TYPE_LIFECYCLE, pb.EventSeverity_EVENT_SEVERITY_WARNING},
+}
+
+// eventStream fans the helper's statuses and notifications out to the StreamEvents clients.
+// Unlike the status stream, events are never coalesced.
+type eventStream struct {
+	mu          sync.Mutex
+	subscribers map[chan *pb.Event]bool
+}
+
+// newEventStream creates an event stream without subscribers
+func newEventStream() *eventStream {
+	return &eventStream{subscribers: make(map[chan *pb.Event]bool)}
+}
+
+// publish sends an event to every subscriber, dropping it for those that are full
+func (e *eventStream) publish(event *pb.Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for subscriber := range e.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+}
+
+// subscribe returns a channel receiving the published events and a function removing it
+func (e *eventStream) subscribe() (<-chan *pb.Event, func()) {
+	subscriber := make(chan *pb.Event, eventStreamBuffer)
+
+	e.mu.Lock()
+	e.subscribers[subscriber] = true
+	e.mu.Unlock()
+
+	return subscriber, func() {
+		e.mu.Lock()
+		delete(e.subscribers, subscriber)
+		e.mu.Unlock()
+	}
+}
+
+// publishEvent sends a status or notification to the StreamEvents clients with its structured details
+func (s *Server) publishEvent(name, message string, details map[string]string, statusChange bool) {
+	kind, found := eventKinds[name]
+	if !found {
+		kind = eventKind{pb.EventType_EVENT_TYPE_LIFECYCLE, pb.EventSeverity_EVENT_SEVERITY_INFO}
+	}
+	if name == "stopped" && details["reason"] == stopReasonCrash {
+		kind.severity = pb.EventSeverity_EVENT_SEVERITY_ERROR
+	}
+
+	s.events.publish(&pb.Event{
+		Type:          kind.eventType,
+		Name:          name,
+		Severity:      kind.severity,
+		Timestamp:     time.Now().UnixMilli(),
+		Message:       message,
+		Details:       details,
+		StatusChange:  statusChange,
+		CorrelationId: s.currentOperationID(),
+	})
+}
+
+// eventDetails copies the details of a status or notification without the empty fields
+func eventDetails(details map[string]string) map[string]string {
+	kept := make(map[string]string, len(details))
+	for key, value := range details {
+		if value != "" {
+			kept[key] = value
+		}
+	}
+	return kept
+}
+
+// eventMessage formats the details of a status or notification as the single detail line of the status stream,
+// the hooks and the status history. A lone field is used as is, several are listed as key=value.
+func eventMessage(name string, details map[string]string) string {
+	switch name {
+	case "failover", "endpoint-rotated":
+		if details["outbound"] != "" {
+			return fmt.Sprintf("%s: %s -> %s", details["outbound"], details["from"], details["to"])
+		}
+	case "interface-rebound":
+		return fmt.Sprintf("%s -> %s", details["from"], details["to"])
+	case "usage-alert":
+		return fmt.Sprintf("%s:%s:%s", details["period"], details["limit_bytes"], details["used_bytes"])
+	}
+
+	keys := slices.Sorted(maps.Keys(details))
+	if len(keys) == 1 {
+		return details[keys[0]]
+	}
+	fields := make([]string, len(keys))
+	for i, key := range keys {
+		fields[i] = key + "=" + details[key]
+	}
+	return strings.Join(fields, ", ")
+}
+
+// rulesetFailureDetails returns the failed ruleset files and their errors, as recorded by the downloader
+func (s *Server) rulesetFailureDetails() map[string]string {
+	details := make(map[string]string)
+	for file, failure := range s.rulesetFailures.All() {
+		details[file] = failure.Err
+	}
+	return details
+}
+
+// StreamEvents handles the gRPC StreamEvents request, sending every status change and notification as a typed event
+// with a timestamp, a severity and structured details. StreamStatus keeps sending the bare statuses.
+// Closing this stream does not stop sing-box.
+func (s *Server) StreamEvents(req *pb.StreamEventsRequest, stream pb.OblivionService_StreamEventsServer) error {
+	events, unsubscribe := s.events.subscribe()
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+
+		case event := <-events:
+			if err := stream.Send(event); err != nil {
+				s.logger.error.Printf("Event stream error: %v", err)
+				return err
+			}
+		}
+	}
+}
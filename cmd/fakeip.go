@@ -0,0 +1,130 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"net/netip"
+	"strings"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	"github.com/sagernet/sing-box/adapter"
+	option "github.com/sagernet/sing-box/option"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Fake-ip scanning limits. The store cannot be enumerated, but addresses are handed out in order
+// from the start of each range, so scanning stops after a run of unassigned addresses.
+const (
+	defaultFakeIPLimit = 1000 // Mappings returned when the client does not specify a limit
+	fakeIPScanGap      = 4096 // Consecutive unassigned addresses that end a scan
+)
+
+// fakeIPStore returns the fake-ip store of the running instance; the caller must hold s.mu
+func (s *Server) fakeIPStore() (adapter.FakeIPStore, error) {
+	if !s.core.Running() {
+		return nil, status.Errorf(codes.FailedPrecondition, "sing-box is not running")
+	}
+
+	store := s.core.Instance().Router().FakeIPStore()
+	if store == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "fake-ip is not enabled in the config")
+	}
+	return store, nil
+}
+
+// fakeIPRanges returns the fake-ip address ranges configured in options
+func fakeIPRanges(options *option.Options) []netip.Prefix {
+	if options == nil || options.DNS == nil || options.DNS.FakeIP == nil {
+		return nil
+	}
+
+	var ranges []netip.Prefix
+	if prefix := options.DNS.FakeIP.Inet4Range; prefix != nil {
+		ranges = append(ranges, *prefix)
+	}
+	if prefix := options.DNS.FakeIP.Inet6Range; prefix != nil {
+		ranges = append(ranges, *prefix)
+	}
+	return ranges
+}
+
+// GetFakeIPMappings handles the gRPC GetFakeIPMappings request, listing the current domain to fake address assignments.
+// An optional filter matches part of the domain or the exact address.
+func (s *Server) GetFakeIPMappings(ctx context.Context, req *pb.FakeIPMappingsRequest) (*pb.FakeIPMappingsResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	store, err := s.fakeIPStore()
+	if err != nil {
+		return nil, err
+	}
+
+	limit := int(req.Limit)
+	if limit == 0 {
+		limit = defaultFakeIPLimit
+	}
+
+	resp := &pb.FakeIPMappingsResponse{}
+	for _, prefix := range fakeIPRanges(s.core.Options()) {
+		gap := 0
+		for address := prefix.Masked().Addr().Next(); prefix.Contains(address) && gap < fakeIPScanGap; address = address.Next() {
+			if err := ctx.Err(); err != nil {
+				return nil, status.FromContextError(err).Err()
+			}
+
+			domain, found := store.Lookup(address)
+			if !found {
+				gap++
+				continue
+			}
+			gap = 0
+
+			if req.Filter != "" && !strings.Contains(domain, req.Filter) && address.String() != req.Filter {
+				continue
+			}
+			if len(resp.Mappings) == limit {
+				resp.Truncated = true
+				return resp, nil
+			}
+			resp.Mappings = append(resp.Mappings, &pb.FakeIPMapping{Domain: domain, Address: address.String()})
+		}
+	}
+	return resp, nil
+}
+
+// ResetFakeIP handles the gRPC ResetFakeIP request, clearing the fake-ip pool including its persisted cache.
+// The DNS cache is flushed as well so clients do not keep receiving addresses that no longer map to a domain.
+func (s *Server) ResetFakeIP(ctx context.Context, req *pb.ResetFakeIPRequest) (*pb.ResetFakeIPResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	store, err := s.fakeIPStore()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := store.Reset(); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to reset fake-ip store: %v", err)
+	}
+	s.core.Instance().Router().ClearDNSCache()
+
+	s.logger.info.Println("Fake-ip store reset")
+	return &pb.ResetFakeIPResponse{Message: "Fake-ip store reset"}, nil
+}
@@ -0,0 +1,75 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/ShadowZagrosDev/oblivion-helper/internal/ruleset"
+)
+
+// fallbackSuffix is appended to the tag of a bundled rule-set snapshot written to the ruleset folder
+const fallbackSuffix = ".fallback.json"
+
+// applyRuleSetFallbacks points the local rule-sets whose file is missing, because the first download failed
+// or the ruleset folder is empty, at the snapshot bundled in the binary, so the tunnel can still start.
+// Rule-sets without a snapshot are left missing and fail the start: skipping the rules using them would send
+// the traffic they route elsewhere, such as blocked or proxied domains, out directly.
+func (s *Server) applyRuleSetFallbacks(content []byte) ([]byte, error) {
+	var config map[string]any
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, err
+	}
+
+	route, _ := config["route"].(map[string]any)
+	ruleSets, _ := route["rule_set"].([]any)
+	changed := false
+	for _, item := range ruleSets {
+		ruleSet, ok := item.(map[string]any)
+		if !ok || ruleSet["type"] != "local" {
+			continue
+		}
+		tag, _ := ruleSet["tag"].(string)
+		path, _ := ruleSet["path"].(string)
+		if _, err := os.Stat(path); path == "" || !os.IsNotExist(err) {
+			continue
+		}
+
+		snapshot, ok := ruleset.Fallback(tag)
+		if !ok {
+			continue
+		}
+		fallbackPath := filepath.Join(s.dirPath, rulesetFolderName, tag+fallbackSuffix)
+		if err := os.MkdirAll(filepath.Dir(fallbackPath), os.ModePerm); err != nil {
+			return nil, err
+		}
+		if err := writeFileAtomic(fallbackPath, snapshot); err != nil {
+			return nil, err
+		}
+
+		s.logger.warn.Printf("Rule-set %s is missing, using the bundled snapshot", tag)
+		ruleSet["path"] = fallbackPath
+		ruleSet["format"] = "source"
+		changed = true
+	}
+
+	if !changed {
+		return content, nil
+	}
+	return json.Marshal(config)
+}
@@ -0,0 +1,49 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetConfig handles the gRPC GetConfig request, returning the options sing-box actually runs with,
+// including the overrides set through the API, or those the config file would produce while stopped.
+// With redact set, private keys, passwords and other secrets are replaced as in debug bundles.
+func (s *Server) GetConfig(ctx context.Context, req *pb.GetConfigRequest) (*pb.GetConfigResponse, error) {
+	s.mu.RLock()
+	running := s.core.Running()
+	s.mu.RUnlock()
+
+	options, err := s.currentOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := json.MarshalIndent(options, "", "  ")
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode config: %v", err)
+	}
+	if req.Redact {
+		content = redactConfig(content)
+	}
+	return &pb.GetConfigResponse{Config: string(content), Running: running, Path: s.configPath()}, nil
+}
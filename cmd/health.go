@@ -0,0 +1,125 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	box "github.com/sagernet/sing-box"
+	"github.com/sagernet/sing-box/common/urltest"
+	"github.com/sagernet/sing-box/outbound"
+)
+
+// Outbound health check parameters
+const (
+	healthCheckURL     = "https://www.gstatic.com/generate_204" // Fetched through each checked outbound
+	healthCheckTimeout = 10 * time.Second
+	healthFailureLimit = 2 // Consecutive failed checks after which the selected outbound is replaced
+)
+
+// watchOutboundHealth periodically checks the selected outbound of every selector group and, once it
+// keeps failing, switches the group to the first healthy member. urltest groups manage themselves.
+func (s *Server) watchOutboundHealth(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var instance *box.Box
+	failures := make(map[string]int) // Consecutive failed checks by selector tag
+	for range ticker.C {
+		s.mu.RLock()
+		current := s.core.Instance()
+		var selectors []*outbound.Selector
+		if s.core.Running() {
+			for _, detour := range current.Router().Outbounds() {
+				if selector, isSelector := detour.(*outbound.Selector); isSelector && len(selector.All()) > 1 {
+					selectors = append(selectors, selector)
+				}
+			}
+		}
+		s.mu.RUnlock()
+
+		if current != instance {
+			instance = current
+			clear(failures)
+		}
+		for _, selector := range selectors {
+			s.checkSelector(instance, selector, failures)
+		}
+	}
+}
+
+// checkSelector tests the selected outbound of a selector group and fails over to the next healthy member
+// when the check has failed healthFailureLimit times in a row. The selection is not persisted, so the
+// user's choice is tried again on the next start.
+func (s *Server) checkSelector(instance *box.Box, selector *outbound.Selector, failures map[string]int) {
+	tag, selected := selector.Tag(), selector.Now()
+	_, err := testOutbound(instance, selected)
+	if err == nil {
+		failures[tag] = 0
+		return
+	}
+	s.logger.warn.Printf("Health check of %s in group %s failed: %v", selected, tag, err)
+
+	failures[tag]++
+	if failures[tag] < healthFailureLimit {
+		return
+	}
+
+	for _, candidate := range selector.All() {
+		if candidate == selected {
+			continue
+		}
+		delay, err := testOutbound(instance, candidate)
+		if err != nil {
+			continue
+		}
+
+		s.mu.RLock()
+		switched := s.core.Instance() == instance && selector.SelectOutbound(candidate)
+		s.mu.RUnlock()
+		if !switched {
+			return
+		}
+
+		failures[tag] = 0
+		s.logger.info.Printf("Failed over group %s from %s to %s (%d ms)", tag, selected, candidate, delay)
+		s.broadcastEvent("failover", map[string]string{"outbound": tag, "from": selected, "to": candidate})
+		return
+	}
+
+	if failures[tag] == healthFailureLimit {
+		s.logger.warn.Printf("No healthy outbound left in group %s", tag)
+		s.broadcastEvent("outbounds-unhealthy", map[string]string{"outbound": tag})
+	}
+}
+
+// testOutbound fetches the health check URL through an outbound of the given instance, returning the delay in milliseconds
+func testOutbound(instance *box.Box, tag string) (uint16, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+	return testOutboundURL(ctx, instance, tag, healthCheckURL)
+}
+
+// testOutboundURL fetches link through an outbound of the given instance, returning the delay in milliseconds
+func testOutboundURL(ctx context.Context, instance *box.Box, tag, link string) (uint16, error) {
+	detour, loaded := instance.Router().Outbound(tag)
+	if !loaded {
+		return 0, fmt.Errorf("outbound %s not found", tag)
+	}
+	return urltest.URLTest(ctx, link, detour)
+}
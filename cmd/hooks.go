@@ -0,0 +1,148 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// hookTimeout bounds the run time of a hook command
+const hookTimeout = time.Minute
+
+// Hook events derived from status updates, in addition to the status names themselves
+const (
+	hookEventConnected    = "connected"    // Sing-box started
+	hookEventDisconnected = "disconnected" // Sing-box stopped for any reason but a crash
+	hookEventCrashed      = "crashed"      // Sing-box stopped after a failure
+	hookEventAny          = "*"            // Every status update
+)
+
+// eventHook is a command run when an event occurs
+type eventHook struct {
+	event   string
+	command string
+}
+
+// eventHooks is the repeatable -hook flag, given as <event>=<command>
+type eventHooks []eventHook
+
+// String implements flag.Value
+func (h *eventHooks) String() string {
+	var values []string
+	for _, hook := range *h {
+		values = append(values, hook.event+"="+hook.command)
+	}
+	return strings.Join(values, ",")
+}
+
+// Set implements flag.Value
+func (h *eventHooks) Set(value string) error {
+	event, command, found := strings.Cut(value, "=")
+	event, command = strings.TrimSpace(event), strings.TrimSpace(command)
+	if !found || event == "" || command == "" {
+		return fmt.Errorf("expected <event>=<command>")
+	}
+	*h = append(*h, eventHook{event: event, command: command})
+	return nil
+}
+
+// hookEvents returns the events a status update triggers
+func hookEvents(status, detail string) []string {
+	events := []string{status, hookEventAny}
+	switch {
+	case status == "started":
+		events = append(events, hookEventConnected)
+	case status == "stopped" && detail == stopReasonCrash:
+		events = append(events, hookEventCrashed)
+	case status == "stopped":
+		events = append(events, hookEventDisconnected)
+	}
+	return events
+}
+
+// runHooks starts the hook commands matching a status update in the background.
+// Event details are passed in OBLIVION_* environment variables.
+func (s *Server) runHooks(status, detail string) {
+	if len(s.flags.Hooks) == 0 {
+		return
+	}
+
+	events := hookEvents(status, detail)
+	for _, hook := range s.flags.Hooks {
+		for _, event := range events {
+			if hook.event != event {
+				continue
+			}
+			env := []string{
+				"OBLIVION_EVENT=" + event,
+				"OBLIVION_STATUS=" + status,
+				"OBLIVION_DETAIL=" + detail,
+				"OBLIVION_CORRELATION_ID=" + s.currentOperationID(),
+				"OBLIVION_TIME=" + time.Now().Format(time.RFC3339),
+			}
+			s.cleanup.Go(func() { s.runHook(hook, env) })
+		}
+	}
+}
+
+// runHook runs a hook command through the system shell as the hook user
+func (s *Server) runHook(hook eventHook, env []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, hook.command)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Dir = s.dirPath
+	username, err := s.hookUser()
+	if err != nil {
+		s.logger.error.Printf("Hook for %s not run: %v", hook.event, err)
+		return
+	}
+	release, err := runAsUser(cmd, username)
+	if err != nil {
+		s.logger.error.Printf("Hook for %s not run: %v", hook.event, err)
+		return
+	}
+	defer release()
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		s.logger.warn.Printf("Hook for %s failed: %v: %s", hook.event, err, strings.TrimSpace(string(output)))
+		return
+	}
+	s.logger.info.Printf("Hook for %s ran: %s", hook.event, hook.command)
+}
+
+// hookUser returns the user hook commands run as: the -hook-user flag, or the user who started the helper.
+// An empty name keeps the helper's identity, which is only used while the helper is unprivileged; hooks are
+// never run with administrator/root privileges unless -hook-user names such an account explicitly.
+func (s *Server) hookUser() (string, error) {
+	if s.flags.HookUser != "" {
+		return s.flags.HookUser, nil
+	}
+	if !s.elevated {
+		return "", nil
+	}
+	account, err := invokingAccount()
+	if err != nil {
+		return "", fmt.Errorf("refusing to run hooks with administrator/root privileges (%v), set -hook-user", err)
+	}
+	return account.Username, nil
+}
@@ -0,0 +1,63 @@
+//go:build !windows
+
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// shellCommand returns a command running the given line through /bin/sh
+func shellCommand(ctx context.Context, line string) *exec.Cmd {
+	return exec.CommandContext(ctx, "/bin/sh", "-c", line)
+}
+
+// runAsUser makes cmd run with the identity of the given user, given by name or UID,
+// so hooks do not inherit the helper's root privileges. An empty name keeps the helper's identity.
+// The returned function releases the resources held for cmd once it has run.
+func runAsUser(cmd *exec.Cmd, username string) (func(), error) {
+	release := func() {}
+	if username == "" || os.Geteuid() != 0 {
+		return release, nil
+	}
+
+	account, err := user.Lookup(username)
+	if err != nil {
+		if account, err = user.LookupId(username); err != nil {
+			return nil, fmt.Errorf("unknown hook user %q", username)
+		}
+	}
+	uid, err := strconv.ParseUint(account.Uid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UID of %s: %w", account.Username, err)
+	}
+	gid, err := strconv.ParseUint(account.Gid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GID of %s: %w", account.Username, err)
+	}
+
+	cmd.SysProcAttr = &syscall.SysProcAttr{Credential: &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid)}}
+	cmd.Env = append(cmd.Env, "HOME="+account.HomeDir, "USER="+account.Username, "LOGNAME="+account.Username)
+	cmd.Dir = account.HomeDir
+	return release, nil
+}
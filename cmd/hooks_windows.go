@@ -0,0 +1,103 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"os/user"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// shellCommand returns a command running the given line through cmd.exe
+func shellCommand(ctx context.Context, line string) *exec.Cmd {
+	return exec.CommandContext(ctx, "cmd", "/C", line)
+}
+
+// runAsUser makes cmd run with the logon token of the given user, so hooks do not inherit the helper's
+// administrator privileges. Only the user logged on to the console can be used, and only while the helper
+// runs as a service (LocalSystem), since other processes cannot obtain that token. Naming the helper's own
+// account explicitly keeps its identity, as does an empty name. The returned function closes the token.
+func runAsUser(cmd *exec.Cmd, username string) (func(), error) {
+	release := func() {}
+	if username == "" {
+		return release, nil
+	}
+	if own, err := tokenAccount(windows.GetCurrentProcessToken()); err == nil && sameAccount(own, username) {
+		return release, nil
+	}
+
+	token, account, err := consoleUserToken()
+	if err != nil {
+		return nil, fmt.Errorf("cannot run as %s: %w", username, err)
+	}
+	if !sameAccount(account, username) {
+		token.Close()
+		return nil, fmt.Errorf("cannot run as %s: only the console user %s can be used", username, account)
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{Token: syscall.Token(token)}
+	return func() { token.Close() }, nil
+}
+
+// invokingAccount returns the user logged on to the console, who hooks run as by default
+func invokingAccount() (*user.User, error) {
+	token, account, err := consoleUserToken()
+	if err != nil {
+		return nil, err
+	}
+	token.Close()
+	return &user.User{Username: account}, nil
+}
+
+// consoleUserToken returns the logon token and the DOMAIN\name account of the user logged on to the console
+func consoleUserToken() (windows.Token, string, error) {
+	var token windows.Token
+	if err := windows.WTSQueryUserToken(windows.WTSGetActiveConsoleSessionId(), &token); err != nil {
+		return 0, "", fmt.Errorf("failed to get the console user's token (the helper must run as a service): %w", err)
+	}
+	account, err := tokenAccount(token)
+	if err != nil {
+		token.Close()
+		return 0, "", err
+	}
+	return token, account, nil
+}
+
+// tokenAccount returns the DOMAIN\name account a token belongs to
+func tokenAccount(token windows.Token) (string, error) {
+	tokenUser, err := token.GetTokenUser()
+	if err != nil {
+		return "", err
+	}
+	name, domain, _, err := tokenUser.User.Sid.LookupAccount("")
+	if err != nil {
+		return "", err
+	}
+	return domain + `\` + name, nil
+}
+
+// sameAccount reports whether a DOMAIN\name account is the given user, named with or without its domain
+func sameAccount(account, username string) bool {
+	if strings.EqualFold(account, username) {
+		return true
+	}
+	_, name, _ := strings.Cut(account, `\`)
+	return strings.EqualFold(name, username)
+}
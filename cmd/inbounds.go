@@ -0,0 +1,141 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	"github.com/sagernet/sing-box/adapter"
+	"github.com/sagernet/sing-box/inbound"
+	option "github.com/sagernet/sing-box/option"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// extraInbound is an inbound added at runtime on top of the config. It is kept until removed or the helper exits,
+// and re-created whenever a new instance starts.
+type extraInbound struct {
+	options option.Inbound
+	inbound adapter.Inbound // Live inbound of the running instance, nil while stopped
+}
+
+// startExtraInbound creates and starts an extra inbound on the running instance; the caller must hold s.mu
+func (s *Server) startExtraInbound(extra *extraInbound) error {
+	logger := s.core.NewLogger(fmt.Sprintf("inbound/%s[%s]", extra.options.Type, extra.options.Tag))
+	created, err := inbound.New(s.core.Context(), s.core.Instance().Router(), logger, extra.options.Tag, extra.options, nil)
+	if err != nil {
+		return err
+	}
+	if err := created.Start(); err != nil {
+		created.Close()
+		return err
+	}
+	extra.inbound = created
+	return nil
+}
+
+// startExtraInbounds re-creates the extra inbounds on a new instance; the caller must hold s.mu
+func (s *Server) startExtraInbounds() {
+	for tag, extra := range s.extraInbounds {
+		if err := s.startExtraInbound(extra); err != nil {
+			s.logger.error.Printf("Failed to start inbound %s: %v", tag, err)
+		}
+	}
+}
+
+// closeExtraInbounds closes the extra inbounds of the running instance; the caller must hold s.mu
+func (s *Server) closeExtraInbounds() {
+	for tag, extra := range s.extraInbounds {
+		if extra.inbound == nil {
+			continue
+		}
+		if err := extra.inbound.Close(); err != nil {
+			s.logger.warn.Printf("Failed to close inbound %s: %v", tag, err)
+		}
+		extra.inbound = nil
+	}
+}
+
+// AddInbound handles the gRPC AddInbound request, starting an extra inbound (e.g. an HTTP proxy for a single app)
+// without restarting the running session. The inbound is given as a sing-box inbound JSON object.
+func (s *Server) AddInbound(ctx context.Context, req *pb.AddInboundRequest) (*pb.InboundResponse, error) {
+	var options option.Inbound
+	if err := json.Unmarshal([]byte(req.Inbound), &options); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid inbound: %v", err)
+	}
+	if options.Tag == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "inbound tag is required")
+	}
+	if options.Type == "tun" {
+		return nil, status.Errorf(codes.InvalidArgument, "TUN inbounds cannot be added at runtime")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.extraInbounds[options.Tag]; exists {
+		return nil, status.Errorf(codes.AlreadyExists, "inbound %q already exists", options.Tag)
+	}
+	// While stopped, the config the next start uses is checked instead
+	configuredOptions := s.core.Options()
+	if !s.core.Running() {
+		if loaded, err := s.loadSingBoxConfig(); err == nil {
+			configuredOptions = loaded
+		}
+	}
+	for _, configured := range inspectInbounds(configuredOptions) {
+		if configured.Tag == options.Tag {
+			return nil, status.Errorf(codes.AlreadyExists, "inbound %q already exists in the config", options.Tag)
+		}
+	}
+
+	extra := &extraInbound{options: options}
+	if s.core.Running() {
+		if err := s.startExtraInbound(extra); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to start inbound %s: %v", options.Tag, err)
+		}
+	}
+	s.extraInbounds[options.Tag] = extra
+
+	s.logger.info.Printf("Inbound %s (%s) added", options.Tag, options.Type)
+	return &pb.InboundResponse{Message: "Inbound added"}, nil
+}
+
+// RemoveInbound handles the gRPC RemoveInbound request, closing an inbound added with AddInbound
+func (s *Server) RemoveInbound(ctx context.Context, req *pb.RemoveInboundRequest) (*pb.InboundResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	extra, exists := s.extraInbounds[req.Tag]
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "no runtime inbound %q", req.Tag)
+	}
+
+	if extra.inbound != nil {
+		if err := extra.inbound.Close(); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to close inbound %s: %v", req.Tag, err)
+		}
+	}
+	delete(s.extraInbounds, req.Tag)
+
+	s.logger.info.Printf("Inbound %s removed", req.Tag)
+	return &pb.InboundResponse{Message: "Inbound removed"}, nil
+}
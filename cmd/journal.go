@@ -0,0 +1,187 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	option "github.com/sagernet/sing-box/option"
+)
+
+// journalFileName is the name of the append-only file recording sing-box state transitions
+const journalFileName = "sbJournal.log"
+
+// System changes made by a running sing-box instance that outlive an unclean shutdown
+const (
+	changeTun         = "tun"          // TUN interface with automatic routes
+	changeKillSwitch  = "kill-switch"  // Strict routing that blocks traffic outside the tunnel
+	changeSystemProxy = "system-proxy" // OS proxy settings pointing at a local inbound
+	changeAutoProxy   = "auto-proxy"   // OS auto-proxy settings pointing at the PAC server
+)
+
+// JournalEntry is a single state transition recorded in the journal
+type JournalEntry struct {
+	Time       time.Time `json:"time"`
+	State      string    `json:"state"`                 // starting, started, stopping or stopped
+	Changes    []string  `json:"changes,omitempty"`     // System changes made by the instance
+	RouteTable int       `json:"route_table,omitempty"` // iproute2 table used by auto_route on Linux
+}
+
+// newJournalEntry builds a journal entry describing the system changes the given options will make
+func newJournalEntry(state string, options *option.Options) JournalEntry {
+	entry := JournalEntry{Time: time.Now(), State: state}
+
+	for _, inbound := range inspectInbounds(options) {
+		if inbound.Type == "tun" && inbound.AutoRoute {
+			entry.Changes = append(entry.Changes, changeTun)
+			entry.RouteTable = inbound.RouteTable
+			if inbound.StrictRoute {
+				entry.Changes = append(entry.Changes, changeKillSwitch)
+			}
+		}
+		if inbound.SetSystemProxy {
+			entry.Changes = append(entry.Changes, changeSystemProxy)
+		}
+	}
+	return entry
+}
+
+// writeJournal records a state transition. Entries are synced to disk immediately so they survive a crash.
+// A "stopped" entry means the system is clean again, so the journal is compacted down to that entry.
+func (s *Server) writeJournal(entry JournalEntry) {
+	journalPath := filepath.Join(s.dirPath, journalFileName)
+
+	content, err := json.Marshal(entry)
+	if err != nil {
+		s.logger.error.Printf("Failed to encode journal entry: %v", err)
+		return
+	}
+	content = append(content, '\n')
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if entry.State == "stopped" {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	}
+
+	file, err := os.OpenFile(journalPath, flags, 0o644)
+	if err != nil {
+		s.logger.error.Printf("Failed to open journal: %v", err)
+		return
+	}
+	defer file.Close()
+
+	if _, err := file.Write(content); err != nil {
+		s.logger.error.Printf("Failed to write journal: %v", err)
+		return
+	}
+	if err := file.Sync(); err != nil {
+		s.logger.error.Printf("Failed to sync journal: %v", err)
+	}
+}
+
+// readJournal returns the entries of the journal, ignoring a torn last line left by a crash
+func (s *Server) readJournal() ([]JournalEntry, error) {
+	content, err := os.ReadFile(filepath.Join(s.dirPath, journalFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// recoverJournal checks whether the previous run ended while sing-box had system changes in place,
+// and reverts those changes so the machine is not left without working networking
+func (s *Server) recoverJournal() {
+	entries, err := s.readJournal()
+	if err != nil {
+		s.logger.error.Printf("Journal recovery skipped: %v", err)
+		return
+	}
+	if len(entries) == 0 || entries[len(entries)-1].State == "stopped" {
+		return
+	}
+
+	// Collect every change recorded since the last clean stop
+	var pending JournalEntry
+	for _, entry := range entries {
+		if entry.State == "stopped" {
+			pending = JournalEntry{}
+			continue
+		}
+		pending.Changes = appendUnique(pending.Changes, entry.Changes...)
+		if entry.RouteTable != 0 {
+			pending.RouteTable = entry.RouteTable
+		}
+	}
+
+	last := entries[len(entries)-1]
+	s.reliability.failed(fmt.Errorf("previous run ended uncleanly while sing-box was %s", last.State), true)
+	s.logger.warn.Printf("Previous run ended uncleanly while sing-box was %s (%s), repairing...", last.State, last.Time.Format(time.RFC3339))
+
+	for _, change := range pending.Changes {
+		var err error
+		switch change {
+		case changeSystemProxy:
+			err = clearSystemProxy()
+		case changeAutoProxy:
+			err = clearAutoProxy()
+		case changeTun, changeKillSwitch:
+			err = repairRoutes(pending.RouteTable)
+		}
+		if err != nil {
+			s.logger.error.Printf("Failed to repair %s: %v", change, err)
+		} else {
+			s.logger.info.Printf("Repaired %s", change)
+		}
+	}
+
+	s.writeJournal(JournalEntry{Time: time.Now(), State: "stopped"})
+}
+
+// appendUnique appends the values that are not already present in list
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		found := false
+		for _, existing := range list {
+			if existing == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, value)
+		}
+	}
+	return list
+}
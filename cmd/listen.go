@@ -0,0 +1,50 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// listen opens the listener of the gRPC server. The address is a TCP address ("127.0.0.1:50051"),
+// a unix socket except on Windows ("unix:///run/oblivion-helper.sock") or a named pipe on Windows
+// ("pipe:\\.\pipe\oblivion-helper"), in the format clients dial.
+// It returns a function removing what the listener left behind once the server stopped.
+func listen(address string) (net.Listener, func(), error) {
+	if pipe, isPipe := strings.CutPrefix(address, "pipe:"); isPipe {
+		listener, err := listenPipe(pipe)
+		return listener, func() {}, err
+	}
+
+	path, isUnix := strings.CutPrefix(address, "unix:")
+	if !isUnix {
+		listener, err := net.Listen(protocolType, address)
+		return listener, func() {}, err
+	}
+
+	path = strings.TrimPrefix(path, "//")
+	if path == "" {
+		return nil, nil, fmt.Errorf("unix socket path is empty")
+	}
+	listener, err := listenUnix(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return listener, func() { os.Remove(path) }, nil
+}
@@ -0,0 +1,67 @@
+//go:build !windows
+
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// listenUnix listens on a unix socket that only root and the user who invoked sudo or pkexec can connect to.
+// A socket left behind by a previous run is replaced.
+func listenUnix(path string) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	// Create the socket without group and other permissions so it is never reachable by others, even briefly
+	previous := syscall.Umask(0o177)
+	listener, err := net.Listen("unix", path)
+	syscall.Umask(previous)
+	if err != nil {
+		return nil, err
+	}
+
+	if account, err := invokingAccount(); err == nil {
+		uid, uidErr := strconv.Atoi(account.Uid)
+		gid, gidErr := strconv.Atoi(account.Gid)
+		if uidErr != nil || gidErr != nil {
+			listener.Close()
+			return nil, fmt.Errorf("invalid UID or GID of %s", account.Username)
+		}
+		if err := os.Chown(path, uid, gid); err != nil {
+			listener.Close()
+			return nil, fmt.Errorf("failed to hand the socket to the invoking user: %w", err)
+		}
+	}
+	return listener, nil
+}
+
+// listenPipe is only supported on Windows
+func listenPipe(path string) (net.Listener, error) {
+	return nil, errors.New("named pipes are only supported on Windows, use a unix socket")
+}
@@ -0,0 +1,44 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	winio "github.com/Microsoft/go-winio"
+
+	"golang.org/x/sys/windows"
+)
+
+// listenUnix is not supported on Windows, where the helper listens on TCP or a named pipe
+func listenUnix(path string) (net.Listener, error) {
+	return nil, errors.New("unix sockets are not supported on Windows, use a TCP address or a named pipe")
+}
+
+// listenPipe listens on a named pipe that only SYSTEM, elevated administrators and the user running the helper
+// can open, so the desktop app of that user can connect without elevation but other users cannot
+func listenPipe(path string) (net.Listener, error) {
+	user, err := windows.GetCurrentProcessToken().GetTokenUser()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the current user: %w", err)
+	}
+
+	// Protected DACL granting full access to SYSTEM, Administrators and the current user only
+	descriptor := fmt.Sprintf("D:P(A;;GA;;;SY)(A;;GA;;;BA)(A;;GA;;;%s)", user.User.Sid.String())
+	return winio.ListenPipe(path, &winio.PipeConfig{SecurityDescriptor: descriptor})
+}
@@ -0,0 +1,95 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sagernet/sing-box/log"
+)
+
+// Number of recent lines kept in memory for debug bundles
+const (
+	helperLogLines   = 2000
+	coreLogLines     = 2000
+	statusHistoryLen = 200
+)
+
+// ansiEscape matches the color codes of the terminal output
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// helperLogs keeps the recent helper log lines; NewLogger writes to it in addition to the terminal
+var helperLogs = newLogBuffer(helperLogLines)
+
+// logBuffer keeps the most recent lines of a log in memory
+type logBuffer struct {
+	mu    sync.Mutex
+	lines []string
+	max   int
+}
+
+// newLogBuffer creates a buffer keeping up to max lines
+func newLogBuffer(max int) *logBuffer {
+	return &logBuffer{max: max}
+}
+
+// Write stores the lines written by a log.Logger, without color codes
+func (b *logBuffer) Write(p []byte) (int, error) {
+	text := ansiEscape.ReplaceAllString(string(p), "")
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		b.add(line)
+	}
+	return len(p), nil
+}
+
+// add stores a line, discarding the oldest one when the buffer is full
+func (b *logBuffer) add(line string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.lines) >= b.max {
+		b.lines = b.lines[1:]
+	}
+	b.lines = append(b.lines, line)
+}
+
+// String returns the buffered lines
+func (b *logBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.lines) == 0 {
+		return ""
+	}
+	return strings.Join(b.lines, "\n") + "\n"
+}
+
+// coreLogWriter receives the log messages of sing-box in addition to its regular output
+type coreLogWriter struct {
+	buffer *logBuffer
+	stream *logStream
+}
+
+func (w coreLogWriter) DisableColors() bool { return true }
+
+func (w coreLogWriter) WriteMessage(level log.Level, message string) {
+	now := time.Now()
+	w.buffer.add(now.Format(time.DateTime) + " " + strings.ToUpper(log.FormatLevel(level)) + " " + message)
+	w.stream.publish(logEntry{time: now, level: level, message: message})
+}
@@ -0,0 +1,98 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	"github.com/sagernet/sing-box/log"
+	option "github.com/sagernet/sing-box/option"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// setLevel hides the helper's info and warn lines below level; errors are always written
+func (l *Logger) setLevel(level log.Level) {
+	l.info.SetOutput(l.output)
+	l.warn.SetOutput(l.output)
+	if level < log.LevelInfo {
+		l.info.SetOutput(io.Discard)
+	}
+	if level < log.LevelWarn {
+		l.warn.SetOutput(io.Discard)
+	}
+}
+
+// logLevel returns the level set through SetLogLevel, empty if none
+func (s *Server) logLevel() string {
+	s.logLevelMu.Lock()
+	defer s.logLevelMu.Unlock()
+	return s.level
+}
+
+// applyLogLevel makes sing-box log at the level set through SetLogLevel instead of the config's
+func (s *Server) applyLogLevel(options *option.Options) {
+	level := s.logLevel()
+	if level == "" {
+		return
+	}
+	if options.Log == nil {
+		options.Log = &option.LogOptions{}
+	}
+	options.Log.Level = level
+}
+
+// SetLogLevel handles the gRPC SetLogLevel request, changing the verbosity of the helper and of sing-box
+// (trace, debug, info, warn, error, fatal, panic) until the helper exits. The helper has no levels below info.
+// A running sing-box is reloaded in place to apply its level. An empty level returns the current one.
+func (s *Server) SetLogLevel(ctx context.Context, req *pb.SetLogLevelRequest) (*pb.SetLogLevelResponse, error) {
+	if req.Level == "" {
+		return &pb.SetLogLevelResponse{Level: s.logLevel()}, nil
+	}
+	level, err := log.ParseLevel(strings.ToLower(req.Level))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown log level %q, expected trace, debug, info, warn, error, fatal or panic", req.Level)
+	}
+	name := log.FormatLevel(level)
+
+	s.logLevelMu.Lock()
+	s.level = name
+	s.logLevelMu.Unlock()
+	s.logger.info.Printf("Log level set to %s", name)
+	s.logger.setLevel(level)
+
+	s.mu.RLock()
+	running := s.core.Running()
+	var current string
+	if running && s.core.Options().Log != nil {
+		current = s.core.Options().Log.Level
+	}
+	s.mu.RUnlock()
+
+	response := &pb.SetLogLevelResponse{Level: name}
+	if running && current != name {
+		if err := s.reloadSingBox(false, "reloading"); err != nil {
+			return nil, err
+		}
+		response.CoreReloaded = true
+	}
+	return response, nil
+}
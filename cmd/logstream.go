@@ -0,0 +1,117 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	"github.com/sagernet/sing-box/log"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// logStreamBuffer is the number of log entries a slow StreamLogs client may fall behind before entries are dropped
+const logStreamBuffer = 256
+
+// logEntry is a sing-box log message delivered to StreamLogs clients
+type logEntry struct {
+	time    time.Time
+	level   log.Level
+	message string
+}
+
+// logStream fans the sing-box log messages out to the StreamLogs clients
+type logStream struct {
+	mu          sync.Mutex
+	subscribers map[chan logEntry]bool
+}
+
+// newLogStream creates a log stream without subscribers
+func newLogStream() *logStream {
+	return &logStream{subscribers: make(map[chan logEntry]bool)}
+}
+
+// publish sends an entry to every subscriber, dropping it for those that are full
+func (l *logStream) publish(entry logEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for subscriber := range l.subscribers {
+		select {
+		case subscriber <- entry:
+		default:
+		}
+	}
+}
+
+// subscribe returns a channel receiving the published entries and a function removing it
+func (l *logStream) subscribe() (<-chan logEntry, func()) {
+	subscriber := make(chan logEntry, logStreamBuffer)
+
+	l.mu.Lock()
+	l.subscribers[subscriber] = true
+	l.mu.Unlock()
+
+	return subscriber, func() {
+		l.mu.Lock()
+		delete(l.subscribers, subscriber)
+		l.mu.Unlock()
+	}
+}
+
+// StreamLogs handles the gRPC StreamLogs request, forwarding the sing-box log messages at or above
+// the requested level (trace, debug, info, warn, error; default info) as they are written.
+// Messages below the log level of the sing-box config are never produced.
+func (s *Server) StreamLogs(req *pb.StreamLogsRequest, stream pb.OblivionService_StreamLogsServer) error {
+	minLevel := log.LevelInfo
+	if req.Level != "" {
+		level, err := log.ParseLevel(strings.ToLower(req.Level))
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "unknown log level %q", req.Level)
+		}
+		minLevel = level
+	}
+
+	entries, unsubscribe := s.coreLogStream.subscribe()
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+
+		case entry := <-entries:
+			// Lower levels are more severe
+			if entry.level > minLevel {
+				continue
+			}
+			err := stream.Send(&pb.LogEntry{
+				Timestamp: entry.time.UnixMilli(),
+				Level:     log.FormatLevel(entry.level),
+				Message:   entry.message,
+			})
+			if err != nil {
+				s.logger.error.Printf("Log stream error: %v", err)
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	"github.com/sagernet/sing-box/adapter"
+	M "github.com/sagernet/sing/common/metadata"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// lookupPort is the destination port assumed when matching rules for a bare domain or address
+const lookupPort = 443
+
+// routeDecision is the outcome of matching a destination against the rules of the running instance
+type routeDecision struct {
+	ruleSets  []string // Rule-sets that contain the destination
+	ruleIndex int      // Index of the matching route rule, -1 when the final outbound is used
+	rule      string   // Description of the matching route rule
+	outbound  string
+}
+
+// lookupMetadata builds the metadata of a connection to a domain or IP address
+func lookupMetadata(value string, port uint16, network string) (*adapter.InboundContext, error) {
+	metadata := &adapter.InboundContext{Network: network}
+	if addr, err := netip.ParseAddr(value); err == nil {
+		metadata.Destination = M.SocksaddrFrom(addr.Unmap(), port)
+		return metadata, nil
+	}
+	if value == "" || !M.IsDomainName(value) {
+		return nil, fmt.Errorf("%q is neither a domain nor an IP address", value)
+	}
+	metadata.Domain = value
+	metadata.Destination = M.Socksaddr{Fqdn: value, Port: port}
+	return metadata, nil
+}
+
+// decideRoute matches a destination against the rule-sets and route rules of the running instance.
+// newMetadata is called for every match, since matching may record state in the metadata.
+// The caller must hold s.mu.
+func (s *Server) decideRoute(newMetadata func() *adapter.InboundContext) (routeDecision, error) {
+	router := s.core.Instance().Router()
+	decision := routeDecision{ruleIndex: -1}
+
+	for _, info := range inspectRuleSets(s.core.Options()) {
+		ruleSet, found := router.RuleSet(info.Tag)
+		if found && ruleSet.Match(newMetadata()) {
+			decision.ruleSets = append(decision.ruleSets, info.Tag)
+		}
+	}
+
+	for i, rule := range router.Rules() {
+		if rule.Match(newMetadata()) {
+			decision.ruleIndex = i
+			decision.rule = rule.String()
+			decision.outbound = rule.Outbound()
+			return decision, nil
+		}
+	}
+
+	route, _ := inspectRoute(s.core.Options())
+	decision.outbound = route.Final
+	if decision.outbound == "" {
+		outbound, err := router.DefaultOutbound(newMetadata().Network)
+		if err != nil {
+			return decision, err
+		}
+		decision.outbound = outbound.Tag()
+	}
+	return decision, nil
+}
+
+// LookupRule handles the gRPC LookupRule request, reporting which rule-sets contain a domain or IP address
+// and which rule and outbound the running config would route a connection to it through.
+// Rules are matched as for a TCP connection to port 443 without sniffing or DNS resolution.
+func (s *Server) LookupRule(ctx context.Context, req *pb.LookupRuleRequest) (*pb.LookupRuleResponse, error) {
+	metadata, err := lookupMetadata(req.Value, lookupPort, "tcp")
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.core.Running() {
+		return nil, status.Errorf(codes.FailedPrecondition, "sing-box is not running")
+	}
+
+	decision, err := s.decideRoute(func() *adapter.InboundContext {
+		copied := *metadata
+		return &copied
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	return &pb.LookupRuleResponse{
+		Value:     req.Value,
+		RuleSets:  decision.ruleSets,
+		RuleIndex: int32(decision.ruleIndex),
+		Rule:      decision.rule,
+		Outbound:  decision.outbound,
+	}, nil
+}
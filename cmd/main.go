@@ -17,26 +17,29 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log"
-	"net"
-	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
-	pb "oblivion-helper/gRPC"
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+	"github.com/ShadowZagrosDev/oblivion-helper/internal/broadcaster"
+	"github.com/ShadowZagrosDev/oblivion-helper/internal/config"
+	"github.com/ShadowZagrosDev/oblivion-helper/internal/core"
+	"github.com/ShadowZagrosDev/oblivion-helper/internal/ruleset"
 
 	box "github.com/sagernet/sing-box"
 	option "github.com/sagernet/sing-box/option"
 
-	"atomicgo.dev/isadmin"
 	"github.com/fatih/color"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -45,61 +48,165 @@ import (
 
 // Constants for server setup and configuration
 const (
-	protocolType            = "tcp"               // Connection protocol used by the server
-	serverAddress           = "127.0.0.1:50051"   // Localhost address for gRPC server
-	configFileName          = "sbConfig.json"     // Name of the sing-box configuration file
-	exportListFileName      = "sbExportList.json" // Name of the export list config file
-	statusChannelCap        = 100                 // Capacity of the status channel
-	gracefulShutdownTimeout = 2 * time.Second     // Timeout for graceful shutdown
-	rulesetFolderName       = "ruleset"           // Name of the folder to store rulesets
+	protocolType            = "tcp"                  // Connection protocol used by the server
+	serverAddress           = "127.0.0.1:50051"      // Localhost address for gRPC server
+	configFileName          = "sbConfig.json"        // Name of the sing-box configuration file
+	exportListFileName      = "sbExportList.json"    // Name of the export list config file
+	statusChannelCap        = 100                    // Status updates buffered per stream
+	gracefulShutdownTimeout = 2 * time.Second        // Timeout for graceful shutdown
+	rulesetFolderName       = "ruleset"              // Name of the folder to store rulesets
+	coreCleanupName         = "sing-box"             // Name of the cleanup hook that stops sing-box
+	statusCoalesceWindow    = 150 * time.Millisecond // Time a transient status waits for a newer one
 )
 
+// Reasons reported in the detail of a "stopped" status, so the client can tell a requested disconnect from a failure
+const (
+	stopReasonUser        = "user"                // Stop requested through the API
+	stopReasonDisconnect  = "client-disconnected" // The client's status stream closed
+	stopReasonCrash       = "crash"               // Sing-box failed to start, reload or reconnect
+	stopReasonSuspend     = "suspend"             // The system is going to sleep
+	stopReasonShutdown    = "shutdown"            // The helper or the user session is exiting
+	stopReasonTimeout     = "timeout"             // A start did not finish within -start-timeout
+	stopReasonProcessExit = "process-exited"      // A process watched with -monitor exited
+)
+
+// terminalStatuses end a transition and are always delivered; other statuses published within
+// statusCoalesceWindow of each other are coalesced so the client only sees the latest
+var terminalStatuses = []string{"started", "stopped", "download-failed", "config-invalid", "needs-elevation", "clock-skew"}
+
+// errNeedsElevation is returned when sing-box is started while the helper lacks administrator/root privileges
+var errNeedsElevation = status.Error(codes.PermissionDenied, "NEEDS_ELEVATION: the helper must run as an administrator/root to start sing-box")
+
 // Global variable for version
 var Version = "dev"
 
 // Logger wraps multiple loggers with different levels (info, warn, error, fatal)
 type Logger struct {
 	info, warn, error, fatal *log.Logger
+	output                   io.Writer // Destination of the info and warn lines
 }
 
+// withCorrelation returns a copy of the logger whose info, warn and error lines are prefixed with a correlation ID;
+// the shared loggers are left untouched, so concurrent requests and background tasks keep their own prefixes
+func (l *Logger) withCorrelation(id string) *Logger {
+	prefixed := func(logger *log.Logger) *log.Logger {
+		return log.New(logger.Writer(), logger.Prefix()+"["+id+"] ", logger.Flags())
+	}
+	return &Logger{
+		info:   prefixed(l.info),
+		warn:   prefixed(l.warn),
+		error:  prefixed(l.error),
+		fatal:  l.fatal,
+		output: l.output,
+	}
+}
+
+// Infof, Warnf and Errorf let the internal packages log through the helper's loggers
+func (l *Logger) Infof(format string, args ...any)  { l.info.Printf(format, args...) }
+func (l *Logger) Warnf(format string, args ...any)  { l.warn.Printf(format, args...) }
+func (l *Logger) Errorf(format string, args ...any) { l.error.Printf(format, args...) }
+
 // NewLogger initializes a Logger instance with colored prefixes
 func NewLogger() *Logger {
+	stdout := io.MultiWriter(os.Stdout, helperLogs)
+	stderr := io.MultiWriter(os.Stderr, helperLogs)
 	return &Logger{
-		info:  log.New(os.Stdout, color.GreenString("[INFO] "), log.Ldate|log.Ltime|log.Lmsgprefix),
-		warn:  log.New(os.Stdout, color.YellowString("[WARN] "), log.Ldate|log.Ltime|log.Lmsgprefix),
-		error: log.New(os.Stderr, color.RedString("[ERROR] "), log.Ldate|log.Ltime|log.Lmsgprefix),
-		fatal: log.New(os.Stderr, color.New(color.FgRed, color.Bold).Sprint("[FATAL] "), log.Ldate|log.Ltime|log.Lmsgprefix),
+		output: stdout,
+		info:   log.New(stdout, color.GreenString("[INFO] "), log.Ldate|log.Ltime|log.Lmsgprefix),
+		warn:   log.New(stdout, color.YellowString("[WARN] "), log.Ldate|log.Ltime|log.Lmsgprefix),
+		error:  log.New(stderr, color.RedString("[ERROR] "), log.Ldate|log.Ltime|log.Lmsgprefix),
+		fatal:  log.New(stderr, color.New(color.FgRed, color.Bold).Sprint("[FATAL] "), log.Ldate|log.Ltime|log.Lmsgprefix),
 	}
 }
 
 // Server is the main gRPC server implementation
 type Server struct {
 	pb.UnimplementedOblivionServiceServer
-	mu           sync.RWMutex // Synchronizes access to server state
-	statusChange chan string  // Channel to broadcast status updates
-	dirPath      string       // Directory path of the executable
-	instance     *box.Box     // Sing-box instance
-	logger       *Logger      // Logger for server messages
-	exportConfig ExportConfig // Export config
-}
-
-// ExportConfig holds the structure for the export config file
-type ExportConfig struct {
-	Interval int               `json:"interval"`
-	URLs     map[string]string `json:"urls"`
+	mu              sync.RWMutex             // Synchronizes access to server state
+	status          *broadcaster.Broadcaster // Status updates sent to the status stream
+	dirPath         string                   // Directory path of the executable
+	core            core.Manager             // Running sing-box instance
+	logger          *Logger                  // Logger for server messages
+	exportConfig    ruleset.ExportConfig     // Export config
+	rulesetFailures ruleset.Failures         // Last download error of each ruleset file
+	cleanup         *CleanupRegistry         // Cleanup actions to run on every exit path
+	stateMu         sync.Mutex               // Serializes updates of the persisted state
+	power           powerState               // Sing-box state across system sleep
+	reconnect       reconnectState           // Reconnect attempt scheduled in the background
+	flags           *Flags                   // Command-line options
+	reliability     *reliabilityTracker      // Session uptime and failure counters
+	usage           *usageStore              // Persistent traffic statistics, nil if unavailable
+	rateLimit       *rateLimiter             // Throughput caps applied to tunnelled connections
+	connLog         *connectionLog           // Opt-in log of closed connections
+	extraInbounds   map[string]*extraInbound // Inbounds added at runtime, by tag
+	pacSettings     PACSettings              // PAC file server settings
+	pac             *pacServer               // PAC file server, nil while not serving
+	portMapSettings PortMappingSettings      // Router port forwarding settings
+	portMap         *portMapSession          // Ports forwarded for the running instance, nil while stopped
+	elevated        bool                     // Whether the helper runs with administrator/root privileges
+	launchedAt      time.Time                // Time the helper process started
+	operationMu     sync.Mutex               // Serializes state-changing RPCs
+	operationIDMu   sync.Mutex               // Synchronizes access to operationID and operationCancel
+	operationID     string                   // Correlation ID of the running state-changing RPC
+	operationCancel context.CancelFunc       // Cancels the running state-changing RPC, nil if none
+	coreLogs        *logBuffer               // Recent sing-box log lines
+	coreLogStream   *logStream               // Live sing-box log messages for StreamLogs
+	events          *eventStream             // Typed statuses and notifications for StreamEvents
+	statusHistory   *logBuffer               // Recent status updates
+	parent          parentWatch              // Desktop app the helper exits with
+	rebind          rebindState              // Replacement of a bound interface that went down
+	callbacks       callbackRegistry         // Clients hooked into transitions through RegisterHook
+	profileMu       sync.Mutex               // Synchronizes access to profile
+	profile         string                   // Active profile, empty when sbConfig.json is used
+	logLevelMu      sync.Mutex               // Synchronizes access to level
+	level           string                   // Log level set through SetLogLevel, empty to follow the config
+	pause           pauseState               // Instance routing directly after Pause, guarded by mu
+	sysProxy        systemProxyState         // System proxy set through SetSystemProxy, guarded by mu
 }
 
 // NewServer creates and initializes a new Server instance
-func NewServer(logger *Logger) (*Server, error) {
+func NewServer(logger *Logger, cleanup *CleanupRegistry, flags *Flags) (*Server, error) {
 	execDir, err := getExecutableDir()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get executable directory: %w", err)
 	}
 
+	usage, err := openUsageStore(execDir)
+	if err != nil {
+		logger.warn.Printf("Usage statistics disabled: %v", err)
+	}
+
+	elevated := isElevated()
+	initialStatus := "stopped"
+	if !elevated {
+		initialStatus = "needs-elevation"
+	}
+
+	statusBroadcaster := broadcaster.New(statusChannelCap, initialStatus, statusCoalesceWindow, terminalStatuses, func(broadcaster.Event) {
+		logger.warn.Println("Status stream is falling behind, dropping the oldest update")
+	})
+
+	coreLogs := newLogBuffer(coreLogLines)
+	coreLogStream := newLogStream()
+
 	return &Server{
-		statusChange: make(chan string, statusChannelCap),
-		dirPath:      execDir,
-		logger:       logger,
+		status:        statusBroadcaster,
+		core:          core.Manager{LogWriter: coreLogWriter{buffer: coreLogs, stream: coreLogStream}},
+		coreLogs:      coreLogs,
+		coreLogStream: coreLogStream,
+		events:        newEventStream(),
+		launchedAt:    time.Now(),
+		statusHistory: newLogBuffer(statusHistoryLen),
+		dirPath:       execDir,
+		logger:        logger,
+		cleanup:       cleanup,
+		flags:         flags,
+		reliability:   newReliabilityTracker(execDir, logger),
+		usage:         usage,
+		rateLimit:     newRateLimiter(flags.RateLimitDown, flags.RateLimitUp),
+		connLog:       newConnectionLog(execDir),
+		extraInbounds: make(map[string]*extraInbound),
+		elevated:      elevated,
 	}, nil
 }
 
@@ -112,159 +219,248 @@ func getExecutableDir() (string, error) {
 	return filepath.Dir(executable), nil
 }
 
-// loadSingBoxConfig loads and parses the Sing-Box configuration file.
-func (s *Server) loadSingBoxConfig() (*option.Options, error) {
-	configPath := filepath.Join(s.dirPath, configFileName)
-
-	_, err := os.Stat(configPath)
-	if os.IsNotExist(err) {
-		return nil, status.Errorf(codes.NotFound, "sing-box config not found at %s", configPath)
-	}
-
-	content, err := os.ReadFile(configPath)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to read sing-box config: %v", err)
-	}
-
-	var options option.Options
-	if err := json.Unmarshal(content, &options); err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "failed to parse sing-box config: %v", err)
+// configPath returns the path of the sing-box config file in use: the active profile, or sbConfig.json
+func (s *Server) configPath() string {
+	if profile := s.currentProfile(); profile != "" {
+		return s.profilePath(profile)
 	}
+	return filepath.Join(s.dirPath, configFileName)
+}
 
-	return &options, nil
+// loadSingBoxConfig loads and parses the Sing-Box configuration file, applying the overrides set through the API
+// and the bundled rule-sets in place of missing ones
+func (s *Server) loadSingBoxConfig() (*option.Options, error) {
+	options, err := config.Load(s.configPath(), s.applyConfigOverrides, s.applyRuleSetFallbacks)
+	switch {
+	case errors.Is(err, config.ErrNotFound):
+		return nil, status.Errorf(codes.NotFound, "%v", err)
+	case errors.Is(err, config.ErrInvalid):
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+	s.applyLogLevel(options)
+	return options, nil
 }
 
 // loadExportConfig loads and parses the export config file
 func (s *Server) loadExportConfig() error {
-	configPath := filepath.Join(s.dirPath, exportListFileName)
-
-	s.exportConfig.URLs = nil
+	exportConfig, err := ruleset.LoadExportConfig(filepath.Join(s.dirPath, exportListFileName), s.logger)
+	s.exportConfig = exportConfig
+	return err
+}
 
-	_, err := os.Stat(configPath)
-	if os.IsNotExist(err) {
-		s.logger.warn.Printf("Export config not found at %s, skipping...", configPath)
-		return nil // Skip if the config doesn't exist
+// downloadRulesets downloads or refreshes the rulesets listed in the export config until ctx ends.
+// In offline mode, requested or detected, the files on disk are used as they are.
+func (s *Server) downloadRulesets(ctx context.Context, offline bool) error {
+	if err := s.loadExportConfig(); err != nil {
+		return fmt.Errorf("error loading export config: %w", err)
 	}
 
-	content, err := os.ReadFile(configPath)
-	if err != nil {
-		s.logger.error.Printf("Failed to read export config: %v", err)
-		return fmt.Errorf("failed to read export config: %w", err)
+	if len(s.exportConfig.URLs) == 0 {
+		return nil // Nothing to download
 	}
+	s.reportRejectedRulesets()
 
-	if len(content) == 0 {
-		s.logger.warn.Println("Export config is empty, skipping...")
+	if offline || s.flags.Offline || !networkAvailable() {
+		s.logger.info.Println("Offline, skipping ruleset downloads")
+		if stale := s.rulesetDownloader().Stale(s.exportConfig); len(stale) > 0 {
+			s.logger.warn.Printf("Starting with stale or missing rulesets: %s", strings.Join(stale, ", "))
+			s.broadcastEvent("rulesets-stale", map[string]string{"files": strings.Join(stale, ",")})
+		}
 		return nil
 	}
 
-	var config ExportConfig
-	if err := json.Unmarshal(content, &config); err != nil {
-		s.logger.error.Printf("Failed to parse export config: %v", err)
-		return fmt.Errorf("failed to parse export config: %w", err)
-	}
+	s.broadcastStatus("preparing")
+	downloader := s.rulesetDownloader()
+	downloader.Context = ctx
+	return downloader.Update(s.exportConfig)
+}
 
-	if len(config.URLs) == 0 {
-		s.logger.warn.Println("No URLs found in export config, skipping...")
-		return nil
+// rulesetDownloader returns a downloader for the ruleset folder whose temporary files are removed on every exit path
+func (s *Server) rulesetDownloader() *ruleset.Downloader {
+	return &ruleset.Downloader{
+		Dir:       filepath.Join(s.dirPath, rulesetFolderName),
+		Logger:    s.logger,
+		Failures:  &s.rulesetFailures,
+		Allowlist: s.rulesetAllowlist(),
+		Workers:   s.flags.RulesetWorkers,
+		TrackTemp: func(tmpPath string) func() {
+			cleanupName := "temp file " + tmpPath
+			s.cleanup.Register(cleanupName, func() error {
+				if err := os.Remove(tmpPath); err != nil && !os.IsNotExist(err) {
+					return err
+				}
+				return nil
+			})
+			return func() { s.cleanup.Unregister(cleanupName) }
+		},
 	}
-
-	s.exportConfig = config
-	return nil
 }
 
-// downloadRulesets manages the downloading of rulesets based on the export config
-func (s *Server) downloadRulesets() error {
-	if err := s.loadExportConfig(); err != nil {
-		return fmt.Errorf("error loading export config: %w", err)
+// startSingBox starts the Sing-Box process. Cancelling ctx, as Stop and Exit do, aborts the start.
+func (s *Server) startSingBox(parent context.Context, offline bool) error {
+	if !s.elevated {
+		return errNeedsElevation
 	}
 
-	if len(s.exportConfig.URLs) == 0 {
-		return nil // Nothing to download
-	}
+	ctx, cancel := s.startContext(parent)
+	defer cancel()
 
-	rulesetPath := filepath.Join(s.dirPath, rulesetFolderName)
-
-	if _, err := os.Stat(rulesetPath); os.IsNotExist(err) {
-		if err := os.MkdirAll(rulesetPath, os.ModePerm); err != nil {
-			return fmt.Errorf("failed to create ruleset directory: %w", err)
+	// Rulesets cannot be downloaded without a network, so a start that found none goes on offline
+	if !offline && !s.flags.Offline && !s.waitForNetwork(ctx) {
+		if ctx.Err() != nil {
+			return s.startInterrupted(ctx, "waiting for the network")
+		}
+		s.logger.warn.Println("No network available, starting offline")
+		offline = true
+	}
+	if !offline && !s.flags.Offline && s.flags.ClockCheck {
+		if err := s.checkClock(ctx); err != nil {
+			return err
 		}
-		s.logger.info.Printf("Created ruleset directory: %s", rulesetPath)
+	}
+	if err := s.runCallbacks(callbackPreStart, "", true); err != nil {
+		return err
 	}
 
-	s.broadcastStatus("preparing")
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	for filename, url := range s.exportConfig.URLs {
-		filePath := filepath.Join(rulesetPath, filename)
+	if s.core.Running() {
+		return status.Errorf(codes.AlreadyExists, "sing-box is already running")
+	}
+	s.broadcastStatus("starting")
 
-		fileInfo, err := os.Stat(filePath)
-		if os.IsNotExist(err) {
-			if err := s.downloadFile(url, filePath); err != nil {
-				s.logger.error.Printf("Error downloading file %s: %v", filename, err)
-			} else {
-				s.logger.info.Printf("Downloaded file %s from %s", filename, url)
-			}
-			continue
-		} else if err != nil {
-			s.logger.error.Printf("Error checking file %s: %v", filename, err)
-			continue
+	if err := s.downloadRulesets(ctx, offline); err != nil || ctx.Err() != nil {
+		if ctx.Err() != nil {
+			return s.startInterrupted(ctx, "downloading rulesets")
 		}
+		s.publishStatus("download-failed", s.rulesetFailureDetails())
+		return status.Errorf(codes.FailedPrecondition, "Failed to download rulesets: %v", err)
+	}
 
-		if s.exportConfig.Interval <= 0 {
-			s.logger.info.Printf("Skipping interval check for file %s due to invalid interval in config", filename)
-			continue
+	options, err := s.loadSingBoxConfig()
+	if err != nil {
+		if status.Code(err) == codes.InvalidArgument {
+			s.broadcastStatus("config-invalid")
+		} else {
+			s.broadcastStopped(stopReasonCrash)
 		}
+		return err
+	}
 
-		if time.Since(fileInfo.ModTime()) > time.Duration(s.exportConfig.Interval)*24*time.Hour {
-			if err := s.downloadFile(url, filePath); err != nil {
-				s.logger.error.Printf("Error updating file %s: %v", filename, err)
-			} else {
-				s.logger.info.Printf("Updated file %s from %s", filename, url)
-			}
+	err = s.startInstance(ctx, options)
+	if tunCreationFailed(err) && ctx.Err() == nil {
+		// A missing, outdated or broken driver is the usual cause, so repair it and try once more
+		s.logger.warn.Printf("TUN interface could not be created, repairing the driver: %v", err)
+		s.broadcastStatus("repairing-tun-driver")
+		if repairErr := repairTunDriver(); repairErr != nil {
+			s.logger.error.Printf("TUN driver repair failed: %v", repairErr)
 		} else {
-			s.logger.info.Printf("File %s is up to date", filename)
+			err = s.startInstance(ctx, options)
 		}
 	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return s.startInterrupted(ctx, "starting sing-box")
+		}
+		s.broadcastStopped(stopReasonCrash)
+		return err
+	}
+
+	s.broadcastStatus("started")
+	s.logger.info.Println("Sing-box started")
+	s.cleanup.Go(func() { s.notifyCallbacks(callbackPostStart, "") })
 	return nil
 }
 
-// downloadFile downloads a file from a URL to a given path
-func (s *Server) downloadFile(url, filePath string) error {
-	resp, err := http.Get(url)
-	if err != nil {
-		return fmt.Errorf("failed to get URL: %w", err)
+// startContext returns the context bounding a start by the -start-timeout budget
+func (s *Server) startContext(parent context.Context) (context.Context, context.CancelFunc) {
+	if s.flags.StartTimeout <= 0 {
+		return context.WithCancel(parent)
 	}
-	defer resp.Body.Close()
+	return context.WithTimeout(parent, s.flags.StartTimeout)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("server returned non-200 status code: %d", resp.StatusCode)
+// startInterrupted reports a start that used up the -start-timeout budget or was cancelled. A cancelled
+// start broadcasts nothing: the Stop or Exit that cancelled it reports the stop.
+func (s *Server) startInterrupted(ctx context.Context, stage string) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		s.broadcastStopped(stopReasonTimeout)
+		return s.startTimeoutError(stage)
 	}
+	s.logger.info.Printf("Start cancelled while %s", stage)
+	return status.Errorf(codes.Canceled, "start cancelled while %s", stage)
+}
 
-	out, err := os.Create(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+// startTimeoutError reports the stage of a start that used up the -start-timeout budget
+func (s *Server) startTimeoutError(stage string) error {
+	s.logger.error.Printf("Start timed out after %s while %s", s.flags.StartTimeout, stage)
+	return status.Errorf(codes.DeadlineExceeded, "start timed out after %s while %s", s.flags.StartTimeout, stage)
+}
+
+// stopSingBox stops the Sing-Box process, ending the current session for the given reason
+func (s *Server) stopSingBox(reason string) error {
+	s.mu.RLock()
+	running := s.core.Running()
+	s.mu.RUnlock()
+
+	// Only a stop asked for by the user can be vetoed; the others must happen
+	if running {
+		if err := s.runCallbacks(callbackPreStop, reason, reason == stopReasonUser); err != nil {
+			return err
+		}
+	}
+	if err := s.stopSingBoxWithStatus("stopped", reason); err != nil {
+		return err
+	}
+	s.reliability.disconnected()
+	return nil
+}
+
+// stopSingBoxWithStatus stops the Sing-Box process and broadcasts the given status,
+// which lets callers that restart sing-box afterwards avoid reporting a "stopped" state
+func (s *Server) stopSingBoxWithStatus(newStatus, detail string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.core.Running() {
+		return status.Errorf(codes.FailedPrecondition, "sing-box is not running")
+	}
+	if newStatus == "stopped" {
+		s.broadcastStatus("stopping")
 	}
-	defer out.Close()
 
-	_, err = io.Copy(out, resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to copy response body: %w", err)
+	if err := s.stopInstance(); err != nil {
+		if s.core.Running() {
+			s.broadcastStatus("started") // The instance could not be closed and keeps running
+		}
+		return err
 	}
 
+	s.publishStatus(newStatus, map[string]string{"reason": detail})
+	s.logger.info.Println("Sing-box stopped")
 	return nil
 }
 
-// startSingBox starts the Sing-Box process
-func (s *Server) startSingBox() error {
+// reloadSingBox replaces the running instance with one built from the current config file,
+// optionally refreshing the rulesets from the export list first. The transition status is
+// broadcast while the instance is replaced. If the new config fails to start, the previously
+// running options are restored.
+func (s *Server) reloadSingBox(refreshRulesets bool, transition string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if s.instance != nil {
-		return status.Errorf(codes.AlreadyExists, "sing-box is already running")
+	if !s.core.Running() {
+		return status.Errorf(codes.FailedPrecondition, "sing-box is not running")
 	}
 
-	if err := s.downloadRulesets(); err != nil {
-		s.broadcastStatus("download-failed")
-		return status.Errorf(codes.FailedPrecondition, "Failed to download rulesets: %v", err)
+	if refreshRulesets {
+		if err := s.downloadRulesets(context.Background(), false); err != nil {
+			s.publishStatus("download-failed", s.rulesetFailureDetails())
+			return status.Errorf(codes.FailedPrecondition, "Failed to download rulesets: %v", err)
+		}
 	}
 
 	options, err := s.loadSingBoxConfig()
@@ -272,70 +468,196 @@ func (s *Server) startSingBox() error {
 		return err
 	}
 
-	instance, err := box.New(box.Options{
-		Options: *options,
-		Context: context.Background(),
-	})
-	if err != nil {
-		return status.Errorf(codes.Internal, "failed to create sing-box instance: %v", err)
+	s.broadcastStatus(transition)
+	previous := s.core.Options()
+	if err := s.stopInstance(); err != nil {
+		return err
 	}
 
-	if err := instance.Start(); err != nil {
-		return status.Errorf(codes.Internal, "failed to start sing-box: %v", err)
+	if err := s.startInstance(context.Background(), options); err != nil {
+		s.logger.error.Printf("Reload failed, restoring previous config: %v", err)
+		if rollbackErr := s.startInstance(context.Background(), previous); rollbackErr != nil {
+			err = status.Errorf(codes.Internal, "reload failed: %v; restoring previous config failed: %v", err, rollbackErr)
+			s.reliability.failed(err, true)
+			s.reliability.disconnected()
+			s.broadcastStopped(stopReasonCrash)
+			return err
+		}
+		s.broadcastStatus("started")
+		return err
 	}
 
-	s.instance = instance
 	s.broadcastStatus("started")
-	s.logger.info.Println("Sing-box started")
+	s.logger.info.Println("Sing-box reloaded")
 	return nil
 }
 
-// stopSingBox stops the Sing-Box process
-func (s *Server) stopSingBox() error {
+// reloadConfiguration re-reads the export list and sing-box config, restarting sing-box if it is running
+func (s *Server) reloadConfiguration() {
+	s.mu.RLock()
+	running := s.core.Running()
+	s.mu.RUnlock()
+
+	if running {
+		if err := s.reloadSingBox(true, "reloading"); err != nil {
+			s.logger.error.Printf("Reload error: %v", err)
+		}
+		return
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if err := s.loadExportConfig(); err != nil {
+		s.logger.error.Printf("Reload error: %v", err)
+	}
+	if _, err := s.loadSingBoxConfig(); err != nil {
+		s.logger.error.Printf("Reload error: %v", err)
+		return
+	}
+	s.logger.info.Println("Configuration reloaded")
+}
 
-	if s.instance == nil {
-		return status.Errorf(codes.FailedPrecondition, "sing-box is not running")
+// startInstance creates and starts a sing-box instance from the given options; the caller must hold s.mu
+func (s *Server) startInstance(ctx context.Context, options *option.Options) error {
+	enableClashAPI(options)
+	s.writeJournal(newJournalEntry("starting", options))
+
+	err := s.core.Start(ctx, options, func(instance *box.Box) {
+		s.installRateLimit(instance.Router())
+	})
+	if err != nil {
+		s.writeJournal(newJournalEntry("stopped", nil))
+		return status.Errorf(codes.Internal, "%v", err)
 	}
+	s.writeJournal(newJournalEntry("started", options))
 
-	if err := s.instance.Close(); err != nil {
-		return status.Errorf(codes.Internal, "failed to stop sing-box: %v", err)
+	s.restoreSelections()
+	s.resetPausedMode(options)
+	s.startExtraInbounds()
+	if err := s.startPACServer(); err != nil {
+		s.logger.error.Printf("PAC server error: %v", err)
 	}
+	if err := s.applySystemProxy(); err != nil {
+		s.logger.error.Printf("System proxy error: %v", err)
+	}
+	s.startPortMapping()
+	s.cleanup.Register(coreCleanupName, func() error {
+		return s.stopSingBox(stopReasonShutdown)
+	})
+	s.reliability.connected()
+	return nil
+}
 
-	s.instance = nil
-	s.broadcastStatus("stopped")
-	s.logger.info.Println("Sing-box stopped")
+// stopInstance closes the running sing-box instance; the caller must hold s.mu
+func (s *Server) stopInstance() error {
+	s.sampleUsage()
+	s.flushConnectionLog()
+	s.closeExtraInbounds()
+	s.stopPACServer()
+	s.revertSystemProxy()
+	s.stopPortMapping()
+	s.writeJournal(newJournalEntry("stopping", nil))
+	if err := s.core.Stop(); err != nil {
+		return status.Errorf(codes.Internal, "%v", err)
+	}
+	s.writeJournal(newJournalEntry("stopped", nil))
+
+	s.cleanup.Unregister(coreCleanupName)
 	return nil
 }
 
 // Start handles the gRPC Start request to initiate Sing-Box
 func (s *Server) Start(ctx context.Context, req *pb.StartRequest) (*pb.StartResponse, error) {
-	if err := s.startSingBox(); err != nil {
-		s.logger.error.Printf("Start error: %v", err)
+	s.cancelReconnect()
+
+	if !s.elevated {
+		return nil, errNeedsElevation
+	}
+
+	if s.flags.CaptivePortalCheck {
+		s.mu.RLock()
+		running := s.core.Running()
+		s.mu.RUnlock()
+
+		if portal, err := detectCaptivePortal(ctx); err == nil && portal && !running {
+			s.scheduleReconnect(s.waitForCaptivePortal)
+			s.recordDesiredState(true)
+			return &pb.StartResponse{Message: "Captive portal detected, Sing-Box will start after login."}, nil
+		}
+	}
+
+	if err := s.startSingBox(ctx, req.Offline); err != nil {
+		logger := s.requestLogger(ctx)
+		if status.Code(err) == codes.Canceled {
+			logger.info.Println("Start cancelled")
+			return nil, err
+		}
+		logger.error.Printf("Start error: %v", err)
+		s.reliability.failed(err, false)
+		if s.flags.RetryStart && retryableStartError(err) {
+			s.scheduleStartRetry()
+			s.recordDesiredState(true)
+			return &pb.StartResponse{Message: "Sing-Box failed to start, retrying in the background."}, nil
+		}
 		return nil, err
 	}
+	s.recordDesiredState(true)
 	return &pb.StartResponse{Message: "Sing-Box started successfully."}, nil
 }
 
 // Stop handles the gRPC Stop request to terminate Sing-Box
 func (s *Server) Stop(ctx context.Context, req *pb.StopRequest) (*pb.StopResponse, error) {
-	if err := s.stopSingBox(); err != nil {
-		s.logger.error.Printf("Stop error: %v", err)
-		return nil, err
+	pending := s.cancelReconnect() || operationCancelled(ctx)
+	if err := s.stopSingBox(stopReasonUser); err != nil {
+		if !pending || status.Code(err) == codes.Aborted {
+			s.requestLogger(ctx).error.Printf("Stop error: %v", err)
+			return nil, err
+		}
+		s.reliability.disconnected()
+		s.broadcastStopped(stopReasonUser) // Only a background reconnect or an unfinished start was pending
 	}
+	s.recordDesiredState(false)
 	return &pb.StopResponse{Message: "Sing-Box stopped successfully."}, nil
 }
 
-// Exit handles the gRPC Exit request to shut down the service gracefully
-func (s *Server) Exit(ctx context.Context, req *pb.ExitRequest) (*pb.ExitResponse, error) {
-	s.logger.info.Println("Exiting Oblivion-Helper...")
+// Restart handles the gRPC Restart request, stopping and starting Sing-Box as a single operation.
+// If the new start fails, the previous config is restored so the user stays connected.
+func (s *Server) Restart(ctx context.Context, req *pb.RestartRequest) (*pb.RestartResponse, error) {
+	s.cancelReconnect()
+
+	if !s.elevated {
+		return nil, errNeedsElevation
+	}
+
+	s.mu.RLock()
+	running := s.core.Running()
+	s.mu.RUnlock()
 
-	if s.instance != nil {
-		if err := s.stopSingBox(); err != nil {
-			s.logger.error.Printf("Exit stop error: %v", err)
+	if !running {
+		if err := s.startSingBox(ctx, false); err != nil {
+			s.requestLogger(ctx).error.Printf("Restart error: %v", err)
+			if status.Code(err) != codes.Canceled {
+				s.reliability.failed(err, false)
+			}
+			return nil, err
 		}
+		s.recordDesiredState(true)
+		return &pb.RestartResponse{Message: "Sing-Box started successfully."}, nil
+	}
+
+	if err := s.reloadSingBox(true, "restarting"); err != nil {
+		s.requestLogger(ctx).error.Printf("Restart error: %v", err)
+		return nil, err
 	}
+	s.recordDesiredState(true)
+	return &pb.RestartResponse{Message: "Sing-Box restarted successfully."}, nil
+}
+
+// Exit handles the gRPC Exit request to shut down the service gracefully
+func (s *Server) Exit(ctx context.Context, req *pb.ExitRequest) (*pb.ExitResponse, error) {
+	s.requestLogger(ctx).info.Println("Exiting Oblivion-Helper...")
+
+	s.cleanup.Run()
 
 	go func() {
 		time.Sleep(gracefulShutdownTimeout)
@@ -347,33 +669,63 @@ func (s *Server) Exit(ctx context.Context, req *pb.ExitRequest) (*pb.ExitRespons
 
 // StreamStatus streams the current status of Sing-Box to the client
 func (s *Server) StreamStatus(req *pb.StatusRequest, stream pb.OblivionService_StreamStatusServer) error {
-	var lastStatus string
+	var lastStatus broadcaster.Event
+
+	// Subscribe first so no update is missed between reporting the current state and waiting for changes
+	subscription := s.status.Subscribe()
+	defer subscription.Close()
+
+	// Let a client attaching to an already-connected helper know the current state
+	s.mu.RLock()
+	running, paused := s.core.Running(), s.paused()
+	s.mu.RUnlock()
+	if paused {
+		lastStatus = broadcaster.Event{Status: "paused"}
+	} else if running {
+		lastStatus = broadcaster.Event{Status: "started"}
+	} else if !s.elevated {
+		lastStatus = broadcaster.Event{Status: "needs-elevation"}
+	}
+	if lastStatus.Status != "" {
+		if err := stream.Send(&pb.StatusResponse{Status: lastStatus.Status}); err != nil {
+			s.logger.error.Printf("Status stream error: %v", err)
+			return err
+		}
+	}
+
 	for {
 		select {
 		case <-stream.Context().Done(): // Handle client disconnection
 			s.logger.warn.Println("Stream closed by client")
-			if s.instance != nil {
-				if err := s.stopSingBox(); err != nil {
+			if s.core.Running() {
+				if err := s.stopSingBox(stopReasonDisconnect); err != nil {
 					s.logger.error.Printf("Stream stop error: %v", err)
 					return status.Errorf(codes.Aborted, "failed to stop service during stream closure: %v", err)
 				}
 			}
 			return stream.Context().Err()
 
-		case status, ok := <-s.statusChange: // Receive status updates
-			if !ok {
-				s.logger.warn.Println("Status channel closed")
-				return nil // The status channel was closed
-			}
+		case <-subscription.Ready(): // Receive status updates
+			for {
+				event, ok := subscription.Next()
+				if !ok {
+					break
+				}
+
+				if event.Status == lastStatus.Status && event.Detail == lastStatus.Detail {
+					continue
+				}
+				lastStatus = event
 
-			if status == lastStatus {
-				continue
+				if err := stream.Send(&pb.StatusResponse{Status: event.Status, Detail: event.Detail, CorrelationId: event.CorrelationID}); err != nil {
+					s.logger.error.Printf("Status stream error: %v", err)
+					return err // Failed to send status update
+				}
 			}
-			lastStatus = status
 
-			if err := stream.Send(&pb.StatusResponse{Status: status}); err != nil {
-				s.logger.error.Printf("Status stream error: %v", err)
-				return err // Failed to send status update
+			if subscription.Done() {
+				s.logger.warn.Println("Status channel closed")
+				return nil // The broadcaster was closed
 			}
 		}
 	}
@@ -381,75 +733,252 @@ func (s *Server) StreamStatus(req *pb.StatusRequest, stream pb.OblivionService_S
 
 // broadcastStatus sends a status update to the status channel
 func (s *Server) broadcastStatus(status string) {
-	select {
-	case s.statusChange <- status:
-		// Successfully sent status update
-	default:
-		s.logger.warn.Println("Status channel full, dropping update")
+	s.publishStatus(status, nil)
+}
+
+// broadcastStopped reports that the session ended, with the reason as detail
+func (s *Server) broadcastStopped(reason string) {
+	s.publishStatus("stopped", map[string]string{"reason": reason})
+}
+
+// publishStatus changes the current status and sends it with optional details
+func (s *Server) publishStatus(status string, details map[string]string) {
+	details = eventDetails(details)
+	detail := eventMessage(status, details)
+	s.recordStatus(status, detail)
+	s.status.Publish(broadcaster.Event{Status: status, Detail: detail, CorrelationID: s.currentOperationID()})
+	s.publishEvent(status, detail, details, true)
+	s.runHooks(status, detail)
+}
+
+// broadcastEvent sends an update to the status channel without changing the current status,
+// used for notifications such as usage alerts
+func (s *Server) broadcastEvent(status string, details map[string]string) {
+	details = eventDetails(details)
+	detail := eventMessage(status, details)
+	s.recordStatus(status, detail)
+	s.status.Notify(broadcaster.Event{Status: status, Detail: detail, CorrelationID: s.currentOperationID()})
+	s.publishEvent(status, detail, details, false)
+	s.runHooks(status, detail)
+}
+
+// recordStatus keeps a status update in the history included in debug bundles
+func (s *Server) recordStatus(status, detail string) {
+	line := time.Now().Format(time.DateTime) + " " + status
+	if detail != "" {
+		line += " (" + detail + ")"
+	}
+	if id := s.currentOperationID(); id != "" {
+		line += " [" + id + "]"
 	}
+	s.statusHistory.add(line)
+}
+
+// currentStatus returns the last broadcast status
+func (s *Server) currentStatus() string {
+	return s.status.Current()
 }
 
 // main initializes the logger, checks admin privileges, creates the server, and starts the gRPC server
 func main() {
 	logger := NewLogger()
-	handleCommandLineArgs(logger)
+	flags := handleCommandLineArgs(logger)
 
-	if !isadmin.Check() {
-		logger.fatal.Fatal("Oblivion-Helper must be run as an administrator/root.")
-	}
+	cleanup := NewCleanupRegistry(logger)
+	defer cleanup.RunOnPanic()
 
-	server, err := NewServer(logger)
+	server, err := NewServer(logger, cleanup, flags)
 	if err != nil {
-		logger.fatal.Fatalf("Failed to create server: %v", err)
-	}
+		cleanup.Fatalf("Failed to create server: %v", err)
+	}
+
+	if len(flags.Monitors) > 0 {
+		cleanup.Go(func() { server.watchProcesses(flags.Monitors) })
+	}
+	if flags.ParentPID > 0 {
+		server.watchParent(int32(flags.ParentPID))
+	}
+
+	// Without privileges only the gRPC server runs, so the client can learn why and relaunch the helper elevated
+	if !server.elevated {
+		logger.warn.Println("Oblivion-Helper is not running as an administrator/root, Sing-Box cannot be started until it is relaunched with elevated privileges.")
+		startGRPCServer(server, logger)
+		return
+	}
+	server.recoverJournal()
+	server.loadProfileSettings()
+	server.loadPACSettings()
+	server.loadPortMappingSettings()
+
+	if flags.Resume {
+		cleanup.Go(server.resumeLastState)
+	}
+	cleanup.Go(func() { server.watchConfig(flags.AutoReload) })
+	server.loadUsageThresholds()
+	cleanup.Go(server.watchUsage)
+	server.loadConnectionLogSettings()
+	cleanup.Go(server.watchConnections)
+	if flags.AutoReconnect {
+		cleanup.Go(server.watchNetwork)
+	}
+	cleanup.Go(server.watchEndpoints)
+	cleanup.Go(server.watchBindInterface)
+	if flags.HealthCheck > 0 {
+		cleanup.Go(func() { server.watchOutboundHealth(flags.HealthCheck) })
+	}
+	cleanup.Go(func() {
+		err := watchPowerEvents(func(event PowerEvent) {
+			defer cleanup.RunOnPanic() // Handlers may run on goroutines of the platform watcher
+			server.handlePowerEvent(event, flags.ResumeOnWake)
+		})
+		if err != nil {
+			logger.warn.Printf("Power event monitoring unavailable: %v", err)
+		}
+	})
 
 	startGRPCServer(server, logger)
 }
 
-// handleCommandLineArgs processes command-line arguments like "version"
-func handleCommandLineArgs(logger *Logger) {
-	if len(os.Args) > 1 {
+// Flags holds the command-line options of the helper
+type Flags struct {
+	Resume             bool            // Restore the last desired connection state on launch
+	AutoReload         bool            // Apply config file changes while sing-box is running
+	ResumeOnWake       bool            // Re-establish the tunnel after the system wakes up
+	AutoReconnect      bool            // Restart the tunnel when the underlying network changes
+	CaptivePortalCheck bool            // Hold the tunnel back while a captive portal intercepts traffic
+	RateLimitDown      int64           // Download cap in bytes per second, 0 for unlimited
+	RateLimitUp        int64           // Upload cap in bytes per second, 0 for unlimited
+	BindInterface      string          // Network interface outbound connections are bound to, empty for automatic
+	Offline            bool            // Never download rulesets, starting with the files on disk
+	NetworkWait        time.Duration   // How long a start waits for the network to come up
+	RetryStart         bool            // Keep retrying a failed start in the background
+	StartTimeout       time.Duration   // Time budget of a whole start, 0 for unlimited
+	Monitors           processMonitors // Companion processes to watch and the action taken when they exit
+	ParentPID          int             // PID of the desktop app the helper must not outlive, 0 to not watch one
+	ParentGrace        time.Duration   // Delay between the parent's exit and the helper's
+	HealthCheck        time.Duration   // Interval of the selector group health checks, 0 to disable failover
+	ClockCheck         bool            // Refuse to start while the system clock is far off
+	Hooks              eventHooks      // Commands run on status events
+	HookUser           string          // User hook commands run as, empty for the user who invoked sudo
+	Listen             string          // Address of the gRPC server: TCP address, unix socket or named pipe
+	TLS                bool            // Serve gRPC over TLS, accepting only clients with a certificate of the local CA
+	AllowedClients     allowedClients  // Executables allowed to connect, empty to accept any process
+	RulesetSources     rulesetSources  // Ruleset download sources allowed besides the official ones
+	DropPrivileges     bool            // Run as the invoking user with network capabilities only once listening
+	RulesetWorkers     int             // Number of rulesets downloaded at once
+}
+
+// handleCommandLineArgs processes command-line commands like "version" and parses option flags
+func handleCommandLineArgs(logger *Logger) *Flags {
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
 		switch os.Args[1] {
 		case "version":
 			logger.info.Printf("Oblivion-Helper Version: %s\n", Version)
+			logger.info.Printf("Sing-Box Version: %s\n", coreVersion())
 			logger.info.Printf("Environment: %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+		case "bundle":
+			runBundleCommand(logger, os.Args[2:])
+		case "tls-export":
+			if len(os.Args) < 3 {
+				logger.fatal.Fatalf("Usage: %s tls-export <folder>", filepath.Base(os.Args[0]))
+			}
+			runTLSExportCommand(logger, os.Args[2])
 		default:
-			logger.warn.Printf("Unknown command '%s'.\nUse 'version' to display version information.\n", os.Args[1])
+			logger.warn.Printf("Unknown command '%s'.\nUse 'version' to display version information, 'bundle' to save a debug bundle or 'tls-export' to export the TLS client credentials.\n", os.Args[1])
 		}
 		os.Exit(0)
 	}
+
+	flags := &Flags{}
+	flag.BoolVar(&flags.Resume, "resume", false, "restore the last connection state (for launching at boot as a service)")
+	flag.BoolVar(&flags.AutoReload, "auto-reload", false, "reload sing-box automatically when the config file changes")
+	flag.BoolVar(&flags.ResumeOnWake, "resume-on-wake", true, "reconnect sing-box after the system wakes up if it was running before sleep")
+	flag.BoolVar(&flags.AutoReconnect, "auto-reconnect", true, "restart sing-box when the underlying network changes")
+	flag.BoolVar(&flags.CaptivePortalCheck, "captive-portal-check", false, "detect captive portals before connecting")
+	flag.Int64Var(&flags.RateLimitDown, "rate-limit-down", 0, "cap the tunnel download rate in bytes per second (0 for unlimited)")
+	flag.Int64Var(&flags.RateLimitUp, "rate-limit-up", 0, "cap the tunnel upload rate in bytes per second (0 for unlimited)")
+	flag.DurationVar(&flags.StartTimeout, "start-timeout", 3*time.Minute, "abort a start that takes longer than this, including downloads (0 for unlimited)")
+	flag.BoolVar(&flags.RetryStart, "retry-start", false, "keep retrying a failed start in the background until the network allows it")
+	flag.DurationVar(&flags.NetworkWait, "network-wait", 0, "wait up to this long for the network before starting (e.g. 30s)")
+	flag.BoolVar(&flags.Offline, "offline", false, "never download rulesets and start with the files on disk")
+	flag.StringVar(&flags.BindInterface, "bind-interface", "", "bind outbound connections to this network interface instead of the default route")
+	flag.IntVar(&flags.ParentPID, "parent-pid", 0, "stop sing-box and exit when the process with this PID (the desktop app) exits")
+	flag.DurationVar(&flags.ParentGrace, "parent-grace", 10*time.Second, "delay between the parent's exit and the helper's")
+	flag.BoolVar(&flags.ClockCheck, "clock-check", true, "refuse to start while the system clock is more than 5 minutes off")
+	flag.DurationVar(&flags.HealthCheck, "health-check", 0, "check the selected outbound of selector groups this often and fail over when it dies (e.g. 1m)")
+	flag.Var(&flags.Hooks, "hook", "run a command on an event, e.g. connected='mount /mnt/share' (repeatable)")
+	flag.StringVar(&flags.HookUser, "hook-user", "", "user hook commands run as (default: the user who invoked sudo or pkexec, or the console user on Windows)")
+	flag.Var(&flags.Monitors, "monitor", "watch a process by `name or PID` and notify, stop or exit when it exits, e.g. warp-plus:stop (repeatable)")
+	flag.StringVar(&flags.Listen, "listen", serverAddress, "gRPC server address: host:port, unix:///path for a unix socket (not on Windows) or pipe:\\\\.\\pipe\\name for a named pipe (Windows)")
+	flag.BoolVar(&flags.TLS, "tls", false, "serve gRPC over TLS and require the client certificate exported by 'tls-export'")
+	flag.Var(&flags.AllowedClients, "allow-client", "only accept control connections from the executable at `path` (repeatable, requires a unix socket or named pipe)")
+	flag.Var(&flags.RulesetSources, "ruleset-source", "also allow ruleset downloads from `[scheme://]host` and its subdomains, * for any host (repeatable)")
+	flag.BoolVar(&flags.DropPrivileges, "drop-privileges", false, "once listening, run as the user who invoked sudo or pkexec, keeping only the network capabilities (Linux)")
+	flag.IntVar(&flags.RulesetWorkers, "ruleset-workers", ruleset.DefaultWorkers, "number of rulesets downloaded at once")
+	flag.Parse()
+	return flags
 }
 
 // startGRPCServer starts the gRPC server and handles termination signals
 func startGRPCServer(server *Server, logger *Logger) {
-	lis, err := net.Listen(protocolType, serverAddress)
+	lis, removeListener, err := listen(server.flags.Listen)
 	if err != nil {
-		logger.fatal.Fatalf("Failed to listen: %v", err)
+		server.cleanup.Fatalf("Failed to listen: %v", err)
+	}
+	defer removeListener()
+
+	if len(server.flags.AllowedClients) > 0 {
+		if lis, err = verifyPeers(lis, server.flags.AllowedClients, logger); err != nil {
+			server.cleanup.Fatalf("%v", err)
+		}
+	}
+
+	serverOptions := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(server.cleanup.UnaryInterceptor, server.logUnary),
+		grpc.ChainStreamInterceptor(server.cleanup.StreamInterceptor, server.logStream),
 	}
+	if server.flags.TLS {
+		creds, err := serverCredentials(filepath.Join(server.dirPath, tlsFolderName))
+		if err != nil {
+			server.cleanup.Fatalf("Failed to set up TLS: %v", err)
+		}
+		serverOptions = append(serverOptions, grpc.Creds(creds))
+	}
+	grpcServer := grpc.NewServer(serverOptions...)
 
-	grpcServer := grpc.NewServer()
+	if server.flags.DropPrivileges && server.elevated {
+		if err := server.dropPrivileges(); err != nil {
+			server.cleanup.Fatalf("Failed to drop privileges: %v", err)
+		}
+	}
 	pb.RegisterOblivionServiceServer(grpcServer, server)
 
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
 
-	go func() {
-		logger.info.Printf("Server started on: %s", serverAddress)
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	server.cleanup.Go(func() {
+		for range reload {
+			logger.info.Println("Received SIGHUP, reloading configuration...")
+			server.reloadConfiguration()
+		}
+	})
+
+	server.cleanup.Go(func() {
+		logger.info.Printf("Server started on: %s", server.flags.Listen)
 		if err := grpcServer.Serve(lis); err != nil {
-			logger.fatal.Fatalf("Failed to serve: %v", err)
+			server.cleanup.Fatalf("Failed to serve: %v", err)
 		}
-	}()
+	})
 
 	<-shutdown
 	logger.warn.Println("Received termination signal, shutting down...")
 
-	if server.instance != nil {
-		if err := server.stopSingBox(); err != nil {
-			logger.error.Printf("Shutdown stop error: %v", err)
-		}
-	}
+	server.cleanup.Run()
 
-	close(server.statusChange)
+	server.status.Close()
+	server.usage.close()
 	grpcServer.GracefulStop()
 
 	logger.info.Println("Server terminated gracefully")
@@ -0,0 +1,250 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	"github.com/shirou/gopsutil/v4/process"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Actions taken when a monitored process exits. Each action includes the ones before it.
+const (
+	monitorActionNotify = "notify" // Broadcast "process-exited" with the process as detail
+	monitorActionStop   = "stop"   // Stop sing-box
+	monitorActionExit   = "exit"   // Stop sing-box and exit the helper
+)
+
+// processMonitorInterval is how often the monitored processes are checked
+const processMonitorInterval = 2 * time.Second
+
+// processMonitor watches a companion process, such as warp-plus or the desktop app, by executable name or PID
+type processMonitor struct {
+	name   string // Executable name, empty when watching a PID
+	pid    int32
+	action string
+}
+
+// String returns the name or PID of the monitored process
+func (m processMonitor) String() string {
+	if m.name != "" {
+		return m.name
+	}
+	return strconv.Itoa(int(m.pid))
+}
+
+// processMonitors is the repeatable -monitor flag, given as <name or PID>[:<action>]
+type processMonitors []processMonitor
+
+// String implements flag.Value
+func (m *processMonitors) String() string {
+	var values []string
+	for _, monitor := range *m {
+		values = append(values, monitor.String()+":"+monitor.action)
+	}
+	return strings.Join(values, ",")
+}
+
+// Set implements flag.Value
+func (m *processMonitors) Set(value string) error {
+	target, action, found := strings.Cut(value, ":")
+	if !found {
+		action = monitorActionNotify
+	}
+	switch action {
+	case monitorActionNotify, monitorActionStop, monitorActionExit:
+	default:
+		return fmt.Errorf("unknown action %q, expected notify, stop or exit", action)
+	}
+	if target == "" {
+		return fmt.Errorf("missing process name or PID")
+	}
+
+	monitor := processMonitor{action: action}
+	if pid, err := strconv.ParseInt(target, 10, 32); err == nil {
+		monitor.pid = int32(pid)
+	} else {
+		monitor.name = target
+	}
+	*m = append(*m, monitor)
+	return nil
+}
+
+// processRunning reports whether the monitored process is running. A process watched by name
+// matches any executable with that name, with or without the .exe extension.
+func processRunning(monitor processMonitor) (bool, error) {
+	if monitor.name == "" {
+		return process.PidExists(monitor.pid)
+	}
+
+	processes, err := process.Processes()
+	if err != nil {
+		return false, err
+	}
+	for _, p := range processes {
+		name, err := p.Name()
+		if err != nil {
+			continue
+		}
+		if strings.EqualFold(strings.TrimSuffix(name, ".exe"), strings.TrimSuffix(monitor.name, ".exe")) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// watchProcesses reacts to the exit of the monitored processes. A process watched by PID counts as exited
+// once it is gone; one watched by name must have been seen running first, and is watched again if it restarts.
+func (s *Server) watchProcesses(monitors []processMonitor) {
+	seen := make([]bool, len(monitors))
+	for i, monitor := range monitors {
+		seen[i] = monitor.name == ""
+		s.logger.info.Printf("Monitoring process %s (on exit: %s)", monitor, monitor.action)
+	}
+
+	ticker := time.NewTicker(processMonitorInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for i, monitor := range monitors {
+			running, err := processRunning(monitor)
+			if err != nil {
+				s.logger.error.Printf("Failed to check process %s: %v", monitor, err)
+				continue
+			}
+			if running {
+				seen[i] = true
+				continue
+			}
+			if !seen[i] {
+				continue
+			}
+			seen[i] = false
+			s.handleProcessExit(monitor)
+		}
+	}
+}
+
+// handleProcessExit applies the action of a monitor whose process exited
+func (s *Server) handleProcessExit(monitor processMonitor) {
+	s.logger.warn.Printf("Monitored process %s exited", monitor)
+	s.broadcastEvent("process-exited", map[string]string{"process": monitor.String()})
+
+	switch monitor.action {
+	case monitorActionStop:
+		s.mu.RLock()
+		running := s.core.Running()
+		s.mu.RUnlock()
+		if running {
+			if err := s.stopSingBox(stopReasonProcessExit); err != nil {
+				s.logger.error.Printf("Stop error: %v", err)
+			}
+		}
+	case monitorActionExit:
+		s.logger.info.Println("Exiting Oblivion-Helper...")
+		s.cleanup.Run()
+		os.Exit(0)
+	}
+}
+
+// parentWatch tracks the desktop app that launched the helper
+type parentWatch struct {
+	mu     sync.Mutex
+	cancel context.CancelFunc // Ends the current watch, including a pending shutdown
+}
+
+// watchParent watches the given parent process, replacing the previous one. If it exits without
+// calling Exit, sing-box is stopped and the helper exits after the -parent-grace period, unless a
+// new parent registers in the meantime.
+func (s *Server) watchParent(pid int32) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s.parent.mu.Lock()
+	if s.parent.cancel != nil {
+		s.parent.cancel()
+	}
+	s.parent.cancel = cancel
+	s.parent.mu.Unlock()
+
+	s.logger.info.Printf("Watching parent process %d", pid)
+	s.cleanup.Go(func() {
+		ticker := time.NewTicker(processMonitorInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if running, err := process.PidExists(pid); err != nil || running {
+				continue
+			}
+			s.handleParentExit(ctx, pid)
+			return
+		}
+	})
+}
+
+// handleParentExit stops sing-box and exits the helper once the grace period passes without a new parent
+func (s *Server) handleParentExit(ctx context.Context, pid int32) {
+	s.logger.warn.Printf("Parent process %d exited, shutting down in %s", pid, s.flags.ParentGrace)
+	s.broadcastEvent("process-exited", map[string]string{"process": strconv.Itoa(int(pid))})
+
+	s.mu.RLock()
+	running := s.core.Running()
+	s.mu.RUnlock()
+	if running {
+		if err := s.stopSingBox(stopReasonProcessExit); err != nil {
+			s.logger.error.Printf("Stop error: %v", err)
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+		s.logger.info.Println("A new parent registered, shutdown cancelled")
+	case <-time.After(s.flags.ParentGrace):
+		s.logger.info.Println("Exiting Oblivion-Helper...")
+		s.cleanup.Run()
+		os.Exit(0)
+	}
+}
+
+// RegisterParent handles the gRPC RegisterParent request, a handshake through which the desktop app
+// gives its PID so the helper does not outlive it
+func (s *Server) RegisterParent(ctx context.Context, req *pb.RegisterParentRequest) (*pb.RegisterParentResponse, error) {
+	if req.Pid <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid PID %d", req.Pid)
+	}
+	if running, err := process.PidExists(req.Pid); err != nil || !running {
+		return nil, status.Errorf(codes.NotFound, "process %d is not running", req.Pid)
+	}
+
+	s.watchParent(req.Pid)
+	return &pb.RegisterParentResponse{}, nil
+}
@@ -0,0 +1,198 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// tlsFolderName is the folder next to the executable holding the local CA and the certificates of -tls
+const tlsFolderName = "tls"
+
+// Files of the local CA and of the server and client certificates it issues
+const (
+	caCertFile     = "ca.crt"
+	caKeyFile      = "ca.key" // Kept by earlier versions, removed since the CA key is discarded once used
+	serverCertFile = "server.crt"
+	serverKeyFile  = "server.key"
+	clientCertFile = "client.crt"
+	clientKeyFile  = "client.key"
+)
+
+// certificateLifetime is how long the generated CA and certificates are valid
+const certificateLifetime = 10 * 365 * 24 * time.Hour
+
+// ensureCertificates creates the local CA and the server and client certificates in dir unless they exist.
+// The CA key is discarded once both certificates are issued, so nothing on disk can issue more of them;
+// replacing the certificates means deleting the folder and exporting the new client credentials.
+func ensureCertificates(dir string) error {
+	if _, err := os.Stat(filepath.Join(dir, caCertFile)); err == nil {
+		if err := os.Remove(filepath.Join(dir, caKeyFile)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", caKeyFile, err)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	caTemplate := certificateTemplate("Oblivion-Helper local CA")
+	caTemplate.IsCA = true
+	caTemplate.BasicConstraintsValid = true
+	caTemplate.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return fmt.Errorf("failed to create the CA: %w", err)
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return err
+	}
+
+	serverTemplate := certificateTemplate("Oblivion-Helper")
+	serverTemplate.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	serverTemplate.DNSNames = []string{"localhost"}
+	serverTemplate.IPAddresses = []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback}
+	clientTemplate := certificateTemplate("Oblivion Desktop")
+	clientTemplate.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+
+	// The CA certificate is written last, so an interrupted run starts over
+	issued := []struct {
+		template          *x509.Certificate
+		certFile, keyFile string
+	}{
+		{serverTemplate, serverCertFile, serverKeyFile},
+		{clientTemplate, clientCertFile, clientKeyFile},
+	}
+	for _, cert := range issued {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return err
+		}
+		der, err := x509.CreateCertificate(rand.Reader, cert.template, caCert, &key.PublicKey, caKey)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", cert.certFile, err)
+		}
+		if err := writeCertificate(dir, cert.certFile, cert.keyFile, der, key); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, caCertFile), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", caCertFile, err)
+	}
+	return nil
+}
+
+// certificateTemplate returns a certificate template with a random serial number
+func certificateTemplate(commonName string) *x509.Certificate {
+	serial, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	now := time.Now()
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(certificateLifetime),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+}
+
+// writeCertificate writes a certificate and its private key as PEM; the key is only readable by its owner
+func writeCertificate(dir, certFile, keyFile string, der []byte, key *ecdsa.PrivateKey) error {
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, keyFile), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", keyFile, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, certFile), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", certFile, err)
+	}
+	return nil
+}
+
+// serverCredentials returns TLS credentials for the gRPC server that only accept clients presenting
+// a certificate issued by the local CA, creating the CA on first use
+func serverCredentials(dir string) (credentials.TransportCredentials, error) {
+	if err := ensureCertificates(dir); err != nil {
+		return nil, err
+	}
+
+	certificate, err := tls.LoadX509KeyPair(filepath.Join(dir, serverCertFile), filepath.Join(dir, serverKeyFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the server certificate: %w", err)
+	}
+	caPEM, err := os.ReadFile(filepath.Join(dir, caCertFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the CA: %w", err)
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("invalid CA certificate in %s", caCertFile)
+	}
+
+	return credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS13,
+	}), nil
+}
+
+// runTLSExportCommand implements the "tls-export" command, copying the CA certificate and the client
+// credentials to the given folder for the desktop app, creating the local CA first if needed
+func runTLSExportCommand(logger *Logger, target string) {
+	dirPath, err := getExecutableDir()
+	if err != nil {
+		logger.fatal.Fatalf("%v", err)
+	}
+	dir := filepath.Join(dirPath, tlsFolderName)
+	if err := ensureCertificates(dir); err != nil {
+		logger.fatal.Fatalf("Failed to create the certificates: %v", err)
+	}
+
+	if err := os.MkdirAll(target, 0o700); err != nil {
+		logger.fatal.Fatalf("Failed to create %s: %v", target, err)
+	}
+	for _, name := range []string{caCertFile, clientCertFile, clientKeyFile} {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			logger.fatal.Fatalf("Failed to read %s: %v", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(target, name), content, 0o600); err != nil {
+			logger.fatal.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	logger.info.Printf("Client credentials exported to %s", target)
+}
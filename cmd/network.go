@@ -0,0 +1,206 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackpal/gateway"
+)
+
+// Network polling intervals
+const (
+	networkCheckInterval = 3 * time.Second // How often the network interfaces are compared against the last known state
+	networkWaitInterval  = time.Second     // How often the network is checked while a start waits for it
+)
+
+// networkFingerprint summarizes the usable network interfaces and their addresses, ignoring loopback
+// and the addresses of the helper's own TUN interface. An empty fingerprint means no usable network.
+func networkFingerprint(ignore []netip.Prefix) (string, error) {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return "", err
+	}
+
+	var entries []string
+	for _, iface := range interfaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+
+		var addresses []string
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok {
+				continue
+			}
+			ip, ok := netip.AddrFromSlice(ipNet.IP)
+			if !ok || ip.IsLinkLocalUnicast() || prefixesContain(ignore, ip.Unmap()) {
+				continue
+			}
+			addresses = append(addresses, ip.Unmap().String())
+		}
+		if len(addresses) == 0 {
+			continue
+		}
+
+		sort.Strings(addresses)
+		entries = append(entries, iface.Name+"="+strings.Join(addresses, ","))
+	}
+
+	sort.Strings(entries)
+	return strings.Join(entries, ";"), nil
+}
+
+// networkAvailable reports whether any usable network interface is up. If the interfaces cannot be read,
+// the network is assumed to be available.
+func networkAvailable() bool {
+	fingerprint, err := networkFingerprint(nil)
+	return err != nil || fingerprint != ""
+}
+
+// networkReady reports whether a usable interface is up and a default gateway is configured
+func networkReady() bool {
+	if !networkAvailable() {
+		return false
+	}
+	_, err := gateway.DiscoverGateway()
+	return err == nil
+}
+
+// waitForNetwork checks that the network is ready before a start. If it is not, it waits up to the
+// -network-wait timeout or until ctx ends, broadcasting "waiting-for-network", and reports whether the network came up.
+func (s *Server) waitForNetwork(ctx context.Context) bool {
+	if networkReady() {
+		return true
+	}
+	if s.flags.NetworkWait <= 0 {
+		return false
+	}
+
+	s.logger.warn.Printf("Network is not ready, waiting up to %s...", s.flags.NetworkWait)
+	s.broadcastStatus("waiting-for-network")
+
+	ticker := time.NewTicker(networkWaitInterval)
+	defer ticker.Stop()
+	deadline := time.After(s.flags.NetworkWait)
+	for {
+		select {
+		case <-ticker.C:
+			if networkReady() {
+				s.logger.info.Println("Network is ready")
+				return true
+			}
+		case <-deadline:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// prefixesContain reports whether any of the prefixes contains the address
+func prefixesContain(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// watchNetwork polls the network interfaces while sing-box is running and restarts the tunnel
+// when the underlying network changes (Wi-Fi to LTE, docking, another VPN coming up)
+func (s *Server) watchNetwork() {
+	ticker := time.NewTicker(networkCheckInterval)
+	defer ticker.Stop()
+
+	// An empty fingerprint (no usable network) is an ordinary state, so whether a baseline and a pending
+	// state were recorded is tracked separately
+	var baseline, pending string
+	var initialized, hasPending bool
+	for range ticker.C {
+		s.mu.RLock()
+		running := s.core.Running()
+		options := s.core.Options()
+		s.mu.RUnlock()
+
+		if !running {
+			initialized, hasPending = false, false
+			continue
+		}
+
+		current, err := networkFingerprint(tunPrefixes(options))
+		if err != nil {
+			s.logger.error.Printf("Failed to inspect network interfaces: %v", err)
+			continue
+		}
+
+		if !initialized || current == baseline {
+			if !initialized {
+				baseline, initialized = current, true
+			}
+			hasPending = false
+			continue
+		}
+
+		// Interfaces often change in several steps, so act only once the new state held for a full interval
+		if !hasPending || current != pending {
+			pending, hasPending = current, true
+			continue
+		}
+		baseline, hasPending = current, false
+
+		s.handleNetworkChange(current)
+	}
+}
+
+// handleNetworkChange reconnects the tunnel in place on the new network, or reports that no network is available.
+// The network transitions are notifications: the instance keeps running, so the status stays "started". With the
+// captive portal check enabled the tunnel is restarted instead, once no portal intercepts the new network.
+func (s *Server) handleNetworkChange(fingerprint string) {
+	if fingerprint == "" {
+		s.logger.warn.Println("No usable network, waiting for connectivity...")
+		s.broadcastEvent("network-down", nil)
+		return
+	}
+
+	s.logger.info.Printf("Network changed: %s", fingerprint)
+	s.broadcastEvent("network-changed", map[string]string{"fingerprint": fingerprint})
+
+	if s.flags.CaptivePortalCheck {
+		// The probe has to reach the new network directly, so the tunnel stays stopped while a portal intercepts it
+		if err := s.stopSingBoxWithStatus("reconnecting", "network-changed"); err == nil {
+			s.scheduleReconnect(s.waitForCaptivePortal)
+			return
+		}
+	}
+
+	s.reliability.reconnected()
+	if err := s.reloadSingBox(false, "reconnecting"); err != nil {
+		s.logger.error.Printf("Network change reconnect error: %v", err)
+	}
+}
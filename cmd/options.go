@@ -0,0 +1,253 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	option "github.com/sagernet/sing-box/option"
+)
+
+// inboundInfo holds the inbound fields the helper inspects, decoded from the sing-box options
+type inboundInfo struct {
+	Type           string   `json:"type"`
+	Tag            string   `json:"tag"`
+	InterfaceName  string   `json:"interface_name"`
+	Address        listable `json:"address"`
+	Inet4Address   listable `json:"inet4_address"`
+	Inet6Address   listable `json:"inet6_address"`
+	AutoRoute      bool     `json:"auto_route"`
+	StrictRoute    bool     `json:"strict_route"`
+	SetSystemProxy bool     `json:"set_system_proxy"`
+	RouteTable     int      `json:"iproute2_table_index"`
+	Listen         string   `json:"listen"`
+	ListenPort     int      `json:"listen_port"`
+	Users          []any    `json:"users"`
+	Password       string   `json:"password"`
+}
+
+// authenticated reports whether the inbound requires clients to authenticate
+func (i inboundInfo) authenticated() bool {
+	return len(i.Users) > 0 || i.Password != ""
+}
+
+// firstUser returns the username and password of the first user of the inbound, if it has users
+func (i inboundInfo) firstUser() (string, string, bool) {
+	if len(i.Users) == 0 {
+		return "", "", false
+	}
+	user, _ := i.Users[0].(map[string]any)
+	username, _ := user["username"].(string)
+	password, _ := user["password"].(string)
+	return username, password, true
+}
+
+// localAddress returns the address local clients use to reach the inbound
+func (i inboundInfo) localAddress() string {
+	host := i.Listen
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	return net.JoinHostPort(host, strconv.Itoa(i.ListenPort))
+}
+
+// listable decodes sing-box list fields, which accept either a single value or an array
+type listable []string
+
+// UnmarshalJSON accepts both a single string and an array of strings
+func (l *listable) UnmarshalJSON(content []byte) error {
+	var single string
+	if err := json.Unmarshal(content, &single); err == nil {
+		*l = listable{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(content, &list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// inspectInbounds decodes the inbounds of the given options.
+// The options are round-tripped through JSON so only the documented config keys are relied upon.
+func inspectInbounds(options *option.Options) []inboundInfo {
+	if options == nil {
+		return nil
+	}
+
+	content, err := json.Marshal(options)
+	if err != nil {
+		return nil
+	}
+
+	var config struct {
+		Inbounds []inboundInfo `json:"inbounds"`
+	}
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil
+	}
+	return config.Inbounds
+}
+
+// ruleSetInfo holds the rule-set fields the helper inspects
+type ruleSetInfo struct {
+	Type   string             `json:"type"`
+	Tag    string             `json:"tag"`
+	Format string             `json:"format"`
+	Path   string             `json:"path"`
+	Rules  []headlessRuleInfo `json:"rules"` // Rules of an inline rule-set
+}
+
+// headlessRuleInfo holds the fields of a rule-set rule the helper inspects
+type headlessRuleInfo struct {
+	Type          string   `json:"type"`
+	Domain        listable `json:"domain"`
+	DomainSuffix  listable `json:"domain_suffix"`
+	DomainKeyword listable `json:"domain_keyword"`
+	IPCIDR        listable `json:"ip_cidr"`
+	Invert        bool     `json:"invert"`
+	Keys          []string `json:"-"` // Keys of every field set in the rule, including the ones not inspected
+}
+
+// UnmarshalJSON decodes the inspected fields and records the keys of the rule
+func (r *headlessRuleInfo) UnmarshalJSON(content []byte) error {
+	type plain headlessRuleInfo
+	if err := json.Unmarshal(content, (*plain)(r)); err != nil {
+		return err
+	}
+	keys, err := ruleKeys(content)
+	r.Keys = keys
+	return err
+}
+
+// inspectRuleSets decodes the rule-sets of the given options
+func inspectRuleSets(options *option.Options) []ruleSetInfo {
+	if options == nil {
+		return nil
+	}
+
+	content, err := json.Marshal(options)
+	if err != nil {
+		return nil
+	}
+
+	var config struct {
+		Route struct {
+			RuleSet []ruleSetInfo `json:"rule_set"`
+		} `json:"route"`
+	}
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil
+	}
+	return config.Route.RuleSet
+}
+
+// routeRuleInfo holds the route rule fields the helper inspects
+type routeRuleInfo struct {
+	Type          string   `json:"type"`
+	Domain        listable `json:"domain"`
+	DomainSuffix  listable `json:"domain_suffix"`
+	DomainKeyword listable `json:"domain_keyword"`
+	IPCIDR        listable `json:"ip_cidr"`
+	RuleSet       listable `json:"rule_set"`
+	Inbound       listable `json:"inbound"`
+	ClashMode     string   `json:"clash_mode"`
+	Outbound      string   `json:"outbound"`
+	Keys          []string `json:"-"` // Keys of every field set in the rule, including the ones not inspected
+}
+
+// UnmarshalJSON decodes the inspected fields and records the keys of the rule
+func (r *routeRuleInfo) UnmarshalJSON(content []byte) error {
+	type plain routeRuleInfo
+	if err := json.Unmarshal(content, (*plain)(r)); err != nil {
+		return err
+	}
+	keys, err := ruleKeys(content)
+	r.Keys = keys
+	return err
+}
+
+// ruleKeys returns the keys of a rule object
+func ruleKeys(content []byte) ([]string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// routeInfo holds the route fields the helper inspects
+type routeInfo struct {
+	Rules            []routeRuleInfo `json:"rules"`
+	RuleSet          []ruleSetInfo   `json:"rule_set"`
+	Final            string          `json:"final"`
+	DefaultInterface string          `json:"default_interface"`
+}
+
+// outboundInfo holds the outbound fields the helper inspects
+type outboundInfo struct {
+	Type string `json:"type"`
+	Tag  string `json:"tag"`
+}
+
+// inspectRoute decodes the route and outbounds of the given options
+func inspectRoute(options *option.Options) (routeInfo, []outboundInfo) {
+	if options == nil {
+		return routeInfo{}, nil
+	}
+
+	content, err := json.Marshal(options)
+	if err != nil {
+		return routeInfo{}, nil
+	}
+
+	var config struct {
+		Route     routeInfo      `json:"route"`
+		Outbounds []outboundInfo `json:"outbounds"`
+	}
+	if err := json.Unmarshal(content, &config); err != nil {
+		return routeInfo{}, nil
+	}
+	return config.Route, config.Outbounds
+}
+
+// tunPrefixes returns the address prefixes assigned to the TUN inbounds of the given options
+func tunPrefixes(options *option.Options) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, inbound := range inspectInbounds(options) {
+		if inbound.Type != "tun" {
+			continue
+		}
+		for _, list := range []listable{inbound.Address, inbound.Inet4Address, inbound.Inet6Address} {
+			for _, address := range list {
+				if prefix, err := netip.ParsePrefix(strings.TrimSpace(address)); err == nil {
+					prefixes = append(prefixes, prefix.Masked())
+				}
+			}
+		}
+	}
+	return prefixes
+}
@@ -0,0 +1,314 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	option "github.com/sagernet/sing-box/option"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// applyConfigOverrides rewrites the sing-box config with the settings the user changed through the API or flags,
+// so they survive both restarts of the helper and a config file rewritten by the client
+func (s *Server) applyConfigOverrides(content []byte) ([]byte, error) {
+	state, err := s.loadState()
+	if err != nil {
+		s.logger.warn.Printf("Config overrides skipped: %v", err)
+		return content, nil
+	}
+	bindInterface := state.BindInterface
+	if bindInterface == "" {
+		bindInterface = s.flags.BindInterface
+	}
+	if rebound := s.reboundInterface(); rebound != "" {
+		bindInterface = rebound
+	}
+	var config map[string]any
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, err
+	}
+
+	inbounds, _ := config["inbounds"].([]any)
+	for _, item := range inbounds {
+		inbound, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		tag, _ := inbound["tag"].(string)
+		if port, ok := state.InboundPorts[tag]; ok {
+			inbound["listen_port"] = port
+		}
+	}
+
+	// The default interface applies to every outbound without its own bind_interface,
+	// and cannot be combined with automatic interface detection
+	if bindInterface != "" {
+		route, _ := config["route"].(map[string]any)
+		if route == nil {
+			route = make(map[string]any)
+			config["route"] = route
+		}
+		route["default_interface"] = bindInterface
+		delete(route, "auto_detect_interface")
+	}
+
+	if state.CacheFile != nil {
+		setCacheFile(config, *state.CacheFile)
+	}
+
+	if len(state.DNSServers) > 0 {
+		setDNSServers(config, state.DNSServers)
+	}
+
+	if pool := state.EndpointPool; pool != nil && pool.currentEndpoint() != "" {
+		setEndpoint(config, pool.Outbound, pool.currentEndpoint())
+	}
+
+	if state.RoutingPreset != nil {
+		s.applyRoutingPreset(config, *state.RoutingPreset)
+	}
+	if len(state.SplitTunnel) > 0 {
+		addSplitTunnelRules(config, state.SplitTunnel)
+	}
+	addPauseRule(config)
+	// Applied last so the DNS rule stays ahead of the preset rules
+	if state.DNSHijack != nil {
+		setDNSHijack(config, *state.DNSHijack)
+	}
+	return json.Marshal(config)
+}
+
+// dnsHijackTag is the tag of the DNS outbound added when hijacking is enabled on a config without one
+const dnsHijackTag = "dns-out"
+
+// setDNSHijack adds or removes the route rule that sends DNS queries to a dns outbound, so the
+// tunnel's resolver answers them. Removing it lets local resolvers (corporate DNS, Pi-hole) work.
+func setDNSHijack(config map[string]any, enabled bool) {
+	outbounds, _ := config["outbounds"].([]any)
+	dnsTags := make(map[string]bool)
+	var dnsTag string // First dns outbound, used for the added rule
+	for _, item := range outbounds {
+		if outbound, ok := item.(map[string]any); ok && outbound["type"] == "dns" {
+			tag, _ := outbound["tag"].(string)
+			dnsTags[tag] = true
+			if dnsTag == "" {
+				dnsTag = tag
+			}
+		}
+	}
+
+	route, _ := config["route"].(map[string]any)
+	if route == nil {
+		route = make(map[string]any)
+		config["route"] = route
+	}
+	rules, _ := route["rules"].([]any)
+
+	var kept []any
+	for _, item := range rules {
+		rule, ok := item.(map[string]any)
+		if ok {
+			if outbound, _ := rule["outbound"].(string); dnsTags[outbound] {
+				if enabled {
+					return // Already hijacked by the config
+				}
+				continue
+			}
+		}
+		kept = append(kept, item)
+	}
+
+	if !enabled {
+		route["rules"] = kept
+		return
+	}
+
+	if len(dnsTags) == 0 {
+		config["outbounds"] = append(outbounds, map[string]any{"type": "dns", "tag": dnsHijackTag})
+		dnsTag = dnsHijackTag
+	}
+	hijack := map[string]any{
+		"type":     "logical",
+		"mode":     "or",
+		"rules":    []any{map[string]any{"protocol": "dns"}, map[string]any{"port": 53}},
+		"outbound": dnsTag,
+	}
+	route["rules"] = append([]any{hijack}, rules...)
+}
+
+// applyStateOverride persists an override of the config made by change, checks that the overridden config still
+// builds and applies it by reloading sing-box if it is running. If the check or the reload fails, the function
+// returned by change restores the previous value, so a failing override is not reapplied on every start.
+// The overridden options are returned, or nil if there is no config yet.
+func (s *Server) applyStateOverride(change func(state *HelperState) (restore func(state *HelperState))) (*option.Options, error) {
+	var restore func(state *HelperState)
+	if err := s.updateState(func(state *HelperState) { restore = change(state) }); err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	options, err := s.loadSingBoxConfig()
+	switch {
+	case status.Code(err) == codes.NotFound:
+		options, err = nil, nil // Checked once a config is written
+	case err == nil:
+		if component, checkErr := checkInstance(options); checkErr != nil {
+			err = status.Errorf(codes.InvalidArgument, "the override breaks %s: %v", component, checkErr)
+		}
+	}
+	if err == nil {
+		err = s.restartIfRunning()
+	}
+	if err != nil {
+		if restoreErr := s.updateState(restore); restoreErr != nil {
+			s.logger.error.Printf("Failed to restore the previous override: %v", restoreErr)
+		}
+		return nil, err
+	}
+	return options, nil
+}
+
+// restartIfRunning applies a changed override by reloading sing-box if it is running
+func (s *Server) restartIfRunning() error {
+	s.mu.RLock()
+	running := s.core.Running()
+	s.mu.RUnlock()
+	if !running {
+		return nil
+	}
+	return s.reloadSingBox(false, "reloading")
+}
+
+// portAvailable reports whether a TCP listener can be opened on the given address and port
+func portAvailable(listen string, port int) error {
+	listener, err := net.Listen("tcp", net.JoinHostPort(listen, strconv.Itoa(port)))
+	if err != nil {
+		return err
+	}
+	return listener.Close()
+}
+
+// SetInboundPort handles the gRPC SetInboundPort request, moving a proxy inbound of the config to another port.
+// The port is persisted as an override and applied by restarting sing-box if it is running; if the restart fails,
+// the previous port is kept.
+func (s *Server) SetInboundPort(ctx context.Context, req *pb.SetInboundPortRequest) (*pb.SetInboundPortResponse, error) {
+	if req.Port == 0 || req.Port > 65535 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid port %d", req.Port)
+	}
+	port := int(req.Port)
+
+	options, err := s.loadSingBoxConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var target *inboundInfo
+	for _, inbound := range inspectInbounds(options) {
+		if inbound.Tag == req.Inbound {
+			target = &inbound
+			break
+		}
+	}
+	if target == nil {
+		return nil, status.Errorf(codes.NotFound, "inbound %q not found in the config", req.Inbound)
+	}
+	if target.Type != "mixed" && target.Type != "socks" && target.Type != "http" {
+		return nil, status.Errorf(codes.InvalidArgument, "the port of %s inbounds cannot be changed", target.Type)
+	}
+
+	if port != target.ListenPort {
+		if err := portAvailable(target.Listen, port); err != nil {
+			return nil, status.Errorf(codes.AlreadyExists, "port %d is not available: %v", port, err)
+		}
+	}
+
+	_, err = s.applyStateOverride(func(state *HelperState) func(*HelperState) {
+		previous, overridden := state.InboundPorts[req.Inbound]
+		if state.InboundPorts == nil {
+			state.InboundPorts = make(map[string]int)
+		}
+		state.InboundPorts[req.Inbound] = port
+		return func(state *HelperState) {
+			delete(state.InboundPorts, req.Inbound)
+			if overridden {
+				if state.InboundPorts == nil {
+					state.InboundPorts = make(map[string]int)
+				}
+				state.InboundPorts[req.Inbound] = previous
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.logger.info.Printf("Inbound %s moved to port %d", req.Inbound, port)
+
+	return &pb.SetInboundPortResponse{Message: fmt.Sprintf("Inbound %s listens on port %d", req.Inbound, port)}, nil
+}
+
+// SetBindInterface handles the gRPC SetBindInterface request, binding outbound connections to a network interface.
+// This keeps the tunnel on the chosen interface on multi-homed machines or when another VPN owns the default route.
+// An empty name removes the override. The interface is persisted and applied by restarting sing-box if it is running;
+// if the restart fails, the previous binding is kept.
+func (s *Server) SetBindInterface(ctx context.Context, req *pb.SetBindInterfaceRequest) (*pb.SetBindInterfaceResponse, error) {
+	if req.Interface != "" {
+		if _, err := net.InterfaceByName(req.Interface); err != nil {
+			return nil, status.Errorf(codes.NotFound, "interface %q not found: %v", req.Interface, err)
+		}
+	}
+
+	_, err := s.applyStateOverride(func(state *HelperState) func(*HelperState) {
+		previous := state.BindInterface
+		state.BindInterface = req.Interface
+		return func(state *HelperState) { state.BindInterface = previous }
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.setRebind("", "")
+
+	if req.Interface == "" {
+		s.logger.info.Println("Outbound interface binding removed")
+	} else {
+		s.logger.info.Printf("Outbound connections bound to %s", req.Interface)
+	}
+	return &pb.SetBindInterfaceResponse{Interface: req.Interface}, nil
+}
+
+// SetDNSHijack handles the gRPC SetDNSHijack request, turning the interception of DNS queries into the tunnel's
+// resolver on or off. The choice overrides the config's rules, is persisted and is applied by restarting sing-box if it is running;
+// if the restart fails, the previous choice is kept.
+func (s *Server) SetDNSHijack(ctx context.Context, req *pb.SetDNSHijackRequest) (*pb.SetDNSHijackResponse, error) {
+	enabled := req.Enabled
+	_, err := s.applyStateOverride(func(state *HelperState) func(*HelperState) {
+		previous := state.DNSHijack
+		state.DNSHijack = &enabled
+		return func(state *HelperState) { state.DNSHijack = previous }
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.logger.info.Printf("DNS hijack enabled: %v", enabled)
+	return &pb.SetDNSHijackResponse{Enabled: enabled}, nil
+}
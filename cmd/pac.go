@@ -0,0 +1,407 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	option "github.com/sagernet/sing-box/option"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PAC server settings
+const (
+	defaultPACPort = 8090         // Port used when the client does not specify one
+	pacPath        = "/proxy.pac" // URL path of the PAC file
+)
+
+// privateNetworks are always reached directly by the PAC file
+var privateNetworks = []string{"10.0.0.0/8", "127.0.0.0/8", "169.254.0.0/16", "172.16.0.0/12", "192.168.0.0/16"}
+
+// pacTemplate is the PAC script. It receives the proxy directive, the JSON encoded rules evaluated in order,
+// each with its domains, domain suffixes, domain keywords and IPv4 networks, and whether unmatched hosts go direct.
+const pacTemplate = `var proxy = %s;
+var rules = %s;
+var finalDirect = %t;
+
+function matches(rule, host) {
+  for (var i = 0; i < rule.domains.length; i++) {
+    if (host === rule.domains[i]) {
+      return true;
+    }
+  }
+  for (var i = 0; i < rule.suffixes.length; i++) {
+    if (host === rule.suffixes[i] || dnsDomainIs(host, "." + rule.suffixes[i])) {
+      return true;
+    }
+  }
+  for (var i = 0; i < rule.keywords.length; i++) {
+    if (host.indexOf(rule.keywords[i]) >= 0) {
+      return true;
+    }
+  }
+  if (/^\d+\.\d+\.\d+\.\d+$/.test(host)) {
+    for (var i = 0; i < rule.networks.length; i++) {
+      if (isInNet(host, rule.networks[i][0], rule.networks[i][1])) {
+        return true;
+      }
+    }
+  }
+  return false;
+}
+
+function FindProxyForURL(url, host) {
+  if (isPlainHostName(host) || host === "localhost") {
+    return "DIRECT";
+  }
+  if (host.indexOf(":") >= 0) {
+    return proxy; // IPv6 networks cannot be checked, the route rules apply in the proxy
+  }
+  for (var i = 0; i < rules.length; i++) {
+    if (matches(rules[i], host)) {
+      return rules[i].direct ? "DIRECT" : proxy;
+    }
+  }
+  return finalDirect ? "DIRECT" : proxy;
+}
+`
+
+// pacRuleKeys are the route rule keys a PAC file can evaluate from the host of a request
+var pacRuleKeys = map[string]bool{
+	"type": true, "outbound": true, "inbound": true, "rule_set": true,
+	"domain": true, "domain_suffix": true, "domain_keyword": true, "ip_cidr": true,
+}
+
+// pacRule is a route rule as evaluated by the PAC file
+type pacRule struct {
+	Direct   bool        `json:"direct"`
+	Domains  []string    `json:"domains"`
+	Suffixes []string    `json:"suffixes"`
+	Keywords []string    `json:"keywords"`
+	Networks [][2]string `json:"networks"`
+}
+
+// newPACRule returns a PAC rule without destinations; the lists are never null in the script
+func newPACRule(direct bool) pacRule {
+	return pacRule{Direct: direct, Domains: []string{}, Suffixes: []string{}, Keywords: []string{}, Networks: [][2]string{}}
+}
+
+// add appends the destinations of a rule to the PAC rule. PAC engines only support IPv4 networks,
+// given as an address and a mask.
+func (p *pacRule) add(match headlessRuleInfo) {
+	p.Domains = append(p.Domains, match.Domain...)
+	for _, suffix := range match.DomainSuffix {
+		p.Suffixes = append(p.Suffixes, strings.TrimPrefix(suffix, "."))
+	}
+	p.Keywords = append(p.Keywords, match.DomainKeyword...)
+	for _, cidr := range match.IPCIDR {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil || !prefix.Addr().Is4() {
+			continue
+		}
+		mask := net.IP(net.CIDRMask(prefix.Bits(), 32)).String()
+		p.Networks = append(p.Networks, [2]string{prefix.Masked().Addr().String(), mask})
+	}
+}
+
+// empty reports whether the PAC rule matches no destination
+func (p *pacRule) empty() bool {
+	return len(p.Domains)+len(p.Suffixes)+len(p.Keywords)+len(p.Networks) == 0
+}
+
+// exactRule reports whether a rule only has conditions the PAC file can evaluate
+func exactRule(keys []string) bool {
+	for _, key := range keys {
+		if !pacRuleKeys[key] {
+			return false
+		}
+	}
+	return true
+}
+
+// PACSettings configures the proxy auto-config server
+type PACSettings struct {
+	Enabled        bool `json:"enabled"`
+	Port           int  `json:"port"`
+	SetSystemProxy bool `json:"set_system_proxy"` // Register the PAC URL as the system auto-proxy
+}
+
+// url returns the address the PAC file is served at
+func (p PACSettings) url() string {
+	return fmt.Sprintf("http://127.0.0.1:%d%s", p.Port, pacPath)
+}
+
+// pacServer serves the PAC file while sing-box is running
+type pacServer struct {
+	server     *http.Server
+	registered bool // Whether the PAC URL was set as the system auto-proxy
+}
+
+// startPACServer serves the PAC file and registers it as the system auto-proxy if enabled; the caller must hold s.mu
+func (s *Server) startPACServer() error {
+	if !s.pacSettings.Enabled || s.pac != nil {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(s.pacSettings.Port)))
+	if err != nil {
+		return fmt.Errorf("failed to listen for the PAC server: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(pacPath, s.servePAC)
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	s.pac = &pacServer{server: server}
+	s.cleanup.Go(func() { server.Serve(listener) })
+
+	if s.pacSettings.SetSystemProxy {
+		s.writeJournal(JournalEntry{Time: time.Now(), State: "started", Changes: []string{changeAutoProxy}})
+		if err := setAutoProxy(s.pacSettings.url()); err != nil {
+			s.logger.error.Printf("Failed to set the system auto-proxy: %v", err)
+		} else {
+			s.pac.registered = true
+		}
+	}
+	s.logger.info.Printf("Serving PAC file at %s", s.pacSettings.url())
+	return nil
+}
+
+// stopPACServer stops serving the PAC file and removes the system auto-proxy; the caller must hold s.mu
+func (s *Server) stopPACServer() {
+	if s.pac == nil {
+		return
+	}
+
+	if s.pac.registered {
+		if err := clearAutoProxy(); err != nil {
+			s.logger.error.Printf("Failed to clear the system auto-proxy: %v", err)
+		}
+	}
+	// Close does not wait for handlers, which may be blocked on s.mu
+	if err := s.pac.server.Close(); err != nil {
+		s.logger.warn.Printf("Failed to close the PAC server: %v", err)
+	}
+	s.pac = nil
+}
+
+// servePAC writes the PAC file generated from the running config
+func (s *Server) servePAC(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	options := s.core.Options()
+	s.mu.RUnlock()
+
+	script, err := generatePAC(options)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ns-proxy-autoconfig")
+	w.Write([]byte(script))
+}
+
+// generatePAC builds a PAC file that sends traffic to the first proxy inbound of the given options, except for private
+// networks and the destinations the route sends to a direct outbound. The route rules are evaluated in order and the
+// first match wins, with route.final for the unmatched hosts, as sing-box does. Only destinations known to go direct
+// bypass the proxy: a direct rule with conditions the PAC file cannot check, such as a port, a network or a process,
+// is skipped, while such conditions are ignored on the other rules, which then match more hosts than in sing-box.
+// Once a non-direct rule cannot be expressed at all, such as one using a binary rule-set, every remaining host goes
+// through the proxy, where the route rules still apply. Rules depending on the clash mode are skipped.
+func generatePAC(options *option.Options) (string, error) {
+	if options == nil {
+		return "", fmt.Errorf("sing-box is not running")
+	}
+
+	proxy, inbound := pacProxy(inspectInbounds(options))
+	if proxy == "" {
+		return "", fmt.Errorf("the config has no mixed, http or socks inbound")
+	}
+
+	route, outbounds := inspectRoute(options)
+	direct := make(map[string]bool)
+	for _, outbound := range outbounds {
+		if outbound.Type == "direct" {
+			direct[outbound.Tag] = true
+		}
+	}
+	final := route.Final
+	if final == "" && len(outbounds) > 0 {
+		final = outbounds[0].Tag
+	}
+
+	ruleSets := make(map[string]ruleSetInfo)
+	for _, ruleSet := range route.RuleSet {
+		ruleSets[ruleSet.Tag] = ruleSet
+	}
+
+	private := newPACRule(true)
+	private.add(headlessRuleInfo{IPCIDR: privateNetworks})
+	rules := []pacRule{private}
+	finalDirect := direct[final]
+	for _, rule := range route.Rules {
+		if rule.ClashMode != "" {
+			continue // The mode may change after the PAC file was fetched
+		}
+		if len(rule.Inbound) > 0 && !slices.Contains(rule.Inbound, inbound) {
+			continue // Never matches the requests sent to the proxy
+		}
+
+		// complete is cleared when part of the destinations of the rule cannot be listed
+		match, complete := newPACRule(direct[rule.Outbound]), true
+		match.add(headlessRuleInfo{Domain: rule.Domain, DomainSuffix: rule.DomainSuffix, DomainKeyword: rule.DomainKeyword, IPCIDR: rule.IPCIDR})
+		for _, tag := range rule.RuleSet {
+			ruleSetMatches, found := ruleSetRules(ruleSets[tag])
+			complete = complete && found
+			for _, ruleSetMatch := range ruleSetMatches {
+				if exactRule(ruleSetMatch.Keys) {
+					match.add(ruleSetMatch)
+				} else {
+					complete = false
+				}
+			}
+		}
+
+		if match.Direct {
+			if exactRule(rule.Keys) && !match.empty() {
+				rules = append(rules, match)
+			}
+			continue
+		}
+		if !complete || (match.empty() && len(rule.RuleSet) == 0) || rule.Type == "logical" || slices.Contains(rule.Keys, "invert") {
+			finalDirect = false
+			break
+		}
+		if !match.empty() {
+			rules = append(rules, match)
+		}
+	}
+
+	values := make([]any, 0, 3)
+	for _, value := range []any{proxy, rules} {
+		content, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		values = append(values, string(content))
+	}
+	return fmt.Sprintf(pacTemplate, append(values, finalDirect)...), nil
+}
+
+// ruleSetRules returns the rules of an inline or source-format local rule-set, including the bundled snapshots
+// used in place of missing files, and whether they could be read. Binary rule-sets are not expanded: the full lists
+// would make the PAC file too large for browsers to evaluate on every request.
+func ruleSetRules(ruleSet ruleSetInfo) ([]headlessRuleInfo, bool) {
+	switch {
+	case ruleSet.Type == "inline", ruleSet.Type == "": // sing-box omits the default inline type
+		return ruleSet.Rules, true
+	case ruleSet.Type != "local", ruleSet.Format != "source" && (ruleSet.Format != "" || filepath.Ext(ruleSet.Path) != ".json"):
+		return nil, false
+	}
+
+	content, err := os.ReadFile(ruleSet.Path)
+	if err != nil {
+		return nil, false
+	}
+	var source struct {
+		Rules []headlessRuleInfo `json:"rules"`
+	}
+	if err := json.Unmarshal(content, &source); err != nil {
+		return nil, false
+	}
+	return source.Rules, true
+}
+
+// pacProxy returns the PAC directive for the first mixed, http or socks inbound, and the tag of that inbound
+func pacProxy(inbounds []inboundInfo) (string, string) {
+	for _, inbound := range inbounds {
+		address := inbound.localAddress()
+		switch inbound.Type {
+		case "mixed":
+			return fmt.Sprintf("PROXY %s; SOCKS5 %s", address, address), inbound.Tag
+		case "http":
+			return "PROXY " + address, inbound.Tag
+		case "socks":
+			return "SOCKS5 " + address, inbound.Tag
+		}
+	}
+	return "", ""
+}
+
+// loadPACSettings applies the persisted PAC server settings
+func (s *Server) loadPACSettings() {
+	state, err := s.loadState()
+	if err != nil {
+		s.logger.error.Printf("Failed to load PAC server settings: %v", err)
+		return
+	}
+	if state.PAC != nil {
+		s.mu.Lock()
+		s.pacSettings = *state.PAC
+		s.mu.Unlock()
+	}
+}
+
+// SetPACServer handles the gRPC SetPACServer request, turning the PAC file server on or off.
+// The PAC file is served while sing-box is running.
+func (s *Server) SetPACServer(ctx context.Context, req *pb.SetPACServerRequest) (*pb.PACServerResponse, error) {
+	settings := PACSettings{Enabled: req.Enabled, Port: int(req.Port), SetSystemProxy: req.SetSystemProxy}
+	if settings.Port == 0 {
+		settings.Port = defaultPACPort
+	}
+	if settings.Port > 65535 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid port %d", settings.Port)
+	}
+
+	err := s.updateState(func(state *HelperState) {
+		state.PAC = &settings
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stopPACServer()
+	s.pacSettings = settings
+	if s.core.Running() {
+		if err := s.startPACServer(); err != nil {
+			return nil, status.Errorf(codes.Unavailable, "%v", err)
+		}
+	}
+
+	s.logger.info.Printf("PAC server enabled: %v (port %d, system proxy %v)", settings.Enabled, settings.Port, settings.SetSystemProxy)
+	return &pb.PACServerResponse{
+		Enabled:        settings.Enabled,
+		Port:           uint32(settings.Port),
+		SetSystemProxy: settings.SetSystemProxy,
+		Url:            settings.url(),
+	}, nil
+}
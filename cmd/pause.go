@@ -0,0 +1,195 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"strings"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	box "github.com/sagernet/sing-box"
+	"github.com/sagernet/sing-box/experimental/clashapi"
+	option "github.com/sagernet/sing-box/option"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Pausing switches the clash API to a mode whose only rule sends every connection to a direct outbound.
+// The rule is only added to the instance started by Pause, so any later start runs without it
+// and cannot come up paused; the instance and its TUN device stay up and resuming takes effect immediately.
+const (
+	pausedMode       = "Paused"          // Clash mode routing everything directly
+	pauseOutboundTag = "oblivion-direct" // Direct outbound added to configs without one
+)
+
+// pauseState remembers the instance that was paused and the clash mode it had before
+type pauseState struct {
+	instance *box.Box // Paused instance, nil when not paused
+	mode     string   // Clash mode restored by Resume
+}
+
+// paused reports whether the running instance is paused; the caller must hold s.mu
+func (s *Server) paused() bool {
+	return s.pause.instance != nil && s.pause.instance == s.core.Instance()
+}
+
+// hasPausedMode reports whether the instance has the route rule of the paused mode
+func hasPausedMode(server *clashapi.Server) bool {
+	for _, mode := range server.ModeList() {
+		if strings.EqualFold(mode, pausedMode) {
+			return true
+		}
+	}
+	return false
+}
+
+// setPausedMode switches to the paused mode. Sing-box saves the mode to the cache file, from where the next
+// start would restore it, so the mode it had before is written back. The caller must hold s.mu.
+func (s *Server) setPausedMode(server *clashapi.Server, previous string) {
+	server.SetMode(pausedMode)
+	if cacheFile := s.core.CacheFile(); cacheFile != nil {
+		if err := cacheFile.StoreMode(previous); err != nil {
+			s.logger.error.Printf("Failed to keep the paused mode out of the cache file: %v", err)
+		}
+	}
+}
+
+// resetPausedMode switches a new instance back to the default clash mode should it start in the paused mode,
+// e.g. restored from a cache file written by an older version; the caller must hold s.mu
+func (s *Server) resetPausedMode(options *option.Options) {
+	server, err := s.clashServer()
+	if err != nil || !strings.EqualFold(server.Mode(), pausedMode) {
+		return
+	}
+	mode := "Rule"
+	if clashAPI := options.Experimental.ClashAPI; clashAPI != nil && clashAPI.DefaultMode != "" && !strings.EqualFold(clashAPI.DefaultMode, pausedMode) {
+		mode = clashAPI.DefaultMode
+	}
+	server.SetMode(mode)
+	s.logger.warn.Printf("Started in the paused clash mode, switched to %s", mode)
+}
+
+// addPauseRule adds the route rule of the paused clash mode after the leading DNS rules of a config,
+// so name resolution keeps going through the tunnel's DNS while other traffic bypasses it. The rule
+// only matches in the paused mode, which instances never start in.
+func addPauseRule(config map[string]any) {
+	insertRouteRules(config, map[string]any{"clash_mode": pausedMode, "outbound": directOutbound(config)})
+}
+
+// directOutbound returns the tag of the first direct outbound of a config, adding one if there is none
+func directOutbound(config map[string]any) string {
+	outbounds, _ := config["outbounds"].([]any)
+	for _, item := range outbounds {
+		if outbound, ok := item.(map[string]any); ok && outbound["type"] == "direct" {
+			tag, _ := outbound["tag"].(string)
+			return tag
+		}
+	}
+	config["outbounds"] = append(outbounds, map[string]any{"type": "direct", "tag": pauseOutboundTag})
+	return pauseOutboundTag
+}
+
+// insertRouteRules inserts route rules after the leading rules of a config that send DNS queries to a dns outbound
+func insertRouteRules(config map[string]any, added ...any) {
+	outbounds, _ := config["outbounds"].([]any)
+	dnsTags := make(map[string]bool)
+	for _, item := range outbounds {
+		if outbound, ok := item.(map[string]any); ok && outbound["type"] == "dns" {
+			tag, _ := outbound["tag"].(string)
+			dnsTags[tag] = true
+		}
+	}
+
+	route, _ := config["route"].(map[string]any)
+	if route == nil {
+		route = make(map[string]any)
+		config["route"] = route
+	}
+	rules, _ := route["rules"].([]any)
+	position := 0
+	for position < len(rules) {
+		rule, _ := rules[position].(map[string]any)
+		if tag, _ := rule["outbound"].(string); !dnsTags[tag] {
+			break
+		}
+		position++
+	}
+	route["rules"] = append(rules[:position:position], append(added, rules[position:]...)...)
+}
+
+// Pause handles the gRPC Pause request, sending all traffic directly while sing-box and the TUN device keep running.
+// Every instance is started with the route rule of the paused mode, so pausing only switches the clash mode.
+// Open connections are closed so they reconnect without the tunnel. A later reload or restart resumes.
+func (s *Server) Pause(ctx context.Context, req *pb.PauseRequest) (*pb.PauseResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	server, err := s.clashServer()
+	if err != nil || s.paused() {
+		return &pb.PauseResponse{}, err
+	}
+	if !hasPausedMode(server) {
+		return nil, status.Errorf(codes.FailedPrecondition, "the running config has no route rule for the paused mode")
+	}
+
+	s.pause = pauseState{instance: s.core.Instance(), mode: server.Mode()}
+	s.setPausedMode(server, s.pause.mode)
+	for _, connection := range server.TrafficManager().Snapshot().Connections {
+		connection.Close()
+	}
+
+	s.broadcastStatus("paused")
+	s.logger.info.Println("Tunnel paused, routing directly")
+	return &pb.PauseResponse{}, nil
+}
+
+// Resume handles the gRPC Resume request, routing traffic through the tunnel again after Pause.
+// Direct connections opened while paused are closed so they reconnect through the tunnel.
+func (s *Server) Resume(ctx context.Context, req *pb.ResumeRequest) (*pb.ResumeResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	server, err := s.clashServer()
+	if err != nil {
+		return nil, err
+	}
+	if !s.paused() {
+		return &pb.ResumeResponse{}, nil
+	}
+
+	server.SetMode(s.pause.mode)
+	s.pause = pauseState{}
+	for _, connection := range server.TrafficManager().Snapshot().Connections {
+		connection.Close()
+	}
+
+	s.broadcastStatus("started")
+	s.logger.info.Println("Tunnel resumed")
+	return &pb.ResumeResponse{}, nil
+}
+
+// clashModes returns the clash modes a client can choose, without the one used by Pause
+func clashModes(modes []string) []string {
+	var selectable []string
+	for _, mode := range modes {
+		if !strings.EqualFold(mode, pausedMode) {
+			selectable = append(selectable, mode)
+		}
+	}
+	return selectable
+}
@@ -0,0 +1,113 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// allowedClients are the executables allowed to connect to the gRPC server, set by -allow-client
+type allowedClients []string
+
+// String implements flag.Value
+func (a *allowedClients) String() string {
+	return strings.Join(*a, ",")
+}
+
+// Set implements flag.Value
+func (a *allowedClients) Set(value string) error {
+	if value == "" {
+		return fmt.Errorf("missing executable path")
+	}
+	path, err := filepath.Abs(value)
+	if err != nil {
+		return err
+	}
+	// Compare against the real path, as reported for the peer process
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	*a = append(*a, path)
+	return nil
+}
+
+// allows reports whether the executable at path is one of the allowed clients
+func (a allowedClients) allows(path string) bool {
+	for _, allowed := range a {
+		if allowed == path || runtime.GOOS == "windows" && strings.EqualFold(allowed, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// peerVerifyingListener closes incoming connections whose peer process is not an allowed client
+type peerVerifyingListener struct {
+	net.Listener
+	allowed allowedClients
+	logger  *Logger
+}
+
+// verifyPeers wraps the listener so only the allowed executables can connect. The peer process is only known
+// for unix sockets and named pipes, so TCP listeners are rejected.
+func verifyPeers(listener net.Listener, allowed allowedClients, logger *Logger) (net.Listener, error) {
+	if _, isTCP := listener.Addr().(*net.TCPAddr); isTCP {
+		return nil, fmt.Errorf("-allow-client requires -listen with a unix socket or named pipe")
+	}
+	return &peerVerifyingListener{Listener: listener, allowed: allowed, logger: logger}, nil
+}
+
+// Accept implements net.Listener, skipping connections from other processes
+func (l *peerVerifyingListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		if err := l.verify(conn); err != nil {
+			l.logger.warn.Printf("Rejected control connection: %v", err)
+			conn.Close()
+			continue
+		}
+		return conn, nil
+	}
+}
+
+// verify checks that the process on the other end of conn runs an allowed executable
+func (l *peerVerifyingListener) verify(conn net.Conn) error {
+	pid, err := peerPID(conn)
+	if err != nil {
+		return fmt.Errorf("failed to identify the peer process: %w", err)
+	}
+	peer, err := process.NewProcess(pid)
+	if err != nil {
+		return fmt.Errorf("peer process %d: %w", pid, err)
+	}
+	exe, err := peer.Exe()
+	if err != nil {
+		return fmt.Errorf("failed to get the executable of process %d: %w", pid, err)
+	}
+	if !l.allowed.allows(exe) {
+		return fmt.Errorf("process %d runs %s, which is not an allowed client", pid, exe)
+	}
+	return nil
+}
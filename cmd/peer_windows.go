@@ -0,0 +1,41 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"net"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var procGetNamedPipeClientProcessId = windows.NewLazySystemDLL("kernel32.dll").NewProc("GetNamedPipeClientProcessId")
+
+// peerPID returns the process ID of the client of a named pipe connection, using GetNamedPipeClientProcessId
+func peerPID(conn net.Conn) (int32, error) {
+	// Connections of go-winio pipe listeners expose their handle
+	pipe, ok := conn.(interface{ Fd() uintptr })
+	if !ok {
+		return 0, errors.New("not a named pipe connection")
+	}
+
+	var pid uint32
+	if ret, _, err := procGetNamedPipeClientProcessId.Call(pipe.Fd(), uintptr(unsafe.Pointer(&pid))); ret == 0 {
+		return 0, err
+	}
+	return int32(pid), nil
+}
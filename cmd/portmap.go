@@ -0,0 +1,425 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	"github.com/huin/goupnp/dcps/internetgateway2"
+	"github.com/jackpal/gateway"
+	natpmp "github.com/jackpal/go-nat-pmp"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Port mapping settings
+const (
+	portMappingLease       = time.Hour        // Lifetime requested from the router, so stale mappings expire after a crash
+	portMappingRenewal     = 30 * time.Minute // How often mappings are renewed while sing-box runs
+	portMappingDescription = "Oblivion"
+	natPMPTimeout          = 2 * time.Second
+)
+
+// PortMappingSettings is the opt-in for forwarding listening inbounds on the router
+type PortMappingSettings struct {
+	Enabled bool `json:"enabled"`
+}
+
+// portMapping is a port forwarded on the router
+type portMapping struct {
+	inbound         string
+	protocol        string // tcp or udp
+	internalPort    int
+	externalPort    int
+	externalAddress string
+}
+
+// portMapper forwards ports through a router
+type portMapper interface {
+	method() string                                               // Protocol spoken with the router
+	add(protocol string, port int) (externalPort int, err error)  // Adds or renews a mapping
+	remove(protocol string, internalPort, externalPort int) error // Deletes a mapping
+	externalAddress() (string, error)                             // Public address of the router
+}
+
+// upnpClient is implemented by the UPnP WAN connection services
+type upnpClient interface {
+	AddPortMapping(remoteHost string, externalPort uint16, protocol string, internalPort uint16, internalClient string, enabled bool, description string, lease uint32) error
+	DeletePortMapping(remoteHost string, externalPort uint16, protocol string) error
+	GetExternalIPAddress() (string, error)
+}
+
+// upnpMapper forwards ports through a UPnP Internet Gateway Device
+type upnpMapper struct {
+	client    upnpClient
+	localAddr string // Address of this machine on the gateway's network
+}
+
+func (m *upnpMapper) method() string {
+	return "upnp"
+}
+
+func (m *upnpMapper) add(protocol string, port int) (int, error) {
+	err := m.client.AddPortMapping("", uint16(port), strings.ToUpper(protocol), uint16(port), m.localAddr, true, portMappingDescription, uint32(portMappingLease.Seconds()))
+	return port, err
+}
+
+func (m *upnpMapper) remove(protocol string, internalPort, externalPort int) error {
+	return m.client.DeletePortMapping("", uint16(externalPort), strings.ToUpper(protocol))
+}
+
+func (m *upnpMapper) externalAddress() (string, error) {
+	return m.client.GetExternalIPAddress()
+}
+
+// natPMPMapper forwards ports through a NAT-PMP gateway
+type natPMPMapper struct {
+	client *natpmp.Client
+}
+
+func (m *natPMPMapper) method() string {
+	return "nat-pmp"
+}
+
+func (m *natPMPMapper) add(protocol string, port int) (int, error) {
+	result, err := m.client.AddPortMapping(protocol, port, port, int(portMappingLease.Seconds()))
+	if err != nil {
+		return 0, err
+	}
+	return int(result.MappedExternalPort), nil
+}
+
+func (m *natPMPMapper) remove(protocol string, internalPort, externalPort int) error {
+	// A zero lifetime deletes the mapping
+	_, err := m.client.AddPortMapping(protocol, internalPort, 0, 0)
+	return err
+}
+
+func (m *natPMPMapper) externalAddress() (string, error) {
+	result, err := m.client.GetExternalAddress()
+	if err != nil {
+		return "", err
+	}
+	return net.IP(result.ExternalIPAddress[:]).String(), nil
+}
+
+// discoverPortMapper finds a gateway that supports UPnP, falling back to NAT-PMP
+func discoverPortMapper() (portMapper, error) {
+	var clients []upnpClient
+	var locations []string
+	if found, _, err := internetgateway2.NewWANIPConnection1Clients(); err == nil {
+		for _, client := range found {
+			clients = append(clients, client)
+			locations = append(locations, client.ServiceClient.Location.Host)
+		}
+	}
+	if found, _, err := internetgateway2.NewWANPPPConnection1Clients(); err == nil {
+		for _, client := range found {
+			clients = append(clients, client)
+			locations = append(locations, client.ServiceClient.Location.Host)
+		}
+	}
+	for i, client := range clients {
+		if localAddr, err := localAddressTo(locations[i]); err == nil {
+			return &upnpMapper{client: client, localAddr: localAddr}, nil
+		}
+	}
+
+	gatewayIP, err := gateway.DiscoverGateway()
+	if err != nil {
+		return nil, fmt.Errorf("no UPnP gateway found and the default gateway is unknown: %w", err)
+	}
+	mapper := &natPMPMapper{client: natpmp.NewClientWithTimeout(gatewayIP, natPMPTimeout)}
+	if _, err := mapper.externalAddress(); err != nil {
+		return nil, fmt.Errorf("gateway %s supports neither UPnP nor NAT-PMP: %w", gatewayIP, err)
+	}
+	return mapper, nil
+}
+
+// localAddressTo returns the local address used to reach the given host:port
+func localAddressTo(hostport string) (string, error) {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = hostport
+	}
+	conn, err := net.Dial("udp", net.JoinHostPort(host, "1900"))
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).IP.String(), nil
+}
+
+// portMapTarget is a listening inbound port to forward
+type portMapTarget struct {
+	inbound  string
+	protocol string
+	port     int
+}
+
+// portMapTargets returns the ports of the inbounds that listen beyond the loopback interface and require
+// authentication, so an open proxy is never exposed to the internet
+func portMapTargets(logger *Logger, inbounds []inboundInfo) []portMapTarget {
+	var targets []portMapTarget
+	for _, inbound := range inbounds {
+		if inbound.ListenPort == 0 || inbound.Listen == "" {
+			continue
+		}
+		if addr, err := netip.ParseAddr(inbound.Listen); err == nil && addr.IsLoopback() {
+			continue
+		}
+		if !inbound.authenticated() {
+			logger.warn.Printf("Not mapping inbound %s: it has no users configured", inbound.Tag)
+			continue
+		}
+		for _, protocol := range inboundProtocols(inbound.Type) {
+			targets = append(targets, portMapTarget{inbound: inbound.Tag, protocol: protocol, port: inbound.ListenPort})
+		}
+	}
+	return targets
+}
+
+// inboundProtocols returns the transport protocols an inbound type listens on
+func inboundProtocols(inboundType string) []string {
+	switch inboundType {
+	case "tun", "redirect", "tproxy":
+		return nil
+	case "hysteria", "hysteria2", "tuic":
+		return []string{"udp"}
+	case "socks", "mixed", "shadowsocks", "direct":
+		return []string{"tcp", "udp"}
+	default:
+		return []string{"tcp"}
+	}
+}
+
+// portMapSession keeps the inbound ports of a sing-box instance forwarded until it is closed
+type portMapSession struct {
+	mu       sync.Mutex
+	logger   *Logger
+	mapper   portMapper
+	mappings []portMapping
+	closed   bool
+	done     chan struct{}
+}
+
+// newPortMapSession forwards the given ports in the background
+func newPortMapSession(logger *Logger, targets []portMapTarget) *portMapSession {
+	session := &portMapSession{logger: logger, done: make(chan struct{})}
+	go session.run(targets)
+	return session
+}
+
+// run discovers the gateway, adds the mappings and renews them until the session is closed
+func (p *portMapSession) run(targets []portMapTarget) {
+	mapper, err := discoverPortMapper()
+	if err != nil {
+		p.logger.warn.Printf("Port mapping unavailable: %v", err)
+		return
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.mapper = mapper
+	p.mu.Unlock()
+
+	ticker := time.NewTicker(portMappingRenewal)
+	defer ticker.Stop()
+	for {
+		if !p.store(mapper, mapAll(p.logger, mapper, targets)) {
+			return
+		}
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// store records the mappings added by the last renewal; if the session was closed meanwhile,
+// the mappings are removed again and false is returned
+func (p *portMapSession) store(mapper portMapper, mappings []portMapping) bool {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		removeAll(p.logger, mapper, mappings)
+		return false
+	}
+	if len(p.mappings) == 0 && len(mappings) > 0 {
+		p.logger.info.Printf("Mapped %d port(s) via %s", len(mappings), mapper.method())
+	}
+	p.mappings = mappings
+	p.mu.Unlock()
+	return true
+}
+
+// mapAll adds or renews a mapping for every target on the router
+func mapAll(logger *Logger, mapper portMapper, targets []portMapTarget) []portMapping {
+	externalAddress, err := mapper.externalAddress()
+	if err != nil {
+		logger.warn.Printf("Failed to get the external address: %v", err)
+	}
+
+	var mappings []portMapping
+	for _, target := range targets {
+		externalPort, err := mapper.add(target.protocol, target.port)
+		if err != nil {
+			logger.error.Printf("Failed to map %s port %d of %s: %v", target.protocol, target.port, target.inbound, err)
+			continue
+		}
+		mappings = append(mappings, portMapping{
+			inbound:         target.inbound,
+			protocol:        target.protocol,
+			internalPort:    target.port,
+			externalPort:    externalPort,
+			externalAddress: externalAddress,
+		})
+	}
+	return mappings
+}
+
+// removeAll deletes the given mappings from the router
+func removeAll(logger *Logger, mapper portMapper, mappings []portMapping) {
+	for _, mapping := range mappings {
+		if err := mapper.remove(mapping.protocol, mapping.internalPort, mapping.externalPort); err != nil {
+			logger.warn.Printf("Failed to remove %s port mapping %d: %v", mapping.protocol, mapping.externalPort, err)
+		}
+	}
+}
+
+// list returns the active mappings
+func (p *portMapSession) list() []portMapping {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]portMapping(nil), p.mappings...)
+}
+
+// close stops renewing and removes the mappings from the router in the background, so the callers
+// holding s.mu do not wait for the router
+func (p *portMapSession) close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.done)
+	mapper, mappings := p.mapper, p.mappings
+	p.mappings = nil
+	p.mu.Unlock()
+
+	if len(mappings) > 0 {
+		go removeAll(p.logger, mapper, mappings)
+	}
+}
+
+// startPortMapping forwards the listening inbounds of the running instance if enabled; the caller must hold s.mu
+func (s *Server) startPortMapping() {
+	if !s.portMapSettings.Enabled || s.portMap != nil {
+		return
+	}
+
+	targets := portMapTargets(s.logger, inspectInbounds(s.core.Options()))
+	if len(targets) == 0 {
+		s.logger.info.Println("Port mapping enabled, but no inbound with users listens beyond localhost")
+		return
+	}
+	s.portMap = newPortMapSession(s.logger, targets)
+}
+
+// stopPortMapping removes the mappings of the running instance; the caller must hold s.mu
+func (s *Server) stopPortMapping() {
+	if s.portMap == nil {
+		return
+	}
+	s.portMap.close()
+	s.portMap = nil
+}
+
+// loadPortMappingSettings applies the persisted port mapping settings
+func (s *Server) loadPortMappingSettings() {
+	state, err := s.loadState()
+	if err != nil {
+		s.logger.error.Printf("Failed to load port mapping settings: %v", err)
+		return
+	}
+	if state.PortMapping != nil {
+		s.mu.Lock()
+		s.portMapSettings = *state.PortMapping
+		s.mu.Unlock()
+	}
+}
+
+// portMappingResponse describes the settings and the active mappings; the caller must hold s.mu
+func (s *Server) portMappingResponse() *pb.PortMappingResponse {
+	response := &pb.PortMappingResponse{Enabled: s.portMapSettings.Enabled}
+	if s.portMap == nil {
+		return response
+	}
+	for _, mapping := range s.portMap.list() {
+		response.Mappings = append(response.Mappings, &pb.PortMapping{
+			Inbound:         mapping.inbound,
+			Protocol:        mapping.protocol,
+			InternalPort:    uint32(mapping.internalPort),
+			ExternalPort:    uint32(mapping.externalPort),
+			ExternalAddress: mapping.externalAddress,
+		})
+	}
+	return response
+}
+
+// SetPortMapping handles the gRPC SetPortMapping request, turning router port forwarding of listening inbounds on or off
+func (s *Server) SetPortMapping(ctx context.Context, req *pb.SetPortMappingRequest) (*pb.PortMappingResponse, error) {
+	settings := PortMappingSettings{Enabled: req.Enabled}
+	err := s.updateState(func(state *HelperState) {
+		state.PortMapping = &settings
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stopPortMapping()
+	s.portMapSettings = settings
+	if s.core.Running() {
+		s.startPortMapping()
+	}
+
+	s.logger.info.Printf("Port mapping enabled: %v", settings.Enabled)
+	return s.portMappingResponse(), nil
+}
+
+// GetPortMappings handles the gRPC GetPortMappings request, listing the ports forwarded on the router
+func (s *Server) GetPortMappings(ctx context.Context, req *pb.GetPortMappingsRequest) (*pb.PortMappingResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.portMappingResponse(), nil
+}
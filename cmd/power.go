@@ -0,0 +1,100 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+// PowerEvent is a system power or session transition reported by the platform
+type PowerEvent int
+
+const (
+	PowerSuspend PowerEvent = iota // The system is about to sleep
+	PowerResume                    // The system woke up from sleep
+	SessionEnd                     // The user is logging off or the system is shutting down
+)
+
+// String returns a readable name for the event
+func (e PowerEvent) String() string {
+	switch e {
+	case PowerSuspend:
+		return "suspend"
+	case PowerResume:
+		return "resume"
+	case SessionEnd:
+		return "session end"
+	default:
+		return "unknown"
+	}
+}
+
+// powerState remembers whether sing-box was running when the system went to sleep
+type powerState struct {
+	mu               sync.Mutex
+	runningAtSuspend bool
+}
+
+// handlePowerEvent stops sing-box before sleep or session end, so the system proxy it set never points
+// at a dead port, and re-establishes the tunnel after resume when resumeOnWake is set
+func (s *Server) handlePowerEvent(event PowerEvent, resumeOnWake bool) {
+	s.logger.info.Printf("Power event: %s", event)
+
+	s.mu.RLock()
+	running := s.core.Running()
+	s.mu.RUnlock()
+
+	switch event {
+	case SessionEnd:
+		s.cleanup.Run()
+
+	case PowerSuspend:
+		s.power.mu.Lock()
+		s.power.runningAtSuspend = running
+		s.power.mu.Unlock()
+		if running {
+			if err := s.stopSingBox(stopReasonSuspend); err != nil {
+				s.logger.error.Printf("Suspend stop error: %v", err)
+			}
+		}
+
+	case PowerResume:
+		s.power.mu.Lock()
+		wasRunning := s.power.runningAtSuspend
+		s.power.runningAtSuspend = false
+		s.power.mu.Unlock()
+		if !resumeOnWake {
+			return
+		}
+
+		switch {
+		case running:
+			// No suspend notice was received, so the tunnel slept with it and its handshakes are stale
+			s.reliability.reconnected()
+			if err := s.reloadSingBox(false, "reconnecting"); err != nil {
+				s.logger.error.Printf("Resume reconnect error: %v", err)
+			}
+		case wasRunning:
+			s.broadcastStatus("reconnecting")
+			if err := s.startSingBox(context.Background(), false); err != nil {
+				s.logger.error.Printf("Resume start error: %v", err)
+				s.reliability.failed(err, true)
+				s.broadcastStopped(stopReasonCrash)
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// Sleep detection parameters. IOKit power notifications require cgo, which release builds disable,
+// so sleep is measured with two clocks that ignore wall clock changes: CLOCK_MONOTONIC keeps counting
+// while the system sleeps and CLOCK_UPTIME_RAW stops, so the gap between them grows by the time slept.
+const (
+	sleepCheckInterval = 5 * time.Second
+	sleepGapThreshold  = 15 * time.Second
+)
+
+// sleepTime returns the time the system has spent asleep since boot
+func sleepTime() (time.Duration, error) {
+	var awake, total unix.Timespec
+	if err := unix.ClockGettime(unix.CLOCK_UPTIME_RAW, &awake); err != nil {
+		return 0, err
+	}
+	if err := unix.ClockGettime(unix.CLOCK_MONOTONIC, &total); err != nil {
+		return 0, err
+	}
+	return time.Duration(total.Nano() - awake.Nano()), nil
+}
+
+// watchPowerEvents reports a suspend and a resume event whenever the system wakes up from sleep.
+// The sleep cannot be observed in advance without IOKit, so both are reported on wake, in order,
+// which stops and restarts the tunnel like on the platforms announcing it.
+func watchPowerEvents(handler func(PowerEvent)) error {
+	last, err := sleepTime()
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(sleepCheckInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		slept, err := sleepTime()
+		if err != nil {
+			return err
+		}
+		if slept-last > sleepGapThreshold {
+			handler(PowerSuspend)
+			handler(PowerResume)
+		}
+		last = slept
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Window messages and power broadcast types used to observe power and session events
+const (
+	wmQueryEndSession     = 0x0011
+	wmEndSession          = 0x0016
+	wmPowerBroadcast      = 0x0218
+	pbtAPMSuspend         = 0x0004
+	pbtAPMResumeAutomatic = 0x0012
+)
+
+var (
+	user32               = windows.NewLazySystemDLL("user32.dll")
+	procRegisterClassExW = user32.NewProc("RegisterClassExW")
+	procCreateWindowExW  = user32.NewProc("CreateWindowExW")
+	procDefWindowProcW   = user32.NewProc("DefWindowProcW")
+	procGetMessageW      = user32.NewProc("GetMessageW")
+	procTranslateMessage = user32.NewProc("TranslateMessage")
+	procDispatchMessageW = user32.NewProc("DispatchMessageW")
+)
+
+// wndClassEx mirrors the Win32 WNDCLASSEXW structure
+type wndClassEx struct {
+	Size       uint32
+	Style      uint32
+	WndProc    uintptr
+	ClsExtra   int32
+	WndExtra   int32
+	Instance   windows.Handle
+	Icon       windows.Handle
+	Cursor     windows.Handle
+	Background windows.Handle
+	MenuName   *uint16
+	ClassName  *uint16
+	IconSm     windows.Handle
+}
+
+// winMsg mirrors the Win32 MSG structure
+type winMsg struct {
+	Hwnd    windows.HWND
+	Message uint32
+	WParam  uintptr
+	LParam  uintptr
+	Time    uint32
+	Pt      struct{ X, Y int32 }
+}
+
+// watchPowerEvents creates a hidden top-level window to receive power and session broadcasts
+// and reports them to handler. It blocks for the lifetime of the message loop.
+func watchPowerEvents(handler func(PowerEvent)) error {
+	// The window and its message loop must live on the same OS thread
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	className, err := windows.UTF16PtrFromString("OblivionHelperPowerWatcher")
+	if err != nil {
+		return err
+	}
+
+	wndProc := windows.NewCallback(func(hwnd windows.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+		switch msg {
+		case wmQueryEndSession:
+			return 1 // Never block the session from ending
+		case wmEndSession:
+			if wParam != 0 {
+				// The process may be terminated as soon as this message returns, so handle it synchronously
+				handler(SessionEnd)
+			}
+			return 0
+		case wmPowerBroadcast:
+			switch wParam {
+			case pbtAPMSuspend:
+				handler(PowerSuspend)
+			case pbtAPMResumeAutomatic:
+				go handler(PowerResume)
+			}
+			return 1
+		}
+		ret, _, _ := procDefWindowProcW.Call(uintptr(hwnd), uintptr(msg), wParam, lParam)
+		return ret
+	})
+
+	class := wndClassEx{
+		WndProc:   wndProc,
+		ClassName: className,
+	}
+	class.Size = uint32(unsafe.Sizeof(class))
+	if ret, _, err := procRegisterClassExW.Call(uintptr(unsafe.Pointer(&class))); ret == 0 {
+		return fmt.Errorf("failed to register window class: %w", err)
+	}
+
+	// Message-only windows do not receive broadcasts, so create a regular window that is never shown
+	hwnd, _, err := procCreateWindowExW.Call(0, uintptr(unsafe.Pointer(className)), uintptr(unsafe.Pointer(className)), 0, 0, 0, 0, 0, 0, 0, 0, 0)
+	if hwnd == 0 {
+		return fmt.Errorf("failed to create window: %w", err)
+	}
+
+	var msg winMsg
+	for {
+		ret, _, err := procGetMessageW.Call(uintptr(unsafe.Pointer(&msg)), 0, 0, 0)
+		switch int32(ret) {
+		case -1:
+			return fmt.Errorf("message loop failed: %w", err)
+		case 0:
+			return nil
+		}
+		procTranslateMessage.Call(uintptr(unsafe.Pointer(&msg)))
+		procDispatchMessageW.Call(uintptr(unsafe.Pointer(&msg)))
+	}
+}
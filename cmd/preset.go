@@ -0,0 +1,259 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+	"github.com/ShadowZagrosDev/oblivion-helper/internal/ruleset"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Rule-set sources and defaults used by the routing presets
+const (
+	geositeURL           = "https://raw.githubusercontent.com/SagerNet/sing-geosite/rule-set/%s.srs"
+	geoipURL             = "https://raw.githubusercontent.com/SagerNet/sing-geoip/rule-set/%s.srs"
+	adsRuleSet           = "geosite-category-ads-all"
+	defaultPresetRefresh = 7 // Update interval in days when the export list has none
+)
+
+// routingPreset is the domestic rule-sets of a country, whose traffic goes direct while the rest is proxied
+type routingPreset struct {
+	name     string
+	ruleSets []string
+}
+
+// routingPresets are the supported countries by ISO 3166 code
+var routingPresets = map[string]routingPreset{
+	"ir": {name: "Iran", ruleSets: []string{"geosite-category-ir", "geoip-ir"}},
+	"cn": {name: "China", ruleSets: []string{"geosite-cn", "geoip-cn"}},
+	"ru": {name: "Russia", ruleSets: []string{"geosite-category-ru", "geoip-ru"}},
+}
+
+// RoutingPresetSettings is the routing preset chosen by the user
+type RoutingPresetSettings struct {
+	Country  string `json:"country"` // ISO 3166 code
+	BlockAds bool   `json:"block_ads"`
+}
+
+// ruleSets returns the rule-sets the preset uses
+func (p RoutingPresetSettings) ruleSets() []string {
+	ruleSets := append([]string(nil), routingPresets[p.Country].ruleSets...)
+	if p.BlockAds {
+		ruleSets = append(ruleSets, adsRuleSet)
+	}
+	return ruleSets
+}
+
+// findRoutingPreset returns the code of the preset matching a country code or name
+func findRoutingPreset(country string) (string, bool) {
+	for code, preset := range routingPresets {
+		if strings.EqualFold(country, code) || strings.EqualFold(country, preset.name) {
+			return code, true
+		}
+	}
+	return "", false
+}
+
+// isPresetRuleSet reports whether a rule-set tag belongs to any preset
+func isPresetRuleSet(tag string) bool {
+	if tag == adsRuleSet {
+		return true
+	}
+	for _, preset := range routingPresets {
+		for _, ruleSet := range preset.ruleSets {
+			if ruleSet == tag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ruleSetURL returns the download URL of a preset rule-set
+func ruleSetURL(tag string) string {
+	if strings.HasPrefix(tag, "geoip-") {
+		return fmt.Sprintf(geoipURL, tag)
+	}
+	return fmt.Sprintf(geositeURL, tag)
+}
+
+// applyRoutingPreset adds the preset's rule-sets and rules to the config: ads are blocked and domestic
+// destinations go direct ahead of the config's own rules, but after the rules hijacking DNS queries, so
+// DNS keeps being resolved through sing-box. Rules left by a previous preset are replaced.
+func (s *Server) applyRoutingPreset(config map[string]any, preset RoutingPresetSettings) {
+	route, _ := config["route"].(map[string]any)
+	if route == nil {
+		route = make(map[string]any)
+		config["route"] = route
+	}
+
+	var ruleSets []any
+	existing, _ := route["rule_set"].([]any)
+	for _, item := range existing {
+		if ruleSet, ok := item.(map[string]any); ok {
+			if tag, _ := ruleSet["tag"].(string); isPresetRuleSet(tag) {
+				continue
+			}
+		}
+		ruleSets = append(ruleSets, item)
+	}
+	for _, tag := range preset.ruleSets() {
+		ruleSets = append(ruleSets, map[string]any{
+			"type":   "local",
+			"tag":    tag,
+			"format": "binary",
+			"path":   filepath.Join(s.dirPath, rulesetFolderName, tag+".srs"),
+		})
+	}
+	route["rule_set"] = ruleSets
+
+	var presetRules []any
+	if preset.BlockAds {
+		presetRules = append(presetRules, map[string]any{"rule_set": adsRuleSet, "outbound": outboundOfType(config, "block")})
+	}
+	presetRules = append(presetRules, map[string]any{"rule_set": routingPresets[preset.Country].ruleSets, "outbound": outboundOfType(config, "direct")})
+
+	var rules []any
+	existing, _ = route["rules"].([]any)
+	for _, item := range existing {
+		if rule, ok := item.(map[string]any); ok && isPresetRule(rule) {
+			continue
+		}
+		rules = append(rules, item)
+	}
+	route["rules"] = rules
+	insertRouteRules(config, presetRules...)
+}
+
+// isPresetRule reports whether a route rule only matches preset rule-sets
+func isPresetRule(rule map[string]any) bool {
+	var tags []string
+	switch value := rule["rule_set"].(type) {
+	case string:
+		tags = []string{value}
+	case []any:
+		for _, item := range value {
+			tag, _ := item.(string)
+			tags = append(tags, tag)
+		}
+	case []string:
+		tags = value
+	}
+	if len(tags) == 0 {
+		return false
+	}
+	for _, tag := range tags {
+		if !isPresetRuleSet(tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// outboundOfType returns the tag of the first outbound of the given type, adding one if the config has none
+func outboundOfType(config map[string]any, outboundType string) string {
+	outbounds, _ := config["outbounds"].([]any)
+	for _, item := range outbounds {
+		if outbound, ok := item.(map[string]any); ok && outbound["type"] == outboundType {
+			tag, _ := outbound["tag"].(string)
+			return tag
+		}
+	}
+	config["outbounds"] = append(outbounds, map[string]any{"type": outboundType, "tag": outboundType})
+	return outboundType
+}
+
+// updatePresetExportList replaces the preset entries of the export list with the rule-sets of the given preset
+func (s *Server) updatePresetExportList(preset *RoutingPresetSettings) error {
+	exportPath := filepath.Join(s.dirPath, exportListFileName)
+	exportConfig, err := ruleset.LoadExportConfig(exportPath, s.logger)
+	if err != nil {
+		return err
+	}
+
+	urls := make(map[string]string)
+	for filename, url := range exportConfig.URLs {
+		if !isPresetRuleSet(strings.TrimSuffix(filename, ".srs")) {
+			urls[filename] = url
+		}
+	}
+	if preset != nil {
+		for _, tag := range preset.ruleSets() {
+			urls[tag+".srs"] = ruleSetURL(tag)
+		}
+	}
+	exportConfig.URLs = urls
+	if exportConfig.Interval <= 0 {
+		exportConfig.Interval = defaultPresetRefresh
+	}
+
+	content, err := json.MarshalIndent(exportConfig, "", "    ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(exportPath, content)
+}
+
+// ApplyRoutingPreset handles the gRPC ApplyRoutingPreset request. Given the user's country, it adds the
+// rule-sets for domestic domains and addresses (routed direct) and optionally ads (blocked) to the export list,
+// and routes them ahead of the config's rules. An empty country removes the preset.
+func (s *Server) ApplyRoutingPreset(ctx context.Context, req *pb.ApplyRoutingPresetRequest) (*pb.ApplyRoutingPresetResponse, error) {
+	var preset *RoutingPresetSettings
+	if req.Country != "" {
+		code, ok := findRoutingPreset(req.Country)
+		if !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "no routing preset for %q", req.Country)
+		}
+		preset = &RoutingPresetSettings{Country: code, BlockAds: req.BlockAds}
+	}
+
+	if err := s.updatePresetExportList(preset); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update export list: %v", err)
+	}
+	err := s.updateState(func(state *HelperState) {
+		state.RoutingPreset = preset
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	response := &pb.ApplyRoutingPresetResponse{}
+	if preset != nil {
+		response.Country = preset.Country
+		response.RuleSets = preset.ruleSets()
+		s.logger.info.Printf("Routing preset %s applied (block ads: %v)", preset.Country, preset.BlockAds)
+	} else {
+		s.logger.info.Println("Routing preset removed")
+	}
+
+	s.mu.RLock()
+	running := s.core.Running()
+	s.mu.RUnlock()
+	if running {
+		if err := s.reloadSingBox(true, "reloading"); err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
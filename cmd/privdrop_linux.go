@@ -0,0 +1,148 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Securebits letting the process keep its capabilities, the effective ones included, when it leaves UID 0
+const (
+	secbitNoSetuidFixup = 1 << 2
+	secbitKeepCaps      = 1 << 4
+)
+
+// retainedCapabilities are kept after dropping root: TUN devices, routes and firewall rules, raw sockets
+// for ICMP, and inbounds on privileged ports
+var retainedCapabilities = []uint{unix.CAP_NET_ADMIN, unix.CAP_NET_RAW, unix.CAP_NET_BIND_SERVICE}
+
+// dropPrivileges switches the helper to the user who invoked it, keeping only the capabilities Sing-Box needs,
+// so the ruleset downloader and the gRPC server no longer run as root. The files the helper keeps writing are
+// handed to that user first, so state, rulesets and caches stay writable; the configs and the TLS credentials
+// stay owned by root.
+func (s *Server) dropPrivileges() error {
+	uid, gid, err := invokingUser()
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(s.dirPath)
+	if err != nil {
+		return err
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); !ok || int(stat.Uid) != uid {
+		return fmt.Errorf("%s must be owned by UID %d, since the helper keeps writing its files there", s.dirPath, uid)
+	}
+	for _, path := range s.runtimeFiles() {
+		if err := chownTree(path, uid, gid); err != nil {
+			return fmt.Errorf("failed to hand %s to UID %d: %w", path, uid, err)
+		}
+	}
+
+	// The effective capabilities are kept through the UID change, so a TUN device created
+	// by a concurrent start never lacks them
+	if err := allThreadsSyscall(unix.SYS_PRCTL, unix.PR_SET_SECUREBITS, secbitNoSetuidFixup|secbitKeepCaps, 0); err != nil {
+		return fmt.Errorf("failed to keep capabilities: %w", err)
+	}
+	if err := syscall.Setgroups(nil); err != nil {
+		return fmt.Errorf("failed to clear groups: %w", err)
+	}
+	if err := syscall.Setgid(gid); err != nil {
+		return fmt.Errorf("failed to set GID: %w", err)
+	}
+	if err := syscall.Setuid(uid); err != nil {
+		return fmt.Errorf("failed to set UID: %w", err)
+	}
+
+	var mask uint32
+	for _, capability := range retainedCapabilities {
+		mask |= 1 << capability
+	}
+	header := unix.CapUserHeader{Version: unix.LINUX_CAPABILITY_VERSION_3}
+	data := [2]unix.CapUserData{{Effective: mask, Permitted: mask}}
+	if err := allThreadsSyscall(unix.SYS_CAPSET, uintptr(unsafe.Pointer(&header)), uintptr(unsafe.Pointer(&data[0])), 0); err != nil {
+		return fmt.Errorf("failed to drop capabilities: %w", err)
+	}
+
+	s.logger.info.Printf("Dropped privileges to UID %d, keeping CAP_NET_ADMIN, CAP_NET_RAW and CAP_NET_BIND_SERVICE", uid)
+	return nil
+}
+
+// allThreadsSyscall runs a system call on every thread, since credentials and capabilities are per thread on Linux
+func allThreadsSyscall(trap, a1, a2, a3 uintptr) error {
+	if _, _, errno := syscall.AllThreadsSyscall(trap, a1, a2, a3); errno != 0 {
+		if errno == syscall.ENOTSUP {
+			return errors.New("not supported by builds with cgo enabled")
+		}
+		return errno
+	}
+	return nil
+}
+
+// invokingUser returns the UID and GID of the user who started the helper through sudo or pkexec
+func invokingUser() (int, int, error) {
+	account, err := invokingAccount()
+	if err != nil {
+		return 0, 0, err
+	}
+	uid, err := strconv.Atoi(account.Uid)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid UID %q", account.Uid)
+	}
+	gid, err := strconv.Atoi(account.Gid)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid GID %q", account.Gid)
+	}
+	return uid, gid, nil
+}
+
+// runtimeFiles returns the files and folders the helper keeps writing while it runs: state, journal, reliability and
+// usage records, connection logs, rulesets and the sing-box cache file
+func (s *Server) runtimeFiles() []string {
+	var paths []string
+	for _, name := range []string{stateFileName, journalFileName, reliabilityFileName, usageFileName, rulesetFolderName} {
+		paths = append(paths, filepath.Join(s.dirPath, name))
+	}
+	logs, _ := filepath.Glob(filepath.Join(s.dirPath, connectionLogPrefix+"*"+connectionLogSuffix))
+	paths = append(paths, logs...)
+
+	options, _ := s.loadSingBoxConfig()
+	_, cachePath := cacheFileOptions(options)
+	return append(paths, cachePath)
+}
+
+// chownTree hands a file, or a folder and everything below it, to the given user; missing paths are skipped
+func chownTree(root string, uid, gid int) error {
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(path, uid, gid)
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,123 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Inbound probe parameters
+const (
+	inboundProbeURL     = "https://www.gstatic.com/generate_204" // Fetched through the inbound when the client gives no URL
+	inboundProbeTimeout = 10 * time.Second
+)
+
+// proxyInbound returns the inbound with the given tag, or the first mixed, HTTP or SOCKS inbound if tag is empty.
+// Inbounds added at runtime are included; the caller must hold s.mu.
+func (s *Server) proxyInbound(tag string) (inboundInfo, bool) {
+	inbounds := inspectInbounds(s.core.Options())
+	for _, extra := range s.extraInbounds {
+		content, err := json.Marshal(extra.options)
+		if err != nil {
+			continue
+		}
+		var inbound inboundInfo
+		if err := json.Unmarshal(content, &inbound); err == nil {
+			inbounds = append(inbounds, inbound)
+		}
+	}
+
+	for _, inbound := range inbounds {
+		if tag != "" && inbound.Tag != tag {
+			continue
+		}
+		switch inbound.Type {
+		case "mixed", "http", "socks":
+			return inbound, true
+		}
+	}
+	return inboundInfo{}, false
+}
+
+// ProbeInbound handles the gRPC ProbeInbound request. It sends a test request through a local
+// SOCKS or HTTP inbound to check that the proxy path works before the OS proxy settings are changed.
+func (s *Server) ProbeInbound(ctx context.Context, req *pb.ProbeInboundRequest) (*pb.ProbeInboundResponse, error) {
+	s.mu.RLock()
+	running := s.core.Running()
+	inbound, found := s.proxyInbound(req.Inbound)
+	s.mu.RUnlock()
+
+	if !running {
+		return nil, status.Errorf(codes.FailedPrecondition, "sing-box is not running")
+	}
+	if !found {
+		if req.Inbound != "" {
+			return nil, status.Errorf(codes.NotFound, "no mixed, HTTP or SOCKS inbound %q", req.Inbound)
+		}
+		return nil, status.Errorf(codes.NotFound, "the config has no mixed, HTTP or SOCKS inbound")
+	}
+
+	target := req.Url
+	if target == "" {
+		target = inboundProbeURL
+	}
+
+	// Mixed inbounds accept both; SOCKS is used so the request takes the same path as most apps
+	scheme := "socks5"
+	if inbound.Type == "http" {
+		scheme = "http"
+	}
+	proxyURL := &url.URL{Scheme: scheme, Host: inbound.localAddress()}
+	if username, password, ok := inbound.firstUser(); ok {
+		proxyURL.User = url.UserPassword(username, password) // Inbounds with users reject anonymous clients
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, inboundProbeTimeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid URL: %v", err)
+	}
+
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+	defer client.CloseIdleConnections()
+
+	start := time.Now()
+	response := &pb.ProbeInboundResponse{Inbound: inbound.Tag}
+	resp, err := client.Do(request)
+	if err != nil {
+		response.Error = err.Error()
+		return response, nil
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	response.Ok = resp.StatusCode < http.StatusBadRequest
+	response.StatusCode = int32(resp.StatusCode)
+	response.LatencyMs = time.Since(start).Milliseconds()
+	return response, nil
+}
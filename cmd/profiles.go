@@ -0,0 +1,199 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+	"github.com/ShadowZagrosDev/oblivion-helper/internal/config"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// profilesFolderName is the folder next to the executable holding the named configs, one <name>.json each
+const profilesFolderName = "profiles"
+
+// profileNamePattern restricts profile names to ones that are safe as file names on every OS
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 _.-]{0,63}$`)
+
+// profilePath returns the config file of a named profile
+func (s *Server) profilePath(name string) string {
+	return filepath.Join(s.dirPath, profilesFolderName, name+".json")
+}
+
+// validProfileName checks that a profile name can be used as a file name
+func validProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) || strings.Contains(name, "..") {
+		return status.Errorf(codes.InvalidArgument, "invalid profile name %q: use up to 64 letters, digits, spaces, dots, dashes or underscores", name)
+	}
+	return nil
+}
+
+// currentProfile returns the name of the active profile, empty when sbConfig.json is used
+func (s *Server) currentProfile() string {
+	s.profileMu.Lock()
+	defer s.profileMu.Unlock()
+	return s.profile
+}
+
+// loadProfileSettings restores the active profile saved by SwitchProfile
+func (s *Server) loadProfileSettings() {
+	state, err := s.loadState()
+	if err != nil {
+		s.logger.error.Printf("Failed to load the active profile: %v", err)
+		return
+	}
+	if state.Profile == "" {
+		return
+	}
+	if _, err := os.Stat(s.profilePath(state.Profile)); err != nil {
+		s.logger.warn.Printf("Active profile %s is unavailable, using %s: %v", state.Profile, configFileName, err)
+		return
+	}
+
+	s.profileMu.Lock()
+	s.profile = state.Profile
+	s.profileMu.Unlock()
+}
+
+// ListProfiles handles the gRPC ListProfiles request, listing the saved profiles and the active one.
+// The default profile, with an empty name, is sbConfig.json.
+func (s *Server) ListProfiles(ctx context.Context, req *pb.ListProfilesRequest) (*pb.ListProfilesResponse, error) {
+	entries, err := os.ReadDir(filepath.Join(s.dirPath, profilesFolderName))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, status.Errorf(codes.Internal, "failed to list profiles: %v", err)
+	}
+
+	active := s.currentProfile()
+	response := &pb.ListProfilesResponse{Active: active}
+	for _, entry := range entries {
+		name, isConfig := strings.CutSuffix(entry.Name(), ".json")
+		if !isConfig || entry.IsDir() || validProfileName(name) != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		response.Profiles = append(response.Profiles, &pb.Profile{
+			Name:       name,
+			Active:     name == active,
+			Size:       info.Size(),
+			ModifiedAt: info.ModTime().Unix(),
+		})
+	}
+	sort.Slice(response.Profiles, func(i, j int) bool { return response.Profiles[i].Name < response.Profiles[j].Name })
+	return response, nil
+}
+
+// SaveProfile handles the gRPC SaveProfile request, creating or replacing a named profile.
+// The config is checked like TestConfig and rejected with the failing component if invalid.
+// Saving the active profile has the same effect as editing sbConfig.json did: see -auto-reload.
+func (s *Server) SaveProfile(ctx context.Context, req *pb.SaveProfileRequest) (*pb.SaveProfileResponse, error) {
+	if err := validProfileName(req.Name); err != nil {
+		return nil, err
+	}
+
+	options, err := config.Parse([]byte(req.Config), s.applyConfigOverrides, s.applyRuleSetFallbacks)
+	if err != nil {
+		return &pb.SaveProfileResponse{Component: "config", Error: err.Error()}, nil
+	}
+	if component, err := checkConfig(options); err != nil {
+		return &pb.SaveProfileResponse{Component: component, Error: err.Error()}, nil
+	}
+
+	path := s.profilePath(req.Name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create the profiles folder: %v", err)
+	}
+	if err := writeFileAtomic(path, []byte(req.Config)); err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	s.logger.info.Printf("Profile %s saved", req.Name)
+	return &pb.SaveProfileResponse{Saved: true}, nil
+}
+
+// DeleteProfile handles the gRPC DeleteProfile request. The active profile cannot be deleted.
+func (s *Server) DeleteProfile(ctx context.Context, req *pb.DeleteProfileRequest) (*pb.DeleteProfileResponse, error) {
+	if err := validProfileName(req.Name); err != nil {
+		return nil, err
+	}
+	if req.Name == s.currentProfile() {
+		return nil, status.Errorf(codes.FailedPrecondition, "profile %s is active, switch to another profile first", req.Name)
+	}
+
+	if err := os.Remove(s.profilePath(req.Name)); err != nil {
+		if os.IsNotExist(err) {
+			return nil, status.Errorf(codes.NotFound, "profile %s not found", req.Name)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to delete profile %s: %v", req.Name, err)
+	}
+
+	s.logger.info.Printf("Profile %s deleted", req.Name)
+	return &pb.DeleteProfileResponse{}, nil
+}
+
+// SwitchProfile handles the gRPC SwitchProfile request, making a profile the config used by Start.
+// An empty name switches back to sbConfig.json. The choice is persisted, and a running sing-box
+// switches right away, keeping the previous config if the profile fails to start.
+func (s *Server) SwitchProfile(ctx context.Context, req *pb.SwitchProfileRequest) (*pb.SwitchProfileResponse, error) {
+	if req.Name != "" {
+		if err := validProfileName(req.Name); err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(s.profilePath(req.Name)); err != nil {
+			return nil, status.Errorf(codes.NotFound, "profile %s not found", req.Name)
+		}
+	}
+
+	s.profileMu.Lock()
+	previous := s.profile
+	s.profile = req.Name
+	s.profileMu.Unlock()
+
+	s.mu.RLock()
+	running := s.core.Running()
+	s.mu.RUnlock()
+	if running {
+		if err := s.reloadSingBox(false, "reloading"); err != nil {
+			s.profileMu.Lock()
+			s.profile = previous
+			s.profileMu.Unlock()
+			return nil, err
+		}
+	}
+
+	err := s.updateState(func(state *HelperState) {
+		state.Profile = req.Name
+		if state.Connected {
+			state.Config = filepath.Base(s.configPath())
+		}
+	})
+	if err != nil {
+		s.logger.error.Printf("Failed to persist the active profile: %v", err)
+	}
+
+	s.logger.info.Printf("Switched to profile %s", filepath.Base(s.configPath()))
+	return &pb.SwitchProfileResponse{Active: req.Name}, nil
+}
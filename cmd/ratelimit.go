@@ -0,0 +1,172 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	"github.com/sagernet/sing-box/adapter"
+	"github.com/sagernet/sing/common/buf"
+	M "github.com/sagernet/sing/common/metadata"
+	N "github.com/sagernet/sing/common/network"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// rateLimitMinBurst is the smallest token bucket size, so a single read or write is not split into tiny waits
+const rateLimitMinBurst = 64 * 1024
+
+// rateLimiter caps the download and upload throughput shared by all tunnelled connections
+type rateLimiter struct {
+	mu       sync.Mutex
+	download *rate.Limiter
+	upload   *rate.Limiter
+	downRate int64 // Bytes per second, 0 means unlimited
+	upRate   int64 // Bytes per second, 0 means unlimited
+}
+
+// newRateLimiter creates a limiter with the given rates in bytes per second (0 means unlimited)
+func newRateLimiter(download, upload int64) *rateLimiter {
+	limiter := &rateLimiter{
+		download: rate.NewLimiter(rate.Inf, 0),
+		upload:   rate.NewLimiter(rate.Inf, 0),
+	}
+	limiter.set(download, upload)
+	return limiter
+}
+
+// set changes the rates; connections that are already open are throttled from their next read or write
+func (l *rateLimiter) set(download, upload int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.downRate, l.upRate = download, upload
+	setLimit(l.download, download)
+	setLimit(l.upload, upload)
+}
+
+// rates returns the current download and upload rates in bytes per second
+func (l *rateLimiter) rates() (int64, int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.downRate, l.upRate
+}
+
+// setLimit configures a token bucket for the given rate in bytes per second
+func setLimit(limiter *rate.Limiter, bytesPerSecond int64) {
+	if bytesPerSecond <= 0 {
+		limiter.SetLimit(rate.Inf)
+		return
+	}
+	limiter.SetBurst(int(max(bytesPerSecond, rateLimitMinBurst)))
+	limiter.SetLimit(rate.Limit(bytesPerSecond))
+}
+
+// waitN blocks until n bytes may pass the limiter
+func waitN(limiter *rate.Limiter, n int) {
+	for n > 0 {
+		if limiter.Limit() == rate.Inf {
+			return
+		}
+		chunk := min(n, limiter.Burst())
+		if err := limiter.WaitN(context.Background(), chunk); err != nil {
+			return
+		}
+		n -= chunk
+	}
+}
+
+// rateLimitService hooks into the sing-box router as a V2Ray stats service, which lets it wrap every routed connection
+type rateLimitService struct {
+	limiter *rateLimiter
+}
+
+func (r *rateLimitService) Start() error { return nil }
+func (r *rateLimitService) Close() error { return nil }
+
+func (r *rateLimitService) StatsService() adapter.V2RayStatsService { return r }
+
+// RoutedConnection wraps a TCP connection; reads from the inbound side are uploads and writes are downloads
+func (r *rateLimitService) RoutedConnection(inbound string, outbound string, user string, conn net.Conn) net.Conn {
+	return &limitedConn{Conn: conn, limiter: r.limiter}
+}
+
+// RoutedPacketConnection wraps a UDP connection
+func (r *rateLimitService) RoutedPacketConnection(inbound string, outbound string, user string, conn N.PacketConn) N.PacketConn {
+	return &limitedPacketConn{PacketConn: conn, limiter: r.limiter}
+}
+
+// limitedConn throttles a stream connection
+type limitedConn struct {
+	net.Conn
+	limiter *rateLimiter
+}
+
+func (c *limitedConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	waitN(c.limiter.upload, n)
+	return n, err
+}
+
+func (c *limitedConn) Write(b []byte) (int, error) {
+	waitN(c.limiter.download, len(b))
+	return c.Conn.Write(b)
+}
+
+// limitedPacketConn throttles a packet connection
+type limitedPacketConn struct {
+	N.PacketConn
+	limiter *rateLimiter
+}
+
+func (c *limitedPacketConn) ReadPacket(buffer *buf.Buffer) (M.Socksaddr, error) {
+	destination, err := c.PacketConn.ReadPacket(buffer)
+	waitN(c.limiter.upload, buffer.Len())
+	return destination, err
+}
+
+func (c *limitedPacketConn) WritePacket(buffer *buf.Buffer, destination M.Socksaddr) error {
+	waitN(c.limiter.download, buffer.Len())
+	return c.PacketConn.WritePacket(buffer, destination)
+}
+
+// installRateLimit attaches the rate limiter to a new instance before it starts routing connections
+func (s *Server) installRateLimit(router adapter.Router) {
+	if router.V2RayServer() != nil {
+		s.logger.warn.Println("Rate limiting disabled: the config already enables the V2Ray API")
+		return
+	}
+	router.SetV2RayServer(&rateLimitService{limiter: s.rateLimit})
+}
+
+// SetRateLimit handles the gRPC SetRateLimit request, changing the tunnel throughput caps at runtime
+func (s *Server) SetRateLimit(ctx context.Context, req *pb.SetRateLimitRequest) (*pb.RateLimitResponse, error) {
+	if req.DownloadBytesPerSec < 0 || req.UploadBytesPerSec < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "rate limits cannot be negative")
+	}
+
+	s.rateLimit.set(req.DownloadBytesPerSec, req.UploadBytesPerSec)
+	s.logger.info.Printf("Rate limit set: down %d B/s, up %d B/s", req.DownloadBytesPerSec, req.UploadBytesPerSec)
+
+	download, upload := s.rateLimit.rates()
+	return &pb.RateLimitResponse{DownloadBytesPerSec: download, UploadBytesPerSec: upload}, nil
+}
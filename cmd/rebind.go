@@ -0,0 +1,148 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"net"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/jackpal/gateway"
+)
+
+// rebindState tracks the interface outbound connections were moved to after the bound interface went away
+type rebindState struct {
+	mu   sync.Mutex
+	from string // Interface the config or the user bound to
+	to   string // Interface used in its place, empty while no rebind is active
+}
+
+// reboundInterface returns the interface replacing the bound one, or an empty string
+func (s *Server) reboundInterface() string {
+	s.rebind.mu.Lock()
+	defer s.rebind.mu.Unlock()
+	return s.rebind.to
+}
+
+// setRebind records a rebind from one interface to another; an empty to clears it
+func (s *Server) setRebind(from, to string) {
+	s.rebind.mu.Lock()
+	defer s.rebind.mu.Unlock()
+	s.rebind.from, s.rebind.to = from, to
+}
+
+// interfaceUsable reports whether the named interface is up with a routable address
+func interfaceUsable(name string) bool {
+	iface, err := net.InterfaceByName(name)
+	if err != nil || iface.Flags&net.FlagUp == 0 {
+		return false
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return false
+	}
+	for _, addr := range addrs {
+		if prefix, err := netip.ParsePrefix(addr.String()); err == nil && !prefix.Addr().IsLinkLocalUnicast() {
+			return true
+		}
+	}
+	return false
+}
+
+// physicalInterface returns the name of the interface holding the default gateway, ignoring the
+// helper's own TUN interface, or an empty string if there is none
+func physicalInterface(ignore []netip.Prefix) string {
+	ip, err := gateway.DiscoverInterface()
+	if err != nil {
+		return ""
+	}
+	local, ok := netip.AddrFromSlice(ip)
+	if !ok || prefixesContain(ignore, local.Unmap()) {
+		return ""
+	}
+
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return ""
+	}
+	for _, iface := range interfaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if prefix, err := netip.ParsePrefix(addr.String()); err == nil && prefix.Addr().Unmap() == local.Unmap() {
+				return iface.Name
+			}
+		}
+	}
+	return ""
+}
+
+// watchBindInterface follows default route changes while sing-box is running with outbounds bound to an
+// interface. When the bound interface goes down (cable unplugged, Wi-Fi roam), outbounds are rebound to the
+// interface holding the new default route, and moved back once the original interface returns.
+// Interface detection by sing-box itself (auto_detect_interface) needs no help and is left alone.
+func (s *Server) watchBindInterface() {
+	ticker := time.NewTicker(networkCheckInterval)
+	defer ticker.Stop()
+
+	var pending string // Rebind target seen on the previous check, acted on once it holds for a full interval
+	for range ticker.C {
+		s.mu.RLock()
+		running := s.core.Running()
+		options := s.core.Options()
+		s.mu.RUnlock()
+
+		route, _ := inspectRoute(options)
+		if !running || route.DefaultInterface == "" {
+			pending = ""
+			continue
+		}
+
+		s.rebind.mu.Lock()
+		from, to := s.rebind.from, s.rebind.to
+		s.rebind.mu.Unlock()
+
+		var target string
+		switch {
+		case to != "" && interfaceUsable(from):
+			target = from
+		case !interfaceUsable(route.DefaultInterface):
+			target = physicalInterface(tunPrefixes(options))
+		}
+		if target == "" || target == route.DefaultInterface || target != pending {
+			pending = target
+			continue
+		}
+		pending = ""
+
+		if target == from && to != "" {
+			s.setRebind("", "")
+		} else if to != "" {
+			s.setRebind(from, target)
+		} else {
+			s.setRebind(route.DefaultInterface, target)
+		}
+
+		s.logger.info.Printf("Default route moved, rebinding outbounds from %s to %s", route.DefaultInterface, target)
+		s.broadcastEvent("interface-rebound", map[string]string{"from": route.DefaultInterface, "to": target})
+		if err := s.restartIfRunning(); err != nil {
+			s.logger.error.Printf("Rebind reload error: %v", err)
+		}
+	}
+}
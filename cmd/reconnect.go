@@ -0,0 +1,161 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Backoff between the background attempts of a failed start
+const (
+	startRetryMinDelay = 2 * time.Second
+	startRetryMaxDelay = time.Minute
+)
+
+// pendingReconnect is a background attempt to start sing-box once a condition is met
+type pendingReconnect struct {
+	cancel context.CancelFunc
+}
+
+// reconnectState tracks the reconnect attempt scheduled in the background, if any
+type reconnectState struct {
+	mu      sync.Mutex
+	pending *pendingReconnect
+}
+
+// scheduleReconnect starts sing-box in the background as soon as wait returns without error,
+// replacing any previously scheduled attempt. The attempt is abandoned if it is cancelled first.
+func (s *Server) scheduleReconnect(wait func(ctx context.Context) error) {
+	ctx, pending := s.beginReconnect()
+	s.cleanup.Go(func() {
+		defer s.finishReconnect(pending)
+
+		if err := wait(ctx); err != nil {
+			if ctx.Err() == nil {
+				s.logger.error.Printf("Reconnect abandoned: %v", err)
+				s.reliability.failed(err, true)
+				s.reliability.disconnected()
+				s.broadcastStopped(stopReasonCrash)
+			}
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		s.broadcastStatus("reconnecting")
+		s.reliability.reconnected()
+		err := s.startSingBox(ctx, false)
+		if code := status.Code(err); code != codes.OK && code != codes.AlreadyExists && code != codes.Canceled {
+			s.logger.error.Printf("Reconnect error: %v", err)
+			s.reliability.failed(err, true)
+			s.reliability.disconnected()
+			s.broadcastStopped(stopReasonCrash)
+		}
+	})
+}
+
+// scheduleStartRetry retries a failed start in the background with exponential backoff, broadcasting "retrying"
+// with the attempt number. It stops once sing-box starts, an error that retrying cannot fix occurs, or the attempt
+// is cancelled by Start, Stop or Exit. Attempts are skipped while no network is available.
+func (s *Server) scheduleStartRetry() {
+	ctx, pending := s.beginReconnect()
+	s.cleanup.Go(func() {
+		defer s.finishReconnect(pending)
+
+		delay := startRetryMinDelay
+		for attempt := 1; ; {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			delay = min(delay*2, startRetryMaxDelay)
+			if !networkReady() {
+				continue
+			}
+
+			s.publishStatus("retrying", map[string]string{"attempt": strconv.Itoa(attempt)})
+			err := s.startSingBox(ctx, false)
+			if err == nil || status.Code(err) == codes.AlreadyExists || ctx.Err() != nil {
+				return
+			}
+			if !retryableStartError(err) {
+				s.logger.error.Printf("Start retry abandoned: %v", err)
+				s.reliability.failed(err, false)
+				s.broadcastStopped(stopReasonCrash)
+				return
+			}
+			s.logger.warn.Printf("Start attempt %d failed: %v", attempt, err)
+			attempt++
+		}
+	})
+}
+
+// retryableStartError reports whether a start failed for a reason that may go away, such as an unreachable
+// endpoint, rather than an invalid config or missing privileges
+func retryableStartError(err error) bool {
+	switch status.Code(err) {
+	case codes.Internal, codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+// beginReconnect registers a new background attempt, replacing and cancelling any previously scheduled one
+func (s *Server) beginReconnect() (context.Context, *pendingReconnect) {
+	ctx, cancel := context.WithCancel(context.Background())
+	pending := &pendingReconnect{cancel: cancel}
+
+	s.reconnect.mu.Lock()
+	defer s.reconnect.mu.Unlock()
+	if s.reconnect.pending != nil {
+		s.reconnect.pending.cancel()
+	}
+	s.reconnect.pending = pending
+	return ctx, pending
+}
+
+// finishReconnect forgets the given attempt unless it has already been replaced
+func (s *Server) finishReconnect(pending *pendingReconnect) {
+	s.reconnect.mu.Lock()
+	defer s.reconnect.mu.Unlock()
+
+	if s.reconnect.pending == pending {
+		s.reconnect.pending = nil
+	}
+	pending.cancel()
+}
+
+// cancelReconnect abandons the scheduled reconnect attempt, returning whether one was pending
+func (s *Server) cancelReconnect() bool {
+	s.reconnect.mu.Lock()
+	defer s.reconnect.mu.Unlock()
+
+	if s.reconnect.pending == nil {
+		return false
+	}
+	s.reconnect.pending.cancel()
+	s.reconnect.pending = nil
+	return true
+}
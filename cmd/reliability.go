@@ -0,0 +1,212 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+)
+
+// Reliability history settings
+const (
+	reliabilityFileName  = "sbReliability.json" // Name of the file storing daily reliability aggregates
+	reliabilityRetention = 90                   // Number of days kept in the history
+	reliabilityDateFmt   = "2006-01-02"         // Date format of the history keys
+)
+
+// DailyReliability holds the reliability counters aggregated over one day
+type DailyReliability struct {
+	Sessions      uint32 `json:"sessions"`
+	UptimeSeconds int64  `json:"uptime_seconds"`
+	Reconnects    uint32 `json:"reconnects"`
+	Crashes       uint32 `json:"crashes"`
+}
+
+// reliabilityTracker tracks uptime, reconnects and failures of the current session
+// and persists daily aggregates of them
+type reliabilityTracker struct {
+	mu           sync.Mutex
+	path         string    // Path of the daily aggregates file
+	logger       *Logger   // Logger for persistence errors
+	sessionStart time.Time // Start of the current session, zero when there is none
+	reconnects   uint32    // Reconnects during the current session
+	crashes      uint32    // Unexpected sing-box failures during the current session
+	lastError    string    // Last error of the current session
+}
+
+// newReliabilityTracker creates a tracker persisting its aggregates in dirPath
+func newReliabilityTracker(dirPath string, logger *Logger) *reliabilityTracker {
+	return &reliabilityTracker{
+		path:   filepath.Join(dirPath, reliabilityFileName),
+		logger: logger,
+	}
+}
+
+// connected marks sing-box as running, beginning a new session if none is active
+func (r *reliabilityTracker) connected() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.sessionStart.IsZero() {
+		return
+	}
+	r.sessionStart = time.Now()
+	r.reconnects, r.crashes, r.lastError = 0, 0, ""
+	r.update(func(day *DailyReliability) { day.Sessions++ })
+}
+
+// disconnected ends the current session, adding its uptime to the daily aggregates
+func (r *reliabilityTracker) disconnected() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.sessionStart.IsZero() {
+		return
+	}
+	uptime := int64(time.Since(r.sessionStart).Seconds())
+	r.sessionStart = time.Time{}
+	r.update(func(day *DailyReliability) { day.UptimeSeconds += uptime })
+}
+
+// reconnected counts an automatic reconnect of the current session
+func (r *reliabilityTracker) reconnected() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.sessionStart.IsZero() {
+		return // The first connection of a session is not a reconnect
+	}
+
+	r.reconnects++
+	r.update(func(day *DailyReliability) { day.Reconnects++ })
+}
+
+// failed records an error; crashes (unexpected loss of the tunnel) are also counted
+func (r *reliabilityTracker) failed(err error, crash bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastError = err.Error()
+	if crash {
+		r.crashes++
+		r.update(func(day *DailyReliability) { day.Crashes++ })
+	}
+}
+
+// snapshot returns the counters of the current session
+func (r *reliabilityTracker) snapshot() (uptime time.Duration, reconnects, crashes uint32, lastError string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.sessionStart.IsZero() {
+		uptime = time.Since(r.sessionStart)
+	}
+	return uptime, r.reconnects, r.crashes, r.lastError
+}
+
+// load reads the daily aggregates, keyed by date
+func (r *reliabilityTracker) load() map[string]*DailyReliability {
+	history := make(map[string]*DailyReliability)
+
+	content, err := os.ReadFile(r.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			r.logger.error.Printf("Failed to read reliability history: %v", err)
+		}
+		return history
+	}
+	if err := json.Unmarshal(content, &history); err != nil {
+		r.logger.error.Printf("Failed to parse reliability history: %v", err)
+	}
+	return history
+}
+
+// update applies fn to today's aggregate and persists the history; the caller must hold r.mu
+func (r *reliabilityTracker) update(fn func(day *DailyReliability)) {
+	history := r.load()
+
+	today := time.Now().Format(reliabilityDateFmt)
+	if history[today] == nil {
+		history[today] = &DailyReliability{}
+	}
+	fn(history[today])
+
+	cutoff := time.Now().AddDate(0, 0, -reliabilityRetention).Format(reliabilityDateFmt)
+	for date := range history {
+		if date < cutoff {
+			delete(history, date)
+		}
+	}
+
+	content, err := json.MarshalIndent(history, "", "  ")
+	if err == nil {
+		err = writeFileAtomic(r.path, content)
+	}
+	if err != nil {
+		r.logger.error.Printf("Failed to persist reliability history: %v", err)
+	}
+}
+
+// GetStatus handles the gRPC GetStatus request, returning the current state and session counters
+func (s *Server) GetStatus(ctx context.Context, req *pb.GetStatusRequest) (*pb.GetStatusResponse, error) {
+	uptime, reconnects, crashes, lastError := s.reliability.snapshot()
+
+	return &pb.GetStatusResponse{
+		Status:        s.currentStatus(),
+		UptimeSeconds: int64(uptime.Seconds()),
+		Reconnects:    reconnects,
+		Crashes:       crashes,
+		LastError:     lastError,
+		Elevated:      s.elevated,
+		ConfigPath:    s.configPath(),
+	}, nil
+}
+
+// GetReliabilityHistory handles the gRPC GetReliabilityHistory request, returning the daily
+// aggregates of the requested number of days (all retained days if zero), oldest first
+func (s *Server) GetReliabilityHistory(ctx context.Context, req *pb.ReliabilityHistoryRequest) (*pb.ReliabilityHistoryResponse, error) {
+	s.reliability.mu.Lock()
+	history := s.reliability.load()
+	s.reliability.mu.Unlock()
+
+	var cutoff string
+	if req.Days > 0 {
+		cutoff = time.Now().AddDate(0, 0, -int(req.Days)+1).Format(reliabilityDateFmt)
+	}
+
+	resp := &pb.ReliabilityHistoryResponse{}
+	for date, day := range history {
+		if date < cutoff {
+			continue
+		}
+		resp.Days = append(resp.Days, &pb.DailyReliability{
+			Date:          date,
+			Sessions:      day.Sessions,
+			UptimeSeconds: day.UptimeSeconds,
+			Reconnects:    day.Reconnects,
+			Crashes:       day.Crashes,
+		})
+	}
+	sort.Slice(resp.Days, func(i, j int) bool { return resp.Days[i].Date < resp.Days[j].Date })
+	return resp, nil
+}
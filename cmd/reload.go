@@ -0,0 +1,111 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	option "github.com/sagernet/sing-box/option"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// changedSections returns the top-level config sections (dns, inbounds, route...) that differ between two options
+func changedSections(running, loaded *option.Options) ([]string, error) {
+	sections := func(options *option.Options) (map[string]json.RawMessage, error) {
+		content, err := json.Marshal(options)
+		if err != nil {
+			return nil, err
+		}
+		var result map[string]json.RawMessage
+		return result, json.Unmarshal(content, &result)
+	}
+
+	before, err := sections(running)
+	if err != nil {
+		return nil, err
+	}
+	after, err := sections(loaded)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for name, value := range after {
+		if !bytes.Equal(before[name], value) {
+			changed = append(changed, name)
+		}
+	}
+	for name := range before {
+		if _, found := after[name]; !found {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// pendingConfigChanges loads the config file and returns the sections that differ from the running instance
+func (s *Server) pendingConfigChanges() ([]string, error) {
+	options, err := s.loadSingBoxConfig()
+	if err != nil {
+		return nil, err
+	}
+	// The running options went through the same preparation before sing-box was started
+	enableClashAPI(options)
+
+	s.mu.RLock()
+	running := s.core.Running()
+	previous := s.core.Options()
+	s.mu.RUnlock()
+	if !running {
+		return nil, status.Errorf(codes.FailedPrecondition, "sing-box is not running")
+	}
+
+	changed, err := changedSections(previous, options)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to compare configs: %v", err)
+	}
+	return changed, nil
+}
+
+// Reload handles the gRPC Reload request, applying changes to sbConfig.json without a Stop and Start.
+// The new config is compared with the running one: if nothing changed, the tunnel is left untouched;
+// otherwise the instance is replaced in one step, keeping the previous config if the new one fails to start.
+func (s *Server) Reload(ctx context.Context, req *pb.ReloadRequest) (*pb.ReloadResponse, error) {
+	changed, err := s.pendingConfigChanges()
+	if err != nil {
+		if status.Code(err) == codes.InvalidArgument {
+			s.broadcastEvent("config-invalid", map[string]string{"error": err.Error()}) // The running instance is kept
+		}
+		return nil, err
+	}
+	if len(changed) == 0 {
+		return &pb.ReloadResponse{Message: "Configuration unchanged."}, nil
+	}
+
+	s.requestLogger(ctx).info.Printf("Reloading changed config sections: %v", changed)
+	if err := s.reloadSingBox(false, "reloading"); err != nil {
+		return nil, err
+	}
+	return &pb.ReloadResponse{Reloaded: true, ChangedSections: changed, Message: "Configuration reloaded."}, nil
+}
@@ -0,0 +1,164 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"path"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// correlationHeader carries the correlation ID of an RPC. Clients may set it to tie the helper's logs to their own;
+// otherwise a new ID is generated. It is always returned in the response headers.
+const correlationHeader = "x-correlation-id"
+
+// operationMethods are the RPCs that change the connection state. They run one at a time, and their correlation ID
+// is attached to the status events produced while they run.
+var operationMethods = map[string]bool{
+	"Start":   true,
+	"Stop":    true,
+	"Restart": true,
+	"Reload":  true,
+	"Exit":    true,
+}
+
+// cancellingMethods cancel the running state-changing RPC instead of waiting for it, so a slow Start can be aborted
+var cancellingMethods = map[string]bool{
+	"Stop": true,
+	"Exit": true,
+}
+
+// Keys of the request values stored in the context of an RPC
+type (
+	correlationKey        struct{}
+	operationCancelledKey struct{}
+)
+
+// correlationID returns the correlation ID of the RPC the context belongs to
+func correlationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationKey{}).(string)
+	return id
+}
+
+// operationCancelled reports whether the RPC cancelled a running state-changing RPC before it ran
+func operationCancelled(ctx context.Context) bool {
+	cancelled, _ := ctx.Value(operationCancelledKey{}).(bool)
+	return cancelled
+}
+
+// requestLogger returns a logger whose lines carry the correlation ID of the RPC, or the shared logger outside one
+func (s *Server) requestLogger(ctx context.Context) *Logger {
+	if id := correlationID(ctx); id != "" {
+		return s.logger.withCorrelation(id)
+	}
+	return s.logger
+}
+
+// requestCorrelationID returns the correlation ID sent by the client, or a new one
+func requestCorrelationID(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(correlationHeader); len(values) > 0 && values[0] != "" {
+			return values[0]
+		}
+	}
+
+	var id [6]byte
+	rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// beginOperation waits for the running state-changing operation to end and marks id as the running one until
+// the returned function is called. The returned context is cancelled by a later Stop or Exit, but not when the
+// client goes away, so an abandoned Start still completes.
+func (s *Server) beginOperation(ctx context.Context, id string) (context.Context, func()) {
+	s.operationMu.Lock()
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	s.setOperation(id, cancel)
+
+	return ctx, func() {
+		s.setOperation("", nil)
+		cancel()
+		s.operationMu.Unlock()
+	}
+}
+
+// cancelOperation cancels the running state-changing operation, reporting whether there was one
+func (s *Server) cancelOperation() bool {
+	s.operationIDMu.Lock()
+	defer s.operationIDMu.Unlock()
+	if s.operationCancel == nil {
+		return false
+	}
+	s.operationCancel()
+	return true
+}
+
+// setOperation records the correlation ID attached to status events and the cancel function of the operation
+func (s *Server) setOperation(id string, cancel context.CancelFunc) {
+	s.operationIDMu.Lock()
+	defer s.operationIDMu.Unlock()
+	s.operationID = id
+	s.operationCancel = cancel
+}
+
+// currentOperationID returns the correlation ID of the running state-changing operation, if any
+func (s *Server) currentOperationID() string {
+	s.operationIDMu.Lock()
+	defer s.operationIDMu.Unlock()
+	return s.operationID
+}
+
+// logUnary logs every unary RPC with its method, duration, result code and correlation ID
+func (s *Server) logUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	id := requestCorrelationID(ctx)
+	grpc.SetHeader(ctx, metadata.Pairs(correlationHeader, id))
+
+	ctx = context.WithValue(ctx, correlationKey{}, id)
+	method := path.Base(info.FullMethod)
+	if cancellingMethods[method] && s.cancelOperation() {
+		ctx = context.WithValue(ctx, operationCancelledKey{}, true)
+	}
+	if operationMethods[method] {
+		var end func()
+		ctx, end = s.beginOperation(ctx, id)
+		defer end()
+	}
+
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	s.logger.info.Printf("RPC %s [%s] %s in %s", method, id, status.Code(err), time.Since(start).Round(time.Millisecond))
+	return resp, err
+}
+
+// logStream logs the opening and closing of every streaming RPC with its correlation ID
+func (s *Server) logStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	id := requestCorrelationID(ss.Context())
+	ss.SetHeader(metadata.Pairs(correlationHeader, id))
+
+	method := path.Base(info.FullMethod)
+	s.logger.info.Printf("RPC %s [%s] opened", method, id)
+
+	start := time.Now()
+	err := handler(srv, ss)
+	s.logger.info.Printf("RPC %s [%s] %s after %s", method, id, status.Code(err), time.Since(start).Round(time.Millisecond))
+	return err
+}
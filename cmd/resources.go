@@ -0,0 +1,89 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+)
+
+// Sampling interval of StreamResourceUsage
+const (
+	resourceSampleInterval    = 5 * time.Second
+	resourceSampleMinInterval = time.Second
+)
+
+// resourceUsage samples the memory and goroutines of the process, which hosts both the helper and sing-box,
+// with the live connections and uptimes
+func (s *Server) resourceUsage() *pb.ResourceUsage {
+	var memory runtime.MemStats
+	runtime.ReadMemStats(&memory)
+
+	usage := &pb.ResourceUsage{
+		Timestamp:           time.Now().UnixMilli(),
+		HeapAllocBytes:      int64(memory.HeapAlloc),
+		HeapInUseBytes:      int64(memory.HeapInuse),
+		SysBytes:            int64(memory.Sys),
+		GcCycles:            memory.NumGC,
+		Goroutines:          int32(runtime.NumGoroutine()),
+		HelperUptimeSeconds: int64(time.Since(s.launchedAt).Seconds()),
+	}
+	sessionUptime, _, _, _ := s.reliability.snapshot()
+	usage.SessionUptimeSeconds = int64(sessionUptime.Seconds())
+
+	s.mu.RLock()
+	if server, err := s.clashServer(); err == nil {
+		usage.Running = true
+		usage.Connections = int32(len(server.TrafficManager().Snapshot().Connections))
+	}
+	s.mu.RUnlock()
+	return usage
+}
+
+// GetResourceUsage handles the gRPC GetResourceUsage request. sing-box runs inside the helper process,
+// so the memory and goroutine figures cover both.
+func (s *Server) GetResourceUsage(ctx context.Context, req *pb.GetResourceUsageRequest) (*pb.ResourceUsage, error) {
+	return s.resourceUsage(), nil
+}
+
+// StreamResourceUsage handles the gRPC StreamResourceUsage request, sending a sample every interval_ms
+// (5 seconds by default, at least 1 second) to follow memory growth over long sessions.
+// Closing this stream does not stop sing-box.
+func (s *Server) StreamResourceUsage(req *pb.StreamResourceUsageRequest, stream pb.OblivionService_StreamResourceUsageServer) error {
+	interval := resourceSampleInterval
+	if req.IntervalMs > 0 {
+		interval = max(time.Duration(req.IntervalMs)*time.Millisecond, resourceSampleMinInterval)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := stream.Send(s.resourceUsage()); err != nil {
+			s.logger.error.Printf("Resource usage stream error: %v", err)
+			return err
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// defaultRouteTable is the iproute2 table sing-box uses for auto_route when none is configured
+const defaultRouteTable = 2022
+
+// repairRoutes removes the policy routing rules, routes and nftables table that
+// auto_route leaves behind when sing-box is not closed properly
+func repairRoutes(table int) error {
+	if _, err := exec.LookPath("ip"); err != nil {
+		return fmt.Errorf("ip command not found: %w", err)
+	}
+	if table == 0 {
+		table = defaultRouteTable
+	}
+	tableArg := fmt.Sprint(table)
+
+	for _, family := range []string{"-4", "-6"} {
+		// Each call removes a single rule, so repeat until none are left
+		for i := 0; i < 64; i++ {
+			if err := exec.Command("ip", family, "rule", "del", "table", tableArg).Run(); err != nil {
+				break
+			}
+		}
+		exec.Command("ip", family, "route", "flush", "table", tableArg).Run()
+	}
+
+	if _, err := exec.LookPath("nft"); err == nil {
+		exec.Command("nft", "delete", "table", "inet", "sing-box").Run()
+	}
+	return nil
+}
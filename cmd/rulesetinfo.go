@@ -0,0 +1,108 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+	"github.com/ShadowZagrosDev/oblivion-helper/internal/config"
+	"github.com/ShadowZagrosDev/oblivion-helper/internal/ruleset"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetRulesetInfo handles the gRPC GetRulesetInfo request, describing the rule-set files managed by the export list:
+// source URL, size, hash, download time, whether they are missing or stale, the last download error,
+// and which rule-sets of the config use them.
+// Local rule-sets of the config whose file is not managed are listed too, so a start failing on a missing file can be explained.
+func (s *Server) GetRulesetInfo(ctx context.Context, req *pb.GetRulesetInfoRequest) (*pb.RulesetInfoResponse, error) {
+	exportConfig, err := ruleset.LoadExportConfig(filepath.Join(s.dirPath, exportListFileName), s.logger)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	// The config is loaded with the API overrides but without the bundled fallbacks, which replace the paths of missing files.
+	// Managed files are still listed if it cannot be loaded.
+	options, err := config.Load(s.configPath(), s.applyConfigOverrides)
+	if err != nil {
+		s.logger.warn.Printf("Ruleset references unavailable: %v", err)
+	}
+
+	referencing := make(map[string][]string) // Tags of the local rule-sets by file name
+	var unmanaged []ruleSetInfo
+	for _, info := range inspectRuleSets(options) {
+		if info.Type != "local" || info.Path == "" {
+			continue
+		}
+		name := filepath.Base(info.Path)
+		if _, managed := exportConfig.URLs[name]; !managed && len(referencing[name]) == 0 {
+			unmanaged = append(unmanaged, info)
+		}
+		referencing[name] = append(referencing[name], info.Tag)
+	}
+
+	response := &pb.RulesetInfoResponse{}
+	for _, file := range s.rulesetDownloader().Inspect(exportConfig) {
+		entry := &pb.RulesetFile{
+			Name:     file.Name,
+			Url:      file.URL,
+			Path:     file.Path,
+			Size:     file.Size,
+			Sha256:   file.SHA256,
+			Missing:  file.Missing,
+			Stale:    file.Stale,
+			RuleSets: referencing[file.Name],
+		}
+		if !file.Downloaded.IsZero() {
+			entry.DownloadedAt = file.Downloaded.Unix()
+		}
+		if file.LastError != nil {
+			entry.LastError = file.LastError.Err
+			entry.LastErrorAt = file.LastError.At.Unix()
+		}
+		response.Files = append(response.Files, describeReferences(entry))
+	}
+
+	for _, info := range unmanaged {
+		name := filepath.Base(info.Path)
+		entry := &pb.RulesetFile{Name: name, Path: info.Path, RuleSets: referencing[name]}
+		if fileInfo, err := os.Stat(info.Path); err == nil {
+			entry.Size = fileInfo.Size()
+			entry.DownloadedAt = fileInfo.ModTime().Unix()
+		} else {
+			entry.Missing = true
+		}
+		response.Files = append(response.Files, describeReferences(entry))
+	}
+	return response, nil
+}
+
+// describeReferences marks whether the config uses a file and, if it is missing, whether a bundled snapshot stands in
+func describeReferences(entry *pb.RulesetFile) *pb.RulesetFile {
+	entry.Referenced = len(entry.RuleSets) > 0
+	if entry.Missing {
+		for _, tag := range entry.RuleSets {
+			if _, found := ruleset.Fallback(tag); found {
+				entry.Fallback = true
+			}
+		}
+	}
+	return entry
+}
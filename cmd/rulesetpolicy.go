@@ -0,0 +1,61 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/ShadowZagrosDev/oblivion-helper/internal/ruleset"
+)
+
+// rulesetSources are the sources allowed by -ruleset-source in addition to the official ones
+type rulesetSources ruleset.Allowlist
+
+// String implements flag.Value
+func (r *rulesetSources) String() string {
+	var values []string
+	for _, source := range *r {
+		values = append(values, source.String())
+	}
+	return strings.Join(values, ",")
+}
+
+// Set implements flag.Value
+func (r *rulesetSources) Set(value string) error {
+	source, err := ruleset.ParseSource(value)
+	if err != nil {
+		return err
+	}
+	*r = append(*r, source)
+	return nil
+}
+
+// rulesetAllowlist returns the sources rulesets may be downloaded from. The export list is writable by the
+// desktop app, so without it any local process could have the root helper fetch arbitrary URLs to disk.
+func (s *Server) rulesetAllowlist() ruleset.Allowlist {
+	return slices.Concat(ruleset.DefaultAllowlist, ruleset.Allowlist(s.flags.RulesetSources))
+}
+
+// reportRejectedRulesets warns about the rulesets of the export list outside the allowlist, which are not downloaded
+func (s *Server) reportRejectedRulesets() {
+	rejected := s.rulesetAllowlist().Rejected(s.exportConfig)
+	if len(rejected) == 0 {
+		return
+	}
+	s.logger.warn.Printf("Not downloading rulesets from sources outside the allowlist: %s", strings.Join(rejected, ", "))
+	s.broadcastEvent("rulesets-rejected", map[string]string{"files": strings.Join(rejected, ",")})
+}
@@ -0,0 +1,170 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SplitTunnelRule sends the connections of an application to an outbound other than the tunnel's
+type SplitTunnelRule struct {
+	Process  string `json:"process"`            // Process name (chrome.exe) or full path of the executable
+	Outbound string `json:"outbound,omitempty"` // Outbound tag, empty for the direct outbound (excluded from the tunnel)
+}
+
+// routeRule returns the sing-box route rule of a split tunneling rule; a process containing
+// a path separator matches the executable path, otherwise its name
+func (r SplitTunnelRule) routeRule(directTag string) map[string]any {
+	rule := map[string]any{"outbound": r.Outbound}
+	if r.Outbound == "" {
+		rule["outbound"] = directTag
+	}
+	if strings.ContainsAny(r.Process, `/\`) {
+		rule["process_path"] = []any{r.Process}
+	} else {
+		rule["process_name"] = []any{r.Process}
+	}
+	return rule
+}
+
+// addSplitTunnelRules adds the route rules of the split tunneling rules ahead of the config's own rules
+func addSplitTunnelRules(config map[string]any, rules []SplitTunnelRule) {
+	var directTag string
+	added := make([]any, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Outbound == "" && directTag == "" {
+			directTag = directOutbound(config)
+		}
+		added = append(added, rule.routeRule(directTag))
+	}
+	insertRouteRules(config, added...)
+}
+
+// sameProcess reports whether two split tunneling rules target the same application
+func sameProcess(a, b string) bool {
+	return strings.EqualFold(filepath.Clean(a), filepath.Clean(b))
+}
+
+// updateSplitTunnel changes the persisted split tunneling rules and applies them by restarting sing-box if it is running.
+// If the restart fails, the previous rules are restored.
+func (s *Server) updateSplitTunnel(fn func(rules []SplitTunnelRule) []SplitTunnelRule) (*pb.SplitTunnelResponse, error) {
+	var rules []SplitTunnelRule
+	_, err := s.applyStateOverride(func(state *HelperState) func(*HelperState) {
+		previous := append([]SplitTunnelRule(nil), state.SplitTunnel...)
+		state.SplitTunnel = fn(state.SplitTunnel)
+		rules = state.SplitTunnel
+		return func(state *HelperState) { state.SplitTunnel = previous }
+	})
+	if err != nil {
+		return nil, err
+	}
+	return splitTunnelResponse(rules), nil
+}
+
+// splitTunnelResponse converts split tunneling rules to their gRPC form
+func splitTunnelResponse(rules []SplitTunnelRule) *pb.SplitTunnelResponse {
+	response := &pb.SplitTunnelResponse{}
+	for _, rule := range rules {
+		response.Rules = append(response.Rules, &pb.SplitTunnelRule{Process: rule.Process, Outbound: rule.Outbound})
+	}
+	return response
+}
+
+// AddSplitTunnelRule handles the gRPC AddSplitTunnelRule request, sending the connections of an application
+// (process name or executable path) directly, or to the given outbound, instead of through the tunnel.
+// Adding a rule for an application that has one replaces it. Rules are persisted and take precedence
+// over the config's rules.
+func (s *Server) AddSplitTunnelRule(ctx context.Context, req *pb.SplitTunnelRule) (*pb.SplitTunnelResponse, error) {
+	if strings.TrimSpace(req.Process) == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "process is required")
+	}
+	if req.Outbound != "" {
+		options, err := s.currentOptions()
+		if err != nil {
+			return nil, err
+		}
+		_, outbounds := inspectRoute(options)
+		found := false
+		for _, outbound := range outbounds {
+			found = found || outbound.Tag == req.Outbound
+		}
+		if !found {
+			return nil, status.Errorf(codes.NotFound, "outbound %s not found", req.Outbound)
+		}
+	}
+
+	rule := SplitTunnelRule{Process: strings.TrimSpace(req.Process), Outbound: req.Outbound}
+	response, err := s.updateSplitTunnel(func(rules []SplitTunnelRule) []SplitTunnelRule {
+		for i := range rules {
+			if sameProcess(rules[i].Process, rule.Process) {
+				rules[i] = rule
+				return rules
+			}
+		}
+		return append(rules, rule)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.logger.info.Printf("Split tunneling rule added for %s", rule.Process)
+	return response, nil
+}
+
+// RemoveSplitTunnelRule handles the gRPC RemoveSplitTunnelRule request, routing an application through the tunnel again
+func (s *Server) RemoveSplitTunnelRule(ctx context.Context, req *pb.RemoveSplitTunnelRuleRequest) (*pb.SplitTunnelResponse, error) {
+	state, err := s.loadState()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+	found := false
+	for _, rule := range state.SplitTunnel {
+		found = found || sameProcess(rule.Process, req.Process)
+	}
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "no split tunneling rule for %s", req.Process)
+	}
+
+	response, err := s.updateSplitTunnel(func(rules []SplitTunnelRule) []SplitTunnelRule {
+		kept := rules[:0]
+		for _, rule := range rules {
+			if !sameProcess(rule.Process, req.Process) {
+				kept = append(kept, rule)
+			}
+		}
+		return kept
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.logger.info.Printf("Split tunneling rule removed for %s", req.Process)
+	return response, nil
+}
+
+// ListSplitTunnelRules handles the gRPC ListSplitTunnelRules request
+func (s *Server) ListSplitTunnelRules(ctx context.Context, req *pb.ListSplitTunnelRulesRequest) (*pb.SplitTunnelResponse, error) {
+	state, err := s.loadState()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+	return splitTunnelResponse(state.SplitTunnel), nil
+}
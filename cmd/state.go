@@ -0,0 +1,136 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// stateFileName is the name of the file that stores the desired state between launches
+const stateFileName = "sbState.json"
+
+// HelperState holds the desired state of the helper, persisted across restarts
+type HelperState struct {
+	Connected bool      `json:"connected"`  // Whether the user wants sing-box to be running
+	Config    string    `json:"config"`     // Config file used for the connection
+	UpdatedAt time.Time `json:"updated_at"` // Time of the last change
+
+	ClashMode  string            `json:"clash_mode,omitempty"` // Clash mode chosen by the user
+	Selections map[string]string `json:"selections,omitempty"` // Selected outbound of each selector group
+
+	UsageThresholds []UsageThreshold       `json:"usage_thresholds,omitempty"` // Traffic caps that raise usage alerts
+	ConnectionLog   *ConnectionLogSettings `json:"connection_log,omitempty"`   // Opt-in connection logging
+	InboundPorts    map[string]int         `json:"inbound_ports,omitempty"`    // Listen port overrides by inbound tag
+	PAC             *PACSettings           `json:"pac,omitempty"`              // Proxy auto-config file server
+	PortMapping     *PortMappingSettings   `json:"port_mapping,omitempty"`     // Router port forwarding of listening inbounds
+	BindInterface   string                 `json:"bind_interface,omitempty"`   // Network interface outbound connections are bound to
+	DNSHijack       *bool                  `json:"dns_hijack,omitempty"`       // Whether port 53 is intercepted, nil to keep the config's rules
+	RoutingPreset   *RoutingPresetSettings `json:"routing_preset,omitempty"`   // Country-based rule-sets routed ahead of the config's rules
+	EndpointPool    *EndpointPoolSettings  `json:"endpoint_pool,omitempty"`    // WireGuard endpoints rotated through on failures
+	CacheFile       *bool                  `json:"cache_file,omitempty"`       // Whether sing-box keeps its cache file, nil to follow the config
+	Profile         string                 `json:"profile,omitempty"`          // Active profile in the profiles folder, empty for sbConfig.json
+	DNSServers      map[string]string      `json:"dns_servers,omitempty"`      // DNS server addresses by tag replacing the config's, "" for the default server
+	SplitTunnel     []SplitTunnelRule      `json:"split_tunnel,omitempty"`     // Applications routed outside the tunnel
+}
+
+// loadState reads the persisted state, returning an empty state if none was saved yet
+func (s *Server) loadState() (HelperState, error) {
+	var state HelperState
+
+	content, err := os.ReadFile(filepath.Join(s.dirPath, stateFileName))
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return state, fmt.Errorf("failed to read state: %w", err)
+	}
+
+	if err := json.Unmarshal(content, &state); err != nil {
+		return state, fmt.Errorf("failed to parse state: %w", err)
+	}
+	return state, nil
+}
+
+// updateState applies fn to the persisted state and writes it back to disk
+func (s *Server) updateState(fn func(state *HelperState)) error {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	state, err := s.loadState()
+	if err != nil {
+		s.logger.warn.Printf("Discarding unreadable state: %v", err)
+	}
+
+	fn(&state)
+	state.UpdatedAt = time.Now()
+
+	content, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	return writeFileAtomic(filepath.Join(s.dirPath, stateFileName), content)
+}
+
+// recordDesiredState persists whether the user wants sing-box to be connected
+func (s *Server) recordDesiredState(connected bool) {
+	err := s.updateState(func(state *HelperState) {
+		state.Connected = connected
+		state.Config = filepath.Base(s.configPath())
+	})
+	if err != nil {
+		s.logger.error.Printf("Failed to persist state: %v", err)
+	}
+}
+
+// resumeLastState restores the connection if it was active when the helper last ran
+func (s *Server) resumeLastState() {
+	state, err := s.loadState()
+	if err != nil {
+		s.logger.error.Printf("Failed to load last state: %v", err)
+		return
+	}
+
+	if !state.Connected {
+		s.logger.info.Println("Last state was disconnected, not resuming")
+		return
+	}
+
+	s.logger.info.Printf("Resuming connection with %s", state.Config)
+	if err := s.startSingBox(context.Background(), false); err != nil {
+		s.logger.error.Printf("Resume error: %v", err)
+	}
+}
+
+// writeFileAtomic writes content to a temporary file and renames it over path,
+// so readers never observe a partially written file
+func writeFileAtomic(path string, content []byte) error {
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
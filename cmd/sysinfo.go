@@ -0,0 +1,144 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	C "github.com/sagernet/sing-box/constant"
+)
+
+// defaultRouteProbe is dialled over UDP to learn which local address the OS routes internet traffic through.
+// Connecting a UDP socket only selects a route, no packet is sent.
+const defaultRouteProbe = "1.1.1.1:53"
+
+// defaultInterface returns the interface the OS currently routes internet traffic through.
+// While a TUN inbound with automatic routes is running, this is the TUN interface.
+func defaultInterface() (*pb.NetworkInterface, error) {
+	conn, err := net.Dial("udp", defaultRouteProbe)
+	if err != nil {
+		return nil, err
+	}
+	local := conn.LocalAddr().(*net.UDPAddr).AddrPort().Addr().Unmap()
+	conn.Close()
+
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, iface := range interfaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+
+		var matched bool
+		var addresses []string
+		for _, addr := range addrs {
+			prefix, err := netip.ParsePrefix(addr.String())
+			if err != nil {
+				continue
+			}
+			addresses = append(addresses, prefix.String())
+			if prefix.Addr().Unmap() == local {
+				matched = true
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		return &pb.NetworkInterface{
+			Name:         iface.Name,
+			Index:        int32(iface.Index),
+			Mtu:          int32(iface.MTU),
+			HardwareAddr: iface.HardwareAddr.String(),
+			Addresses:    addresses,
+		}, nil
+	}
+	return nil, nil
+}
+
+// coreModule is the module path of the embedded sing-box library
+const coreModule = "github.com/sagernet/sing-box"
+
+// coreVersion returns the version of the embedded sing-box library. The library's own version
+// constant is only set in sing-box release builds, so the module version recorded in the binary is preferred.
+func coreVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if dep.Path != coreModule {
+				continue
+			}
+			if dep.Replace != nil {
+				dep = dep.Replace
+			}
+			return dep.Version
+		}
+	}
+	return C.Version
+}
+
+// GetVersion handles the gRPC GetVersion request, reporting the helper and sing-box versions and the build target
+// so the client can check compatibility
+func (s *Server) GetVersion(ctx context.Context, req *pb.GetVersionRequest) (*pb.VersionResponse, error) {
+	return &pb.VersionResponse{
+		HelperVersion: Version,
+		CoreVersion:   coreVersion(),
+		GoVersion:     runtime.Version(),
+		Os:            runtime.GOOS,
+		Arch:          runtime.GOARCH,
+	}, nil
+}
+
+// Ping handles the gRPC Ping request. It answers at once, without waiting on any lock,
+// so the client can tell a live helper from a dead one quickly.
+func (s *Server) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingResponse, error) {
+	now := time.Now()
+	return &pb.PingResponse{
+		Timestamp:     now.UnixMilli(),
+		UptimeSeconds: int64(now.Sub(s.launchedAt).Seconds()),
+	}, nil
+}
+
+// GetSystemInfo handles the gRPC GetSystemInfo request, describing the environment the helper runs in
+func (s *Server) GetSystemInfo(ctx context.Context, req *pb.GetSystemInfoRequest) (*pb.SystemInfoResponse, error) {
+	resp := &pb.SystemInfoResponse{
+		Os:            runtime.GOOS,
+		OsVersion:     osVersion(),
+		Arch:          runtime.GOARCH,
+		Elevated:      s.elevated,
+		HelperVersion: Version,
+		GoVersion:     runtime.Version(),
+		CoreVersion:   coreVersion(),
+	}
+	resp.TunDriver, resp.TunAvailable = tunDriver()
+
+	iface, err := defaultInterface()
+	if err != nil {
+		s.logger.warn.Printf("Failed to detect the default interface: %v", err)
+	}
+	resp.DefaultInterface = iface
+	return resp, nil
+}
@@ -0,0 +1,39 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// osVersion returns the macOS product version and build
+func osVersion() string {
+	version, err := exec.Command("sw_vers", "-productVersion").Output()
+	if err != nil {
+		return ""
+	}
+	build, err := exec.Command("sw_vers", "-buildVersion").Output()
+	if err != nil {
+		return "macOS " + strings.TrimSpace(string(version))
+	}
+	return "macOS " + strings.TrimSpace(string(version)) + " (" + strings.TrimSpace(string(build)) + ")"
+}
+
+// tunDriver reports the utun kernel interface, which is always present on macOS
+func tunDriver() (string, bool) {
+	return "utun", true
+}
@@ -0,0 +1,57 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// osVersion returns the distribution name and kernel release
+func osVersion() string {
+	var version string
+	if content, err := os.ReadFile("/etc/os-release"); err == nil {
+		scanner := bufio.NewScanner(bytes.NewReader(content))
+		for scanner.Scan() {
+			if name, ok := strings.CutPrefix(scanner.Text(), "PRETTY_NAME="); ok {
+				version = strings.Trim(name, `"`)
+				break
+			}
+		}
+	}
+
+	var uname unix.Utsname
+	if err := unix.Uname(&uname); err == nil {
+		release := unix.ByteSliceToString(uname.Release[:])
+		if version == "" {
+			return "Linux " + release
+		}
+		return version + " (kernel " + release + ")"
+	}
+	return version
+}
+
+// tunDriver reports whether the TUN device node is available
+func tunDriver() (string, bool) {
+	if _, err := os.Stat("/dev/net/tun"); err != nil {
+		return "tun", false
+	}
+	return "tun", true
+}
@@ -0,0 +1,42 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// osVersion returns the Windows version and build number
+func osVersion() string {
+	info := windows.RtlGetVersion()
+	return fmt.Sprintf("Windows %d.%d (build %d)", info.MajorVersion, info.MinorVersion, info.BuildNumber)
+}
+
+// tunDriver reports the Wintun driver, which sing-box embeds and installs when the adapter is first created.
+// An installed driver older than the embedded library is reported as unavailable until it is repaired.
+func tunDriver() (string, bool) {
+	version, err := wintunDriverVersion()
+	if err != nil {
+		return "wintun (embedded, not installed yet)", true
+	}
+	driver := fmt.Sprintf("wintun %d.%d.%d", version[0], version[1], version[2])
+	if wintunOutdated(version) {
+		return driver + " (outdated)", false
+	}
+	return driver, true
+}
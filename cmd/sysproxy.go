@@ -0,0 +1,129 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// systemProxyState is the system proxy set through SetSystemProxy
+type systemProxyState struct {
+	inbound string // Tag of the inbound the system proxy points at, empty if not requested
+	address string // Address currently set in the OS settings, empty while not applied
+}
+
+// systemProxyInbound returns the inbound the system proxy should point at: the given tag,
+// or the first mixed or http inbound
+func systemProxyInbound(inbounds []inboundInfo, tag string) (inboundInfo, error) {
+	for _, inbound := range inbounds {
+		if tag != "" && inbound.Tag != tag {
+			continue
+		}
+		if inbound.Type == "mixed" || inbound.Type == "http" {
+			return inbound, nil
+		}
+		if tag != "" {
+			return inboundInfo{}, fmt.Errorf("inbound %s is a %s inbound, expected mixed or http", tag, inbound.Type)
+		}
+	}
+	if tag != "" {
+		return inboundInfo{}, fmt.Errorf("inbound %s not found", tag)
+	}
+	return inboundInfo{}, fmt.Errorf("the config has no mixed or http inbound")
+}
+
+// applySystemProxy points the OS proxy settings at the requested inbound of the running instance;
+// the caller must hold s.mu
+func (s *Server) applySystemProxy() error {
+	if s.sysProxy.inbound == "" {
+		return nil
+	}
+
+	inbound, err := systemProxyInbound(inspectInbounds(s.core.Options()), s.sysProxy.inbound)
+	if err != nil {
+		return err
+	}
+	address := inbound.localAddress()
+	host, port, _ := net.SplitHostPort(address)
+	portNumber, _ := strconv.Atoi(port)
+
+	s.writeJournal(JournalEntry{Time: time.Now(), State: "started", Changes: []string{changeSystemProxy}})
+	if err := setSystemProxy(host, portNumber, inbound.Type == "mixed"); err != nil {
+		return fmt.Errorf("failed to set the system proxy: %w", err)
+	}
+	s.sysProxy.address = address
+	s.logger.info.Printf("System proxy set to %s", address)
+	return nil
+}
+
+// revertSystemProxy removes the OS proxy settings made by applySystemProxy; the caller must hold s.mu
+func (s *Server) revertSystemProxy() {
+	if s.sysProxy.address == "" {
+		return
+	}
+	if err := clearSystemProxy(); err != nil {
+		s.logger.error.Printf("Failed to clear the system proxy: %v", err)
+		return
+	}
+	s.sysProxy.address = ""
+	s.logger.info.Println("System proxy cleared")
+}
+
+// SetSystemProxy handles the gRPC SetSystemProxy request, pointing the OS proxy settings at a mixed or http inbound
+// of the running instance (the first one unless tagged). The setting follows reloads and restarts, is removed while
+// sing-box is stopped, and stays requested until ClearSystemProxy.
+func (s *Server) SetSystemProxy(ctx context.Context, req *pb.SetSystemProxyRequest) (*pb.SystemProxyResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.core.Running() {
+		return nil, status.Errorf(codes.FailedPrecondition, "sing-box is not running")
+	}
+	inbound, err := systemProxyInbound(inspectInbounds(s.core.Options()), req.Inbound)
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	}
+
+	s.revertSystemProxy()
+	s.sysProxy.inbound = inbound.Tag
+	if err := s.applySystemProxy(); err != nil {
+		s.sysProxy = systemProxyState{}
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+	return &pb.SystemProxyResponse{Enabled: true, Inbound: inbound.Tag, Address: s.sysProxy.address}, nil
+}
+
+// ClearSystemProxy handles the gRPC ClearSystemProxy request, removing the OS proxy settings made by SetSystemProxy
+func (s *Server) ClearSystemProxy(ctx context.Context, req *pb.ClearSystemProxyRequest) (*pb.SystemProxyResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.revertSystemProxy()
+	if s.sysProxy.address != "" {
+		return nil, status.Errorf(codes.Internal, "failed to clear the system proxy")
+	}
+	s.sysProxy = systemProxyState{}
+	return &pb.SystemProxyResponse{}, nil
+}
@@ -0,0 +1,111 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// clearSystemProxy disables the HTTP, HTTPS and SOCKS proxies of every network service
+func clearSystemProxy() error {
+	services, err := networkServices()
+	if err != nil {
+		return err
+	}
+
+	for _, service := range services {
+		for _, flag := range []string{"-setwebproxystate", "-setsecurewebproxystate", "-setsocksfirewallproxystate"} {
+			if output, err := exec.Command("networksetup", flag, service, "off").CombinedOutput(); err != nil {
+				return fmt.Errorf("networksetup %s %s: %w: %s", flag, service, err, strings.TrimSpace(string(output)))
+			}
+		}
+	}
+	return nil
+}
+
+// setSystemProxy points the HTTP and HTTPS proxies of every network service, and the SOCKS proxy if socks is set,
+// at host:port
+func setSystemProxy(host string, port int, socks bool) error {
+	services, err := networkServices()
+	if err != nil {
+		return err
+	}
+
+	flags := []string{"-setwebproxy", "-setsecurewebproxy"}
+	if socks {
+		flags = append(flags, "-setsocksfirewallproxy")
+	}
+	for _, service := range services {
+		for _, flag := range flags {
+			if output, err := exec.Command("networksetup", flag, service, host, strconv.Itoa(port)).CombinedOutput(); err != nil {
+				return fmt.Errorf("networksetup %s %s: %w: %s", flag, service, err, strings.TrimSpace(string(output)))
+			}
+		}
+	}
+	return nil
+}
+
+// setAutoProxy sets the proxy auto-config URL of every network service
+func setAutoProxy(url string) error {
+	services, err := networkServices()
+	if err != nil {
+		return err
+	}
+
+	for _, service := range services {
+		if output, err := exec.Command("networksetup", "-setautoproxyurl", service, url).CombinedOutput(); err != nil {
+			return fmt.Errorf("networksetup -setautoproxyurl %s: %w: %s", service, err, strings.TrimSpace(string(output)))
+		}
+	}
+	return nil
+}
+
+// clearAutoProxy disables the proxy auto-config of every network service
+func clearAutoProxy() error {
+	services, err := networkServices()
+	if err != nil {
+		return err
+	}
+
+	for _, service := range services {
+		if output, err := exec.Command("networksetup", "-setautoproxystate", service, "off").CombinedOutput(); err != nil {
+			return fmt.Errorf("networksetup -setautoproxystate %s: %w: %s", service, err, strings.TrimSpace(string(output)))
+		}
+	}
+	return nil
+}
+
+// networkServices lists the network services known to networksetup
+func networkServices() ([]string, error) {
+	output, err := exec.Command("networksetup", "-listallnetworkservices").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list network services: %w", err)
+	}
+
+	// The first line is an explanatory header and disabled services are prefixed with '*'
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	var services []string
+	for _, line := range lines[1:] {
+		service := strings.TrimPrefix(strings.TrimSpace(line), "*")
+		if service != "" {
+			services = append(services, service)
+		}
+	}
+	return services, nil
+}
@@ -0,0 +1,296 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Schemas of the GNOME proxy settings
+const (
+	proxySchema      = "org.gnome.system.proxy"
+	httpProxySchema  = "org.gnome.system.proxy.http"
+	httpsProxySchema = "org.gnome.system.proxy.https"
+	socksProxySchema = "org.gnome.system.proxy.socks"
+)
+
+// proxyEnvironmentFile is written to the environment.d folder of the desktop user, so the sessions started
+// afterwards and the programs that ignore the GNOME settings get the system proxy from their environment
+const proxyEnvironmentFile = "90-oblivion-proxy.conf"
+
+// gsetting is a GNOME setting and its value, in the GVariant text format used by gsettings
+type gsetting struct {
+	schema, key, value string
+}
+
+// gnomeProxy tracks the GNOME proxy settings changed by the system proxy and the auto-proxy, which share the mode
+var gnomeProxy struct {
+	sync.Mutex
+	saved  []gsetting // Settings before the first change, restored once neither feature uses them
+	manual bool       // Whether the system proxy is set
+	auto   bool       // Whether the auto-proxy is set
+}
+
+// clearSystemProxy removes the proxy environment of the desktop user and restores the GNOME proxy settings changed by
+// setSystemProxy, keeping the auto-proxy if it is set
+func clearSystemProxy() error {
+	gnomeProxy.Lock()
+	defer gnomeProxy.Unlock()
+
+	err := removeProxyEnvironment()
+	if _, lookErr := exec.LookPath("gsettings"); lookErr != nil {
+		return err // No desktop proxy settings to revert
+	}
+	gnomeProxy.manual = false
+	return errors.Join(err, restoreGnomeProxy())
+}
+
+// setSystemProxy points the GNOME HTTP and HTTPS proxies of the desktop user, and the SOCKS proxy if socks is set,
+// at host:port, and writes them to the proxy environment of the user
+func setSystemProxy(host string, port int, socks bool) error {
+	gnomeProxy.Lock()
+	defer gnomeProxy.Unlock()
+
+	if err := writeProxyEnvironment(host, port, socks); err != nil {
+		return err
+	}
+	if _, err := exec.LookPath("gsettings"); err != nil {
+		return nil // The environment is the only proxy setting available
+	}
+	if err := saveGnomeProxy(); err != nil {
+		return err
+	}
+
+	schemas := []string{httpProxySchema, httpsProxySchema}
+	if socks {
+		schemas = append(schemas, socksProxySchema)
+	}
+	for _, schema := range schemas {
+		if err := setGSetting(schema, "host", host); err != nil {
+			return errors.Join(err, restoreGnomeProxy())
+		}
+		if err := setGSetting(schema, "port", strconv.Itoa(port)); err != nil {
+			return errors.Join(err, restoreGnomeProxy())
+		}
+	}
+	if err := setGSetting(proxySchema, "mode", "manual"); err != nil {
+		return errors.Join(err, restoreGnomeProxy())
+	}
+	gnomeProxy.manual = true
+	return nil
+}
+
+// setAutoProxy points the GNOME proxy settings of the desktop user at a proxy auto-config URL. The mode stays manual
+// while the system proxy is set and switches to the auto-config once it is cleared.
+func setAutoProxy(url string) error {
+	if _, err := exec.LookPath("gsettings"); err != nil {
+		return fmt.Errorf("no supported desktop proxy settings found")
+	}
+
+	gnomeProxy.Lock()
+	defer gnomeProxy.Unlock()
+
+	if err := saveGnomeProxy(); err != nil {
+		return err
+	}
+	if err := setGSetting(proxySchema, "autoconfig-url", url); err != nil {
+		return errors.Join(err, restoreGnomeProxy())
+	}
+	if !gnomeProxy.manual {
+		if err := setGSetting(proxySchema, "mode", "auto"); err != nil {
+			return errors.Join(err, restoreGnomeProxy())
+		}
+	}
+	gnomeProxy.auto = true
+	return nil
+}
+
+// clearAutoProxy restores the GNOME proxy settings changed by setAutoProxy, keeping the system proxy if it is set
+func clearAutoProxy() error {
+	if _, err := exec.LookPath("gsettings"); err != nil {
+		return nil // No desktop proxy settings to revert
+	}
+
+	gnomeProxy.Lock()
+	defer gnomeProxy.Unlock()
+
+	gnomeProxy.auto = false
+	return restoreGnomeProxy()
+}
+
+// saveGnomeProxy records the GNOME proxy settings of the desktop user before the first change;
+// the caller must hold gnomeProxy
+func saveGnomeProxy() error {
+	if gnomeProxy.saved != nil {
+		return nil
+	}
+
+	settings := []gsetting{{proxySchema, "mode", ""}, {proxySchema, "autoconfig-url", ""}}
+	for _, schema := range []string{httpProxySchema, httpsProxySchema, socksProxySchema} {
+		settings = append(settings, gsetting{schema, "host", ""}, gsetting{schema, "port", ""})
+	}
+	for i := range settings {
+		value, err := runAsDesktopUser("gsettings", "get", settings[i].schema, settings[i].key)
+		if err != nil {
+			return err
+		}
+		settings[i].value = strings.TrimSpace(value)
+	}
+	gnomeProxy.saved = settings
+	return nil
+}
+
+// restoreGnomeProxy restores the saved GNOME proxy settings that neither the system proxy nor the auto-proxy still
+// uses. Without saved settings, as when repairing after a crash, the proxy mode is reset to none.
+// The caller must hold gnomeProxy.
+func restoreGnomeProxy() error {
+	if gnomeProxy.saved == nil {
+		if gnomeProxy.manual || gnomeProxy.auto {
+			return nil
+		}
+		return setGSetting(proxySchema, "mode", "none")
+	}
+
+	var errs []error
+	for _, setting := range gnomeProxy.saved {
+		usedByManual := gnomeProxy.manual && (setting.schema != proxySchema || setting.key == "mode")
+		usedByAuto := gnomeProxy.auto && setting.schema == proxySchema
+		if usedByManual || usedByAuto {
+			continue
+		}
+		errs = append(errs, setGSetting(setting.schema, setting.key, setting.value))
+	}
+	if gnomeProxy.auto && !gnomeProxy.manual {
+		errs = append(errs, setGSetting(proxySchema, "mode", "auto"))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	if !gnomeProxy.manual && !gnomeProxy.auto {
+		gnomeProxy.saved = nil
+	}
+	return nil
+}
+
+// setGSetting changes a GNOME setting of the desktop user
+func setGSetting(schema, key, value string) error {
+	_, err := runAsDesktopUser("gsettings", "set", schema, key, value)
+	return err
+}
+
+// writeProxyEnvironment writes the proxy variables pointing at host:port to the environment.d folder of the desktop user
+func writeProxyEnvironment(host string, port int, socks bool) error {
+	account, err := desktopAccount()
+	if err != nil {
+		return err
+	}
+
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	variables := map[string]string{"http_proxy": "http://" + address, "https_proxy": "http://" + address}
+	if socks {
+		variables["all_proxy"] = "socks5://" + address
+	}
+	variables["no_proxy"] = "localhost,127.0.0.0/8,::1"
+
+	var content strings.Builder
+	for _, name := range []string{"http_proxy", "https_proxy", "all_proxy", "no_proxy"} {
+		if value, ok := variables[name]; ok {
+			fmt.Fprintf(&content, "%s=%s\n%s=%s\n", name, value, strings.ToUpper(name), value)
+		}
+	}
+
+	dir := filepath.Join(account.HomeDir, ".config", "environment.d")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	path := filepath.Join(dir, proxyEnvironmentFile)
+	if err := writeFileAtomic(path, []byte(content.String())); err != nil {
+		return err
+	}
+	if os.Geteuid() == 0 {
+		uid, _ := strconv.Atoi(account.Uid)
+		gid, _ := strconv.Atoi(account.Gid)
+		if err := os.Chown(path, uid, gid); err != nil {
+			return fmt.Errorf("failed to hand %s to %s: %w", path, account.Username, err)
+		}
+	}
+	return nil
+}
+
+// removeProxyEnvironment removes the proxy variables written by writeProxyEnvironment
+func removeProxyEnvironment() error {
+	account, err := desktopAccount()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(account.HomeDir, ".config", "environment.d", proxyEnvironmentFile)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+	return nil
+}
+
+// desktopAccount returns the user whose desktop proxy settings are changed: the user who started the helper through
+// sudo or pkexec while it runs as root, the helper's own user otherwise, as after dropping privileges
+func desktopAccount() (*user.User, error) {
+	if os.Geteuid() != 0 {
+		return user.Current()
+	}
+	account, err := invokingAccount()
+	if err != nil {
+		return nil, fmt.Errorf("no desktop user to change the proxy settings of: %w", err)
+	}
+	return account, nil
+}
+
+// runAsDesktopUser runs a command in the session of the desktop user, since desktop proxy settings are stored per user,
+// and returns its output
+func runAsDesktopUser(name string, args ...string) (string, error) {
+	account, err := desktopAccount()
+	if err != nil {
+		return "", err
+	}
+
+	cmd := exec.Command(name, args...)
+	if os.Geteuid() == 0 {
+		release, err := runAsUser(cmd, account.Uid)
+		if err != nil {
+			return "", err
+		}
+		defer release()
+		runtimeDir := "/run/user/" + account.Uid
+		cmd.Env = append(cmd.Env, "XDG_RUNTIME_DIR="+runtimeDir, "DBUS_SESSION_BUS_ADDRESS=unix:path="+runtimeDir+"/bus")
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf("%s: %w: %s", name, err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return string(output), nil
+}
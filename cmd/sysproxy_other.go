@@ -0,0 +1,40 @@
+//go:build !windows && !darwin && !linux
+
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import "errors"
+
+// clearSystemProxy is a no-op on platforms without system proxy support
+func clearSystemProxy() error {
+	return nil
+}
+
+// setSystemProxy is not supported on this platform
+func setSystemProxy(host string, port int, socks bool) error {
+	return errors.New("system proxy is not supported on this platform")
+}
+
+// setAutoProxy is not supported on this platform
+func setAutoProxy(url string) error {
+	return errors.New("system auto-proxy is not supported on this platform")
+}
+
+// clearAutoProxy is a no-op on platforms without system proxy support
+func clearAutoProxy() error {
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/sagernet/sing/common/wininet"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// internetSettingsKey is the registry key of the per-user WinINet settings
+const internetSettingsKey = `Software\Microsoft\Windows\CurrentVersion\Internet Settings`
+
+// InternetSetOption options that make running applications reload the proxy settings
+const (
+	internetOptionRefresh         = 37
+	internetOptionSettingsChanged = 39
+)
+
+var (
+	wininetDLL             = windows.NewLazySystemDLL("wininet.dll")
+	procInternetSetOptionW = wininetDLL.NewProc("InternetSetOptionW")
+)
+
+// clearSystemProxy disables the WinINet proxy settings
+func clearSystemProxy() error {
+	return wininet.ClearSystemProxy()
+}
+
+// setSystemProxy points the WinINet proxy at host:port, bypassing local addresses.
+// WinINet uses a single proxy for every protocol, so socks makes no difference.
+func setSystemProxy(host string, port int, socks bool) error {
+	return wininet.SetSystemProxy("http://"+net.JoinHostPort(host, strconv.Itoa(port)), "<local>")
+}
+
+// setAutoProxy sets the WinINet proxy auto-config URL
+func setAutoProxy(url string) error {
+	key, _, err := registry.CreateKey(registry.CURRENT_USER, internetSettingsKey, registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("failed to open internet settings: %w", err)
+	}
+	defer key.Close()
+
+	if err := key.SetStringValue("AutoConfigURL", url); err != nil {
+		return fmt.Errorf("failed to set AutoConfigURL: %w", err)
+	}
+	refreshInternetSettings()
+	return nil
+}
+
+// clearAutoProxy removes the WinINet proxy auto-config URL
+func clearAutoProxy() error {
+	key, err := registry.OpenKey(registry.CURRENT_USER, internetSettingsKey, registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("failed to open internet settings: %w", err)
+	}
+	defer key.Close()
+
+	if err := key.DeleteValue("AutoConfigURL"); err != nil && !errors.Is(err, registry.ErrNotExist) {
+		return fmt.Errorf("failed to remove AutoConfigURL: %w", err)
+	}
+	refreshInternetSettings()
+	return nil
+}
+
+// refreshInternetSettings notifies running applications that the proxy settings changed
+func refreshInternetSettings() {
+	procInternetSetOptionW.Call(0, internetOptionSettingsChanged, 0, 0)
+	procInternetSetOptionW.Call(0, internetOptionRefresh, 0, 0)
+}
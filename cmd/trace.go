@@ -0,0 +1,141 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	"github.com/sagernet/sing-box/adapter"
+	"github.com/sagernet/sing-box/common/process"
+	"github.com/sagernet/sing-box/log"
+	"github.com/sagernet/sing-box/route"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// dnsDecision is the outcome of matching a domain lookup against the DNS rules of the running instance
+type dnsDecision struct {
+	ruleIndex int    // Index of the matching DNS rule, -1 when the final server is used
+	rule      string // Description of the matching DNS rule
+	server    string
+}
+
+// decideDNS matches the lookup of a domain against the DNS rules of the running instance.
+// The rules are rebuilt from the options, since the router does not expose its DNS rules.
+// The caller must hold s.mu.
+func (s *Server) decideDNS(newMetadata func() *adapter.InboundContext) (dnsDecision, error) {
+	decision := dnsDecision{ruleIndex: -1}
+	dnsOptions := s.core.Options().DNS
+	if dnsOptions == nil {
+		return decision, nil
+	}
+
+	router := s.core.Instance().Router()
+	logger := log.NewNOPFactory().Logger()
+	for i, ruleOptions := range dnsOptions.Rules {
+		rule, err := route.NewDNSRule(router, logger, ruleOptions, false)
+		if err != nil {
+			return decision, fmt.Errorf("failed to build DNS rule %d: %w", i, err)
+		}
+		if err := rule.Start(); err != nil {
+			rule.Close()
+			return decision, fmt.Errorf("failed to start DNS rule %d: %w", i, err)
+		}
+		matched := rule.Match(newMetadata())
+		if matched {
+			decision.ruleIndex = i
+			decision.rule = rule.String()
+			decision.server = rule.Outbound()
+		}
+		rule.Close()
+		if matched {
+			return decision, nil
+		}
+	}
+
+	decision.server = dnsOptions.Final
+	if decision.server == "" && len(dnsOptions.Servers) > 0 {
+		decision.server = dnsOptions.Servers[0].Tag
+	}
+	return decision, nil
+}
+
+// TraceRoute handles the gRPC TraceRoute request, simulating a connection through the live rule engine.
+// It reports the DNS server the destination domain would be resolved with, and the rule and outbound
+// the connection would be routed through, without sending any traffic.
+func (s *Server) TraceRoute(ctx context.Context, req *pb.TraceRouteRequest) (*pb.TraceRouteResponse, error) {
+	port := req.Port
+	if port == 0 {
+		port = lookupPort
+	}
+	if port > 65535 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid port %d", port)
+	}
+
+	network := strings.ToLower(req.Protocol)
+	switch network {
+	case "":
+		network = "tcp"
+	case "tcp", "udp":
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported protocol %q, expected tcp or udp", req.Protocol)
+	}
+
+	metadata, err := lookupMetadata(req.Destination, uint16(port), network)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	if req.Process != "" {
+		metadata.ProcessInfo = &process.Info{ProcessPath: req.Process}
+	}
+	newMetadata := func() *adapter.InboundContext {
+		copied := *metadata
+		return &copied
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.core.Running() {
+		return nil, status.Errorf(codes.FailedPrecondition, "sing-box is not running")
+	}
+
+	response := &pb.TraceRouteResponse{DnsRuleIndex: -1}
+	if metadata.Domain != "" {
+		dns, err := s.decideDNS(newMetadata)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "%v", err)
+		}
+		response.DnsServer = dns.server
+		response.DnsRule = dns.rule
+		response.DnsRuleIndex = int32(dns.ruleIndex)
+	}
+
+	decision, err := s.decideRoute(newMetadata)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+	response.RuleSets = decision.ruleSets
+	response.Rule = decision.rule
+	response.RuleIndex = int32(decision.ruleIndex)
+	response.Outbound = decision.outbound
+	return response, nil
+}
@@ -0,0 +1,94 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+)
+
+// Intervals between the speed samples of StreamTraffic
+const (
+	trafficSampleInterval    = time.Second            // Used when the client gives no interval
+	trafficSampleMinInterval = 250 * time.Millisecond // The speeds are computed once per second, so faster samples repeat values
+)
+
+// GetStats handles the gRPC GetStats request, returning the bytes uploaded and downloaded in the current session
+func (s *Server) GetStats(ctx context.Context, req *pb.GetStatsRequest) (*pb.StatsResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	server, err := s.clashServer()
+	if err != nil {
+		return nil, err
+	}
+	upload, download := server.TrafficManager().Total()
+	uptime, _, _, _ := s.reliability.snapshot()
+	return &pb.StatsResponse{
+		Upload:        upload,
+		Download:      download,
+		UptimeSeconds: int64(uptime.Seconds()),
+	}, nil
+}
+
+// StreamTraffic handles the gRPC StreamTraffic request, sending the current upload and download speed
+// every second, or at the requested interval, while sing-box is running, so the client can draw a live graph
+// without polling. Samples pause while sing-box is stopped and resume when it starts again.
+// Unlike StreamStatus, closing this stream does not stop sing-box.
+func (s *Server) StreamTraffic(req *pb.TrafficRequest, stream pb.OblivionService_StreamTrafficServer) error {
+	interval := trafficSampleInterval
+	if req.IntervalMs > 0 {
+		interval = max(time.Duration(req.IntervalMs)*time.Millisecond, trafficSampleMinInterval)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+
+		case now := <-ticker.C:
+			s.mu.RLock()
+			server, err := s.clashServer()
+			var sample *pb.TrafficSample
+			if err == nil {
+				manager := server.TrafficManager()
+				upSpeed, downSpeed := manager.Now()
+				upTotal, downTotal := manager.Total()
+				sample = &pb.TrafficSample{
+					Timestamp:     now.UnixMilli(),
+					UploadSpeed:   upSpeed,
+					DownloadSpeed: downSpeed,
+					UploadTotal:   upTotal,
+					DownloadTotal: downTotal,
+				}
+			}
+			s.mu.RUnlock()
+			if sample == nil {
+				continue // Not connected
+			}
+
+			if err := stream.Send(sample); err != nil {
+				s.logger.error.Printf("Traffic stream error: %v", err)
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"strings"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// tunCreationFailed reports whether sing-box failed to start because the TUN interface could not be created
+func tunCreationFailed(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "configure tun interface")
+}
+
+// RepairTunDriver handles the gRPC RepairTunDriver request, reinstalling or loading the TUN driver.
+// On Windows the installed Wintun driver is removed so the copy embedded in sing-box is installed
+// again when the adapter is next created; on Linux the tun kernel module is loaded.
+func (s *Server) RepairTunDriver(ctx context.Context, req *pb.RepairTunDriverRequest) (*pb.RepairTunDriverResponse, error) {
+	s.mu.RLock()
+	running := s.core.Running()
+	s.mu.RUnlock()
+	if running {
+		return nil, status.Errorf(codes.FailedPrecondition, "stop sing-box before repairing the TUN driver")
+	}
+
+	if err := repairTunDriver(); err != nil {
+		s.logger.error.Printf("TUN driver repair failed: %v", err)
+		return nil, status.Errorf(codes.Internal, "TUN driver repair failed: %v", err)
+	}
+
+	driver, available := tunDriver()
+	s.logger.info.Printf("TUN driver repaired: %s", driver)
+	return &pb.RepairTunDriverResponse{TunDriver: driver, TunAvailable: available}, nil
+}
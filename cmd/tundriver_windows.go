@@ -0,0 +1,89 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// wintunMinVersion is the oldest Wintun driver (major, minor) the embedded library works with
+var wintunMinVersion = [2]uint32{0, 14}
+
+// oemInfPattern matches the published name of a driver package in the pnputil output, which is otherwise localized
+var oemInfPattern = regexp.MustCompile(`(?i)\boem\d+\.inf\b`)
+
+// wintunDriverVersion returns the version of the installed Wintun driver as major, minor, patch
+func wintunDriverVersion() ([3]uint32, error) {
+	path := filepath.Join(os.Getenv("SystemRoot"), "System32", "drivers", "wintun.sys")
+
+	size, err := windows.GetFileVersionInfoSize(path, nil)
+	if err != nil {
+		return [3]uint32{}, err
+	}
+	buffer := make([]byte, size)
+	if err := windows.GetFileVersionInfo(path, 0, size, unsafe.Pointer(&buffer[0])); err != nil {
+		return [3]uint32{}, err
+	}
+
+	var info *windows.VS_FIXEDFILEINFO
+	var length uint32
+	if err := windows.VerQueryValue(unsafe.Pointer(&buffer[0]), `\`, unsafe.Pointer(&info), &length); err != nil {
+		return [3]uint32{}, err
+	}
+	return [3]uint32{info.FileVersionMS >> 16, info.FileVersionMS & 0xffff, info.FileVersionLS >> 16}, nil
+}
+
+// wintunOutdated reports whether an installed driver is older than the embedded library expects
+func wintunOutdated(version [3]uint32) bool {
+	return version[0] < wintunMinVersion[0] || (version[0] == wintunMinVersion[0] && version[1] < wintunMinVersion[1])
+}
+
+// repairTunDriver removes the installed Wintun driver packages, so the driver embedded in sing-box
+// is installed again, cleanly and at the expected version, when the adapter is next created
+func repairTunDriver() error {
+	output, err := exec.Command("pnputil", "/enum-drivers").Output()
+	if err != nil {
+		return fmt.Errorf("failed to list driver packages: %w", err)
+	}
+
+	// Each package is a block of lines; the published name precedes the original name
+	var packages []string
+	for _, block := range strings.Split(strings.ReplaceAll(string(output), "\r\n", "\n"), "\n\n") {
+		if strings.Contains(strings.ToLower(block), "wintun.inf") {
+			if published := oemInfPattern.FindString(block); published != "" {
+				packages = append(packages, published)
+			}
+		}
+	}
+	if len(packages) == 0 {
+		return nil // Nothing installed; the driver is installed when the adapter is created
+	}
+
+	for _, published := range packages {
+		if output, err := exec.Command("pnputil", "/delete-driver", published, "/uninstall", "/force").CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to remove %s: %v: %s", published, err, strings.TrimSpace(string(output)))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"sync"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	"github.com/sagernet/sing-box/adapter"
+	C "github.com/sagernet/sing-box/constant"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// urlTestConcurrency is the number of outbounds URLTest tests at the same time
+const urlTestConcurrency = 8
+
+// URLTest handles the gRPC URLTest request, measuring the latency of the members of a group or, without a group,
+// of every outbound that is not itself a group. Results are sorted from fastest to slowest, failed ones last.
+func (s *Server) URLTest(ctx context.Context, req *pb.URLTestRequest) (*pb.URLTestResponse, error) {
+	target := req.Url
+	if target == "" {
+		target = healthCheckURL
+	}
+	if parsed, err := url.Parse(target); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid URL %q, expected an http or https URL", target)
+	}
+
+	s.mu.RLock()
+	running := s.core.Running()
+	instance := s.core.Instance()
+	s.mu.RUnlock()
+	if !running {
+		return nil, status.Errorf(codes.FailedPrecondition, "sing-box is not running")
+	}
+
+	var tags []string
+	if req.Group != "" {
+		detour, loaded := instance.Router().Outbound(req.Group)
+		if !loaded {
+			return nil, status.Errorf(codes.NotFound, "outbound group %s not found", req.Group)
+		}
+		group, isGroup := detour.(adapter.OutboundGroup)
+		if !isGroup {
+			return nil, status.Errorf(codes.InvalidArgument, "outbound %s is not a group", req.Group)
+		}
+		tags = group.All()
+	} else {
+		for _, detour := range instance.Router().Outbounds() {
+			if _, isGroup := detour.(adapter.OutboundGroup); isGroup {
+				continue
+			}
+			if detour.Type() == C.TypeBlock || detour.Type() == C.TypeDNS {
+				continue // Never reach the URL
+			}
+			tags = append(tags, detour.Tag())
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	results := make([]*pb.URLTestResult, len(tags))
+	slots := make(chan struct{}, urlTestConcurrency)
+	var wg sync.WaitGroup
+	for i, tag := range tags {
+		wg.Add(1)
+		s.cleanup.Go(func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			result := &pb.URLTestResult{Outbound: tag}
+			if delay, err := testOutboundURL(ctx, instance, tag, target); err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Ok = true
+				result.LatencyMs = int64(delay)
+			}
+			results[i] = result
+		})
+	}
+	wg.Wait()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Ok != results[j].Ok {
+			return results[i].Ok
+		}
+		return results[i].LatencyMs < results[j].LatencyMs
+	})
+	return &pb.URLTestResponse{Url: target, Results: results}, nil
+}
@@ -0,0 +1,382 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	"go.etcd.io/bbolt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Usage statistics settings
+const (
+	usageFileName       = "sbUsage.db"     // Name of the usage database
+	usageSampleInterval = 10 * time.Second // How often traffic counters are flushed to the database
+	usageDateFmt        = "2006-01-02"     // Date format of the usage keys
+	usageMonthFmt       = "2006-01"        // Month prefix of the usage keys
+)
+
+// Periods a usage threshold applies to
+const (
+	usagePeriodDay   = "day"
+	usagePeriodMonth = "month"
+)
+
+// usageBucket is the bbolt bucket holding one record per day and profile, keyed "date|profile"
+var usageBucket = []byte("usage")
+
+// UsageRecord holds the traffic of one profile on one day
+type UsageRecord struct {
+	Upload   int64 `json:"upload"`
+	Download int64 `json:"download"`
+}
+
+// UsageThreshold is a traffic cap over a period; crossing it raises a usage alert
+type UsageThreshold struct {
+	Period string `json:"period"` // day or month
+	Bytes  int64  `json:"bytes"`  // Combined upload and download across all profiles
+}
+
+// usageStore aggregates traffic into per-day, per-profile totals in a local bbolt database
+type usageStore struct {
+	mu       sync.Mutex
+	db       *bbolt.DB
+	counters any   // Traffic counters of the instance sampled last, compared to detect a new instance
+	lastUp   int64 // Upload total of that instance at the last sample
+	lastDown int64 // Download total of that instance at the last sample
+
+	thresholds []UsageThreshold
+	alerted    map[string]bool // Thresholds already reported, keyed by period start and cap
+}
+
+// openUsageStore opens (or creates) the usage database in dirPath
+func openUsageStore(dirPath string) (*usageStore, error) {
+	db, err := bbolt.Open(filepath.Join(dirPath, usageFileName), 0o644, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open usage database: %w", err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(usageBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize usage database: %w", err)
+	}
+	return &usageStore{db: db, alerted: make(map[string]bool)}, nil
+}
+
+// record adds the traffic since the last sample to today's total of the profile. counters identifies the
+// traffic counters of the running instance and totals reads their cumulative totals; they are read under the
+// store lock, so concurrent samples are recorded in order and never counted twice. A new instance counts from zero.
+func (u *usageStore) record(profile string, counters any, totals func() (up, down int64)) error {
+	if u == nil {
+		return nil
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if counters != u.counters {
+		u.counters, u.lastUp, u.lastDown = counters, 0, 0
+	}
+	up, down := totals()
+	deltaUp, deltaDown := max(up-u.lastUp, 0), max(down-u.lastDown, 0)
+	u.lastUp, u.lastDown = max(up, u.lastUp), max(down, u.lastDown)
+	if deltaUp == 0 && deltaDown == 0 {
+		return nil
+	}
+
+	key := []byte(time.Now().Format(usageDateFmt) + "|" + profile)
+	return u.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(usageBucket)
+
+		var record UsageRecord
+		if content := bucket.Get(key); content != nil {
+			if err := json.Unmarshal(content, &record); err != nil {
+				return err
+			}
+		}
+		record.Upload += deltaUp
+		record.Download += deltaDown
+
+		content, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(key, content)
+	})
+}
+
+// query returns the records between the from and to dates (inclusive, empty means unbounded),
+// optionally restricted to one profile
+func (u *usageStore) query(from, to, profile string) ([]*pb.UsageEntry, error) {
+	if u == nil {
+		return nil, fmt.Errorf("usage database is not available")
+	}
+
+	var entries []*pb.UsageEntry
+	err := u.db.View(func(tx *bbolt.Tx) error {
+		cursor := tx.Bucket(usageBucket).Cursor()
+		for key, content := cursor.Seek([]byte(from)); key != nil; key, content = cursor.Next() {
+			date, name, _ := strings.Cut(string(key), "|")
+			if to != "" && date > to {
+				break
+			}
+			if profile != "" && name != profile {
+				continue
+			}
+
+			var record UsageRecord
+			if err := json.Unmarshal(content, &record); err != nil {
+				return err
+			}
+			entries = append(entries, &pb.UsageEntry{
+				Date:     date,
+				Profile:  name,
+				Upload:   record.Upload,
+				Download: record.Download,
+			})
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// total returns the combined upload and download of all profiles between the from and to dates (inclusive)
+func (u *usageStore) total(from, to string) (int64, error) {
+	entries, err := u.query(from, to, "")
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, entry := range entries {
+		total += entry.Upload + entry.Download
+	}
+	return total, nil
+}
+
+// setThresholds replaces the configured thresholds
+func (u *usageStore) setThresholds(thresholds []UsageThreshold) {
+	if u == nil {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.thresholds = thresholds
+}
+
+// crossedThresholds returns the thresholds exceeded in their current period that were not reported yet,
+// together with the usage that crossed them
+func (u *usageStore) crossedThresholds() ([]UsageThreshold, []int64, error) {
+	if u == nil {
+		return nil, nil, nil
+	}
+	now := time.Now()
+	today := now.Format(usageDateFmt)
+	month := now.Format(usageMonthFmt)
+
+	u.mu.Lock()
+	thresholds := u.thresholds
+	// Reports of past periods are no longer needed
+	for key := range u.alerted {
+		if start, _, _ := strings.Cut(key, "/"); start != today && start != month+"-01" {
+			delete(u.alerted, key)
+		}
+	}
+	u.mu.Unlock()
+
+	var crossed []UsageThreshold
+	var used []int64
+	for _, threshold := range thresholds {
+		from, to := today, today
+		if threshold.Period == usagePeriodMonth {
+			from, to = month+"-01", month+"-31"
+		}
+
+		key := fmt.Sprintf("%s/%d", from, threshold.Bytes)
+		u.mu.Lock()
+		done := u.alerted[key]
+		u.mu.Unlock()
+		if done {
+			continue
+		}
+
+		total, err := u.total(from, to)
+		if err != nil {
+			return crossed, used, err
+		}
+		if total < threshold.Bytes {
+			continue
+		}
+
+		// A concurrent check may have reported it meanwhile
+		u.mu.Lock()
+		done = u.alerted[key]
+		u.alerted[key] = true
+		u.mu.Unlock()
+		if done {
+			continue
+		}
+		crossed = append(crossed, threshold)
+		used = append(used, total)
+	}
+	return crossed, used, nil
+}
+
+// close closes the usage database
+func (u *usageStore) close() error {
+	if u == nil {
+		return nil
+	}
+	return u.db.Close()
+}
+
+// sampleUsage flushes the traffic of the running instance to the usage database; the caller must hold s.mu
+func (s *Server) sampleUsage() {
+	server, err := s.clashServer()
+	if err != nil {
+		return
+	}
+
+	trafficManager := server.TrafficManager()
+	if err := s.usage.record(s.activeProfile(), trafficManager, trafficManager.Total); err != nil {
+		s.logger.error.Printf("Failed to record usage: %v", err)
+		return
+	}
+
+	crossed, used, err := s.usage.crossedThresholds()
+	if err != nil {
+		s.logger.error.Printf("Failed to check usage thresholds: %v", err)
+	}
+	for i, threshold := range crossed {
+		s.logger.warn.Printf("Usage of %d bytes reached the %s threshold of %d bytes", used[i], threshold.Period, threshold.Bytes)
+		s.broadcastEvent("usage-alert", map[string]string{
+			"period":      threshold.Period,
+			"limit_bytes": strconv.FormatInt(threshold.Bytes, 10),
+			"used_bytes":  strconv.FormatInt(used[i], 10),
+		})
+	}
+}
+
+// loadUsageThresholds applies the persisted usage thresholds
+func (s *Server) loadUsageThresholds() {
+	state, err := s.loadState()
+	if err != nil {
+		s.logger.error.Printf("Failed to load usage thresholds: %v", err)
+		return
+	}
+	s.usage.setThresholds(state.UsageThresholds)
+}
+
+// watchUsage periodically flushes the traffic counters while sing-box is running
+func (s *Server) watchUsage() {
+	ticker := time.NewTicker(usageSampleInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.mu.RLock()
+		s.sampleUsage()
+		s.mu.RUnlock()
+	}
+}
+
+// activeProfile returns the name under which traffic of the running config is recorded
+func (s *Server) activeProfile() string {
+	if profile := s.currentProfile(); profile != "" {
+		return profile
+	}
+	return configFileName
+}
+
+// GetUsageHistory handles the gRPC GetUsageHistory request, returning per-day and per-profile traffic totals
+func (s *Server) GetUsageHistory(ctx context.Context, req *pb.UsageHistoryRequest) (*pb.UsageHistoryResponse, error) {
+	// Include the traffic since the last periodic sample
+	s.mu.RLock()
+	s.sampleUsage()
+	s.mu.RUnlock()
+
+	entries, err := s.usage.query(req.From, req.To, req.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.UsageHistoryResponse{Entries: entries}
+	for _, entry := range entries {
+		resp.TotalUpload += entry.Upload
+		resp.TotalDownload += entry.Download
+	}
+	return resp, nil
+}
+
+// GetUsageThresholds handles the gRPC GetUsageThresholds request, returning the configured traffic caps
+func (s *Server) GetUsageThresholds(ctx context.Context, req *pb.GetUsageThresholdsRequest) (*pb.UsageThresholdsResponse, error) {
+	state, err := s.loadState()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+	return usageThresholdsResponse(state.UsageThresholds), nil
+}
+
+// SetUsageThresholds handles the gRPC SetUsageThresholds request, replacing and persisting the traffic caps
+func (s *Server) SetUsageThresholds(ctx context.Context, req *pb.SetUsageThresholdsRequest) (*pb.UsageThresholdsResponse, error) {
+	if s.usage == nil {
+		return nil, status.Errorf(codes.Unavailable, "usage database is not available")
+	}
+
+	var thresholds []UsageThreshold
+	for _, threshold := range req.Thresholds {
+		if threshold.Period != usagePeriodDay && threshold.Period != usagePeriodMonth {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown threshold period %q", threshold.Period)
+		}
+		if threshold.Bytes <= 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "threshold must be a positive number of bytes")
+		}
+		thresholds = append(thresholds, UsageThreshold{Period: threshold.Period, Bytes: threshold.Bytes})
+	}
+
+	err := s.updateState(func(state *HelperState) {
+		state.UsageThresholds = thresholds
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	s.usage.setThresholds(thresholds)
+	s.logger.info.Printf("Usage thresholds set: %d", len(thresholds))
+	return usageThresholdsResponse(thresholds), nil
+}
+
+// usageThresholdsResponse converts thresholds to their gRPC representation
+func usageThresholdsResponse(thresholds []UsageThreshold) *pb.UsageThresholdsResponse {
+	resp := &pb.UsageThresholdsResponse{}
+	for _, threshold := range thresholds {
+		resp.Thresholds = append(resp.Thresholds, &pb.UsageThreshold{Period: threshold.Period, Bytes: threshold.Bytes})
+	}
+	return resp
+}
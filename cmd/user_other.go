@@ -0,0 +1,46 @@
+//go:build !windows
+
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+)
+
+// invokingAccount returns the account of the user who started the helper through sudo or pkexec. It fails when
+// the helper was started directly, by a service manager or by root itself, where there is no user to act for.
+func invokingAccount() (*user.User, error) {
+	uid := os.Getenv("SUDO_UID")
+	if uid == "" {
+		uid = os.Getenv("PKEXEC_UID")
+	}
+	if uid == "" {
+		return nil, errors.New("unknown invoking user, start the helper through sudo or pkexec")
+	}
+	if uid == "0" {
+		return nil, errors.New("the helper was started by root, there is no user to act for")
+	}
+
+	account, err := user.LookupId(uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up UID %s: %w", uid, err)
+	}
+	return account, nil
+}
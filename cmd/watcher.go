@@ -0,0 +1,108 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// configWatchDebounce is how long the config must stay unchanged before a change is acted upon,
+// since editors usually save a file in several write/rename steps
+const configWatchDebounce = 500 * time.Millisecond
+
+// watchConfig watches the sing-box config file and, when it changes while sing-box is running,
+// validates it and broadcasts a "config-changed" event. With autoReload the new config is applied immediately.
+func (s *Server) watchConfig(autoReload bool) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		s.logger.error.Printf("Failed to create config watcher: %v", err)
+		return
+	}
+	defer watcher.Close()
+
+	// Watch the directories rather than the file, so the watch survives editors replacing the file
+	// and follows profile switches
+	profilesDir := filepath.Join(s.dirPath, profilesFolderName)
+	if err := os.MkdirAll(profilesDir, 0o755); err != nil {
+		s.logger.warn.Printf("Failed to create %s: %v", profilesDir, err)
+	}
+	for _, dir := range []string{s.dirPath, profilesDir} {
+		if err := watcher.Add(dir); err != nil {
+			s.logger.error.Printf("Failed to watch %s: %v", dir, err)
+			return
+		}
+	}
+
+	var debounce *time.Timer
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != s.configPath() || !event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+				continue
+			}
+			if debounce != nil {
+				debounce.Stop()
+			}
+			debounce = time.AfterFunc(configWatchDebounce, func() {
+				s.handleConfigChange(autoReload)
+			})
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			s.logger.error.Printf("Config watcher error: %v", err)
+		}
+	}
+}
+
+// handleConfigChange validates the changed config and reloads sing-box if requested
+func (s *Server) handleConfigChange(autoReload bool) {
+	s.mu.RLock()
+	running := s.core.Running()
+	s.mu.RUnlock()
+	if !running {
+		return
+	}
+
+	changed, err := s.pendingConfigChanges()
+	if err != nil {
+		s.logger.error.Printf("Changed config is invalid: %v", err)
+		s.broadcastEvent("config-invalid", map[string]string{"error": err.Error()}) // The running instance is untouched, so the status stays
+		return
+	}
+	if len(changed) == 0 {
+		return // Rewritten with the same content
+	}
+
+	s.logger.info.Printf("%s changed", filepath.Base(s.configPath()))
+	s.broadcastEvent("config-changed", map[string]string{"files": strings.Join(changed, ",")})
+
+	if !autoReload {
+		return
+	}
+	if err := s.reloadSingBox(false, "reloading"); err != nil {
+		s.logger.error.Printf("Auto-reload error: %v", err)
+	}
+}
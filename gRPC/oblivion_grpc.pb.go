@@ -0,0 +1,2905 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: proto/oblivion.proto
+
+package oblivionHelper
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	OblivionService_Start_FullMethodName                 = "/oblivionHelper.OblivionService/Start"
+	OblivionService_Stop_FullMethodName                  = "/oblivionHelper.OblivionService/Stop"
+	OblivionService_Restart_FullMethodName               = "/oblivionHelper.OblivionService/Restart"
+	OblivionService_Reload_FullMethodName                = "/oblivionHelper.OblivionService/Reload"
+	OblivionService_StreamStatus_FullMethodName          = "/oblivionHelper.OblivionService/StreamStatus"
+	OblivionService_StreamEvents_FullMethodName          = "/oblivionHelper.OblivionService/StreamEvents"
+	OblivionService_Exit_FullMethodName                  = "/oblivionHelper.OblivionService/Exit"
+	OblivionService_GetStatus_FullMethodName             = "/oblivionHelper.OblivionService/GetStatus"
+	OblivionService_GetReliabilityHistory_FullMethodName = "/oblivionHelper.OblivionService/GetReliabilityHistory"
+	OblivionService_Pause_FullMethodName                 = "/oblivionHelper.OblivionService/Pause"
+	OblivionService_Resume_FullMethodName                = "/oblivionHelper.OblivionService/Resume"
+	OblivionService_SetSystemProxy_FullMethodName        = "/oblivionHelper.OblivionService/SetSystemProxy"
+	OblivionService_ClearSystemProxy_FullMethodName      = "/oblivionHelper.OblivionService/ClearSystemProxy"
+	OblivionService_GetClashMode_FullMethodName          = "/oblivionHelper.OblivionService/GetClashMode"
+	OblivionService_SetClashMode_FullMethodName          = "/oblivionHelper.OblivionService/SetClashMode"
+	OblivionService_GetOutboundGroups_FullMethodName     = "/oblivionHelper.OblivionService/GetOutboundGroups"
+	OblivionService_SelectOutbound_FullMethodName        = "/oblivionHelper.OblivionService/SelectOutbound"
+	OblivionService_ListOutbounds_FullMethodName         = "/oblivionHelper.OblivionService/ListOutbounds"
+	OblivionService_GetUsageHistory_FullMethodName       = "/oblivionHelper.OblivionService/GetUsageHistory"
+	OblivionService_GetUsageThresholds_FullMethodName    = "/oblivionHelper.OblivionService/GetUsageThresholds"
+	OblivionService_SetUsageThresholds_FullMethodName    = "/oblivionHelper.OblivionService/SetUsageThresholds"
+	OblivionService_SetRateLimit_FullMethodName          = "/oblivionHelper.OblivionService/SetRateLimit"
+	OblivionService_SetConnectionLog_FullMethodName      = "/oblivionHelper.OblivionService/SetConnectionLog"
+	OblivionService_QueryDNS_FullMethodName              = "/oblivionHelper.OblivionService/QueryDNS"
+	OblivionService_SetDNSServers_FullMethodName         = "/oblivionHelper.OblivionService/SetDNSServers"
+	OblivionService_FlushDNSCache_FullMethodName         = "/oblivionHelper.OblivionService/FlushDNSCache"
+	OblivionService_GetFakeIPMappings_FullMethodName     = "/oblivionHelper.OblivionService/GetFakeIPMappings"
+	OblivionService_ResetFakeIP_FullMethodName           = "/oblivionHelper.OblivionService/ResetFakeIP"
+	OblivionService_GetConnections_FullMethodName        = "/oblivionHelper.OblivionService/GetConnections"
+	OblivionService_CloseConnections_FullMethodName      = "/oblivionHelper.OblivionService/CloseConnections"
+	OblivionService_AddInbound_FullMethodName            = "/oblivionHelper.OblivionService/AddInbound"
+	OblivionService_RemoveInbound_FullMethodName         = "/oblivionHelper.OblivionService/RemoveInbound"
+	OblivionService_SetInboundPort_FullMethodName        = "/oblivionHelper.OblivionService/SetInboundPort"
+	OblivionService_GetSystemInfo_FullMethodName         = "/oblivionHelper.OblivionService/GetSystemInfo"
+	OblivionService_GetVersion_FullMethodName            = "/oblivionHelper.OblivionService/GetVersion"
+	OblivionService_GetResourceUsage_FullMethodName      = "/oblivionHelper.OblivionService/GetResourceUsage"
+	OblivionService_StreamResourceUsage_FullMethodName   = "/oblivionHelper.OblivionService/StreamResourceUsage"
+	OblivionService_Ping_FullMethodName                  = "/oblivionHelper.OblivionService/Ping"
+	OblivionService_TestConfig_FullMethodName            = "/oblivionHelper.OblivionService/TestConfig"
+	OblivionService_SetConfig_FullMethodName             = "/oblivionHelper.OblivionService/SetConfig"
+	OblivionService_GetConfig_FullMethodName             = "/oblivionHelper.OblivionService/GetConfig"
+	OblivionService_ListProfiles_FullMethodName          = "/oblivionHelper.OblivionService/ListProfiles"
+	OblivionService_SaveProfile_FullMethodName           = "/oblivionHelper.OblivionService/SaveProfile"
+	OblivionService_DeleteProfile_FullMethodName         = "/oblivionHelper.OblivionService/DeleteProfile"
+	OblivionService_SwitchProfile_FullMethodName         = "/oblivionHelper.OblivionService/SwitchProfile"
+	OblivionService_ExportLogsBundle_FullMethodName      = "/oblivionHelper.OblivionService/ExportLogsBundle"
+	OblivionService_SetPACServer_FullMethodName          = "/oblivionHelper.OblivionService/SetPACServer"
+	OblivionService_SetPortMapping_FullMethodName        = "/oblivionHelper.OblivionService/SetPortMapping"
+	OblivionService_GetPortMappings_FullMethodName       = "/oblivionHelper.OblivionService/GetPortMappings"
+	OblivionService_ProbeInbound_FullMethodName          = "/oblivionHelper.OblivionService/ProbeInbound"
+	OblivionService_TestConnectivity_FullMethodName      = "/oblivionHelper.OblivionService/TestConnectivity"
+	OblivionService_URLTest_FullMethodName               = "/oblivionHelper.OblivionService/URLTest"
+	OblivionService_GetPublicIP_FullMethodName           = "/oblivionHelper.OblivionService/GetPublicIP"
+	OblivionService_SetBindInterface_FullMethodName      = "/oblivionHelper.OblivionService/SetBindInterface"
+	OblivionService_AddSplitTunnelRule_FullMethodName    = "/oblivionHelper.OblivionService/AddSplitTunnelRule"
+	OblivionService_RemoveSplitTunnelRule_FullMethodName = "/oblivionHelper.OblivionService/RemoveSplitTunnelRule"
+	OblivionService_ListSplitTunnelRules_FullMethodName  = "/oblivionHelper.OblivionService/ListSplitTunnelRules"
+	OblivionService_SetDNSHijack_FullMethodName          = "/oblivionHelper.OblivionService/SetDNSHijack"
+	OblivionService_ApplyRoutingPreset_FullMethodName    = "/oblivionHelper.OblivionService/ApplyRoutingPreset"
+	OblivionService_StreamTraffic_FullMethodName         = "/oblivionHelper.OblivionService/StreamTraffic"
+	OblivionService_GetStats_FullMethodName              = "/oblivionHelper.OblivionService/GetStats"
+	OblivionService_SetLogLevel_FullMethodName           = "/oblivionHelper.OblivionService/SetLogLevel"
+	OblivionService_StreamLogs_FullMethodName            = "/oblivionHelper.OblivionService/StreamLogs"
+	OblivionService_RegisterParent_FullMethodName        = "/oblivionHelper.OblivionService/RegisterParent"
+	OblivionService_LookupRule_FullMethodName            = "/oblivionHelper.OblivionService/LookupRule"
+	OblivionService_TraceRoute_FullMethodName            = "/oblivionHelper.OblivionService/TraceRoute"
+	OblivionService_SetEndpointPool_FullMethodName       = "/oblivionHelper.OblivionService/SetEndpointPool"
+	OblivionService_GetEndpointPool_FullMethodName       = "/oblivionHelper.OblivionService/GetEndpointPool"
+	OblivionService_GetCacheFile_FullMethodName          = "/oblivionHelper.OblivionService/GetCacheFile"
+	OblivionService_ClearCacheFile_FullMethodName        = "/oblivionHelper.OblivionService/ClearCacheFile"
+	OblivionService_SetCacheFile_FullMethodName          = "/oblivionHelper.OblivionService/SetCacheFile"
+	OblivionService_RegisterHook_FullMethodName          = "/oblivionHelper.OblivionService/RegisterHook"
+	OblivionService_GetRulesetInfo_FullMethodName        = "/oblivionHelper.OblivionService/GetRulesetInfo"
+	OblivionService_RepairTunDriver_FullMethodName       = "/oblivionHelper.OblivionService/RepairTunDriver"
+)
+
+// OblivionServiceClient is the client API for OblivionService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type OblivionServiceClient interface {
+	Start(ctx context.Context, in *StartRequest, opts ...grpc.CallOption) (*StartResponse, error)
+	Stop(ctx context.Context, in *StopRequest, opts ...grpc.CallOption) (*StopResponse, error)
+	Restart(ctx context.Context, in *RestartRequest, opts ...grpc.CallOption) (*RestartResponse, error)
+	Reload(ctx context.Context, in *ReloadRequest, opts ...grpc.CallOption) (*ReloadResponse, error)
+	StreamStatus(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StatusResponse], error)
+	StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+	Exit(ctx context.Context, in *ExitRequest, opts ...grpc.CallOption) (*ExitResponse, error)
+	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*GetStatusResponse, error)
+	GetReliabilityHistory(ctx context.Context, in *ReliabilityHistoryRequest, opts ...grpc.CallOption) (*ReliabilityHistoryResponse, error)
+	Pause(ctx context.Context, in *PauseRequest, opts ...grpc.CallOption) (*PauseResponse, error)
+	Resume(ctx context.Context, in *ResumeRequest, opts ...grpc.CallOption) (*ResumeResponse, error)
+	SetSystemProxy(ctx context.Context, in *SetSystemProxyRequest, opts ...grpc.CallOption) (*SystemProxyResponse, error)
+	ClearSystemProxy(ctx context.Context, in *ClearSystemProxyRequest, opts ...grpc.CallOption) (*SystemProxyResponse, error)
+	GetClashMode(ctx context.Context, in *GetClashModeRequest, opts ...grpc.CallOption) (*ClashModeResponse, error)
+	SetClashMode(ctx context.Context, in *SetClashModeRequest, opts ...grpc.CallOption) (*ClashModeResponse, error)
+	GetOutboundGroups(ctx context.Context, in *GetOutboundGroupsRequest, opts ...grpc.CallOption) (*OutboundGroupsResponse, error)
+	SelectOutbound(ctx context.Context, in *SelectOutboundRequest, opts ...grpc.CallOption) (*SelectOutboundResponse, error)
+	ListOutbounds(ctx context.Context, in *ListOutboundsRequest, opts ...grpc.CallOption) (*ListOutboundsResponse, error)
+	GetUsageHistory(ctx context.Context, in *UsageHistoryRequest, opts ...grpc.CallOption) (*UsageHistoryResponse, error)
+	GetUsageThresholds(ctx context.Context, in *GetUsageThresholdsRequest, opts ...grpc.CallOption) (*UsageThresholdsResponse, error)
+	SetUsageThresholds(ctx context.Context, in *SetUsageThresholdsRequest, opts ...grpc.CallOption) (*UsageThresholdsResponse, error)
+	SetRateLimit(ctx context.Context, in *SetRateLimitRequest, opts ...grpc.CallOption) (*RateLimitResponse, error)
+	SetConnectionLog(ctx context.Context, in *SetConnectionLogRequest, opts ...grpc.CallOption) (*ConnectionLogResponse, error)
+	QueryDNS(ctx context.Context, in *QueryDNSRequest, opts ...grpc.CallOption) (*QueryDNSResponse, error)
+	SetDNSServers(ctx context.Context, in *SetDNSServersRequest, opts ...grpc.CallOption) (*DNSServersResponse, error)
+	FlushDNSCache(ctx context.Context, in *FlushDNSCacheRequest, opts ...grpc.CallOption) (*FlushDNSCacheResponse, error)
+	GetFakeIPMappings(ctx context.Context, in *FakeIPMappingsRequest, opts ...grpc.CallOption) (*FakeIPMappingsResponse, error)
+	ResetFakeIP(ctx context.Context, in *ResetFakeIPRequest, opts ...grpc.CallOption) (*ResetFakeIPResponse, error)
+	GetConnections(ctx context.Context, in *GetConnectionsRequest, opts ...grpc.CallOption) (*GetConnectionsResponse, error)
+	CloseConnections(ctx context.Context, in *CloseConnectionsRequest, opts ...grpc.CallOption) (*CloseConnectionsResponse, error)
+	AddInbound(ctx context.Context, in *AddInboundRequest, opts ...grpc.CallOption) (*InboundResponse, error)
+	RemoveInbound(ctx context.Context, in *RemoveInboundRequest, opts ...grpc.CallOption) (*InboundResponse, error)
+	SetInboundPort(ctx context.Context, in *SetInboundPortRequest, opts ...grpc.CallOption) (*SetInboundPortResponse, error)
+	GetSystemInfo(ctx context.Context, in *GetSystemInfoRequest, opts ...grpc.CallOption) (*SystemInfoResponse, error)
+	GetVersion(ctx context.Context, in *GetVersionRequest, opts ...grpc.CallOption) (*VersionResponse, error)
+	GetResourceUsage(ctx context.Context, in *GetResourceUsageRequest, opts ...grpc.CallOption) (*ResourceUsage, error)
+	StreamResourceUsage(ctx context.Context, in *StreamResourceUsageRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ResourceUsage], error)
+	Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error)
+	TestConfig(ctx context.Context, in *TestConfigRequest, opts ...grpc.CallOption) (*TestConfigResponse, error)
+	SetConfig(ctx context.Context, in *SetConfigRequest, opts ...grpc.CallOption) (*SetConfigResponse, error)
+	GetConfig(ctx context.Context, in *GetConfigRequest, opts ...grpc.CallOption) (*GetConfigResponse, error)
+	ListProfiles(ctx context.Context, in *ListProfilesRequest, opts ...grpc.CallOption) (*ListProfilesResponse, error)
+	SaveProfile(ctx context.Context, in *SaveProfileRequest, opts ...grpc.CallOption) (*SaveProfileResponse, error)
+	DeleteProfile(ctx context.Context, in *DeleteProfileRequest, opts ...grpc.CallOption) (*DeleteProfileResponse, error)
+	SwitchProfile(ctx context.Context, in *SwitchProfileRequest, opts ...grpc.CallOption) (*SwitchProfileResponse, error)
+	ExportLogsBundle(ctx context.Context, in *ExportLogsBundleRequest, opts ...grpc.CallOption) (*ExportLogsBundleResponse, error)
+	SetPACServer(ctx context.Context, in *SetPACServerRequest, opts ...grpc.CallOption) (*PACServerResponse, error)
+	SetPortMapping(ctx context.Context, in *SetPortMappingRequest, opts ...grpc.CallOption) (*PortMappingResponse, error)
+	GetPortMappings(ctx context.Context, in *GetPortMappingsRequest, opts ...grpc.CallOption) (*PortMappingResponse, error)
+	ProbeInbound(ctx context.Context, in *ProbeInboundRequest, opts ...grpc.CallOption) (*ProbeInboundResponse, error)
+	TestConnectivity(ctx context.Context, in *TestConnectivityRequest, opts ...grpc.CallOption) (*TestConnectivityResponse, error)
+	URLTest(ctx context.Context, in *URLTestRequest, opts ...grpc.CallOption) (*URLTestResponse, error)
+	GetPublicIP(ctx context.Context, in *GetPublicIPRequest, opts ...grpc.CallOption) (*GetPublicIPResponse, error)
+	SetBindInterface(ctx context.Context, in *SetBindInterfaceRequest, opts ...grpc.CallOption) (*SetBindInterfaceResponse, error)
+	AddSplitTunnelRule(ctx context.Context, in *SplitTunnelRule, opts ...grpc.CallOption) (*SplitTunnelResponse, error)
+	RemoveSplitTunnelRule(ctx context.Context, in *RemoveSplitTunnelRuleRequest, opts ...grpc.CallOption) (*SplitTunnelResponse, error)
+	ListSplitTunnelRules(ctx context.Context, in *ListSplitTunnelRulesRequest, opts ...grpc.CallOption) (*SplitTunnelResponse, error)
+	SetDNSHijack(ctx context.Context, in *SetDNSHijackRequest, opts ...grpc.CallOption) (*SetDNSHijackResponse, error)
+	ApplyRoutingPreset(ctx context.Context, in *ApplyRoutingPresetRequest, opts ...grpc.CallOption) (*ApplyRoutingPresetResponse, error)
+	StreamTraffic(ctx context.Context, in *TrafficRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TrafficSample], error)
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
+	SetLogLevel(ctx context.Context, in *SetLogLevelRequest, opts ...grpc.CallOption) (*SetLogLevelResponse, error)
+	StreamLogs(ctx context.Context, in *StreamLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogEntry], error)
+	RegisterParent(ctx context.Context, in *RegisterParentRequest, opts ...grpc.CallOption) (*RegisterParentResponse, error)
+	LookupRule(ctx context.Context, in *LookupRuleRequest, opts ...grpc.CallOption) (*LookupRuleResponse, error)
+	TraceRoute(ctx context.Context, in *TraceRouteRequest, opts ...grpc.CallOption) (*TraceRouteResponse, error)
+	SetEndpointPool(ctx context.Context, in *SetEndpointPoolRequest, opts ...grpc.CallOption) (*EndpointPoolResponse, error)
+	GetEndpointPool(ctx context.Context, in *GetEndpointPoolRequest, opts ...grpc.CallOption) (*EndpointPoolResponse, error)
+	GetCacheFile(ctx context.Context, in *GetCacheFileRequest, opts ...grpc.CallOption) (*CacheFileResponse, error)
+	ClearCacheFile(ctx context.Context, in *ClearCacheFileRequest, opts ...grpc.CallOption) (*CacheFileResponse, error)
+	SetCacheFile(ctx context.Context, in *SetCacheFileRequest, opts ...grpc.CallOption) (*CacheFileResponse, error)
+	RegisterHook(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[HookMessage, HookEvent], error)
+	GetRulesetInfo(ctx context.Context, in *GetRulesetInfoRequest, opts ...grpc.CallOption) (*RulesetInfoResponse, error)
+	RepairTunDriver(ctx context.Context, in *RepairTunDriverRequest, opts ...grpc.CallOption) (*RepairTunDriverResponse, error)
+}
+
+type oblivionServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewOblivionServiceClient(cc grpc.ClientConnInterface) OblivionServiceClient {
+	return &oblivionServiceClient{cc}
+}
+
+func (c *oblivionServiceClient) Start(ctx context.Context, in *StartRequest, opts ...grpc.CallOption) (*StartResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StartResponse)
+	err := c.cc.Invoke(ctx, OblivionService_Start_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) Stop(ctx context.Context, in *StopRequest, opts ...grpc.CallOption) (*StopResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StopResponse)
+	err := c.cc.Invoke(ctx, OblivionService_Stop_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) Restart(ctx context.Context, in *RestartRequest, opts ...grpc.CallOption) (*RestartResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RestartResponse)
+	err := c.cc.Invoke(ctx, OblivionService_Restart_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) Reload(ctx context.Context, in *ReloadRequest, opts ...grpc.CallOption) (*ReloadResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReloadResponse)
+	err := c.cc.Invoke(ctx, OblivionService_Reload_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) StreamStatus(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StatusResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &OblivionService_ServiceDesc.Streams[0], OblivionService_StreamStatus_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StatusRequest, StatusResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OblivionService_StreamStatusClient = grpc.ServerStreamingClient[StatusResponse]
+
+func (c *oblivionServiceClient) StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &OblivionService_ServiceDesc.Streams[1], OblivionService_StreamEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamEventsRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OblivionService_StreamEventsClient = grpc.ServerStreamingClient[Event]
+
+func (c *oblivionServiceClient) Exit(ctx context.Context, in *ExitRequest, opts ...grpc.CallOption) (*ExitResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExitResponse)
+	err := c.cc.Invoke(ctx, OblivionService_Exit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*GetStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetStatusResponse)
+	err := c.cc.Invoke(ctx, OblivionService_GetStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) GetReliabilityHistory(ctx context.Context, in *ReliabilityHistoryRequest, opts ...grpc.CallOption) (*ReliabilityHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReliabilityHistoryResponse)
+	err := c.cc.Invoke(ctx, OblivionService_GetReliabilityHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) Pause(ctx context.Context, in *PauseRequest, opts ...grpc.CallOption) (*PauseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PauseResponse)
+	err := c.cc.Invoke(ctx, OblivionService_Pause_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) Resume(ctx context.Context, in *ResumeRequest, opts ...grpc.CallOption) (*ResumeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResumeResponse)
+	err := c.cc.Invoke(ctx, OblivionService_Resume_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) SetSystemProxy(ctx context.Context, in *SetSystemProxyRequest, opts ...grpc.CallOption) (*SystemProxyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SystemProxyResponse)
+	err := c.cc.Invoke(ctx, OblivionService_SetSystemProxy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) ClearSystemProxy(ctx context.Context, in *ClearSystemProxyRequest, opts ...grpc.CallOption) (*SystemProxyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SystemProxyResponse)
+	err := c.cc.Invoke(ctx, OblivionService_ClearSystemProxy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) GetClashMode(ctx context.Context, in *GetClashModeRequest, opts ...grpc.CallOption) (*ClashModeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ClashModeResponse)
+	err := c.cc.Invoke(ctx, OblivionService_GetClashMode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) SetClashMode(ctx context.Context, in *SetClashModeRequest, opts ...grpc.CallOption) (*ClashModeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ClashModeResponse)
+	err := c.cc.Invoke(ctx, OblivionService_SetClashMode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) GetOutboundGroups(ctx context.Context, in *GetOutboundGroupsRequest, opts ...grpc.CallOption) (*OutboundGroupsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(OutboundGroupsResponse)
+	err := c.cc.Invoke(ctx, OblivionService_GetOutboundGroups_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) SelectOutbound(ctx context.Context, in *SelectOutboundRequest, opts ...grpc.CallOption) (*SelectOutboundResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SelectOutboundResponse)
+	err := c.cc.Invoke(ctx, OblivionService_SelectOutbound_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) ListOutbounds(ctx context.Context, in *ListOutboundsRequest, opts ...grpc.CallOption) (*ListOutboundsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListOutboundsResponse)
+	err := c.cc.Invoke(ctx, OblivionService_ListOutbounds_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) GetUsageHistory(ctx context.Context, in *UsageHistoryRequest, opts ...grpc.CallOption) (*UsageHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UsageHistoryResponse)
+	err := c.cc.Invoke(ctx, OblivionService_GetUsageHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) GetUsageThresholds(ctx context.Context, in *GetUsageThresholdsRequest, opts ...grpc.CallOption) (*UsageThresholdsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UsageThresholdsResponse)
+	err := c.cc.Invoke(ctx, OblivionService_GetUsageThresholds_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) SetUsageThresholds(ctx context.Context, in *SetUsageThresholdsRequest, opts ...grpc.CallOption) (*UsageThresholdsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UsageThresholdsResponse)
+	err := c.cc.Invoke(ctx, OblivionService_SetUsageThresholds_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) SetRateLimit(ctx context.Context, in *SetRateLimitRequest, opts ...grpc.CallOption) (*RateLimitResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RateLimitResponse)
+	err := c.cc.Invoke(ctx, OblivionService_SetRateLimit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) SetConnectionLog(ctx context.Context, in *SetConnectionLogRequest, opts ...grpc.CallOption) (*ConnectionLogResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConnectionLogResponse)
+	err := c.cc.Invoke(ctx, OblivionService_SetConnectionLog_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) QueryDNS(ctx context.Context, in *QueryDNSRequest, opts ...grpc.CallOption) (*QueryDNSResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueryDNSResponse)
+	err := c.cc.Invoke(ctx, OblivionService_QueryDNS_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) SetDNSServers(ctx context.Context, in *SetDNSServersRequest, opts ...grpc.CallOption) (*DNSServersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DNSServersResponse)
+	err := c.cc.Invoke(ctx, OblivionService_SetDNSServers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) FlushDNSCache(ctx context.Context, in *FlushDNSCacheRequest, opts ...grpc.CallOption) (*FlushDNSCacheResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FlushDNSCacheResponse)
+	err := c.cc.Invoke(ctx, OblivionService_FlushDNSCache_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) GetFakeIPMappings(ctx context.Context, in *FakeIPMappingsRequest, opts ...grpc.CallOption) (*FakeIPMappingsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FakeIPMappingsResponse)
+	err := c.cc.Invoke(ctx, OblivionService_GetFakeIPMappings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) ResetFakeIP(ctx context.Context, in *ResetFakeIPRequest, opts ...grpc.CallOption) (*ResetFakeIPResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResetFakeIPResponse)
+	err := c.cc.Invoke(ctx, OblivionService_ResetFakeIP_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) GetConnections(ctx context.Context, in *GetConnectionsRequest, opts ...grpc.CallOption) (*GetConnectionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetConnectionsResponse)
+	err := c.cc.Invoke(ctx, OblivionService_GetConnections_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) CloseConnections(ctx context.Context, in *CloseConnectionsRequest, opts ...grpc.CallOption) (*CloseConnectionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CloseConnectionsResponse)
+	err := c.cc.Invoke(ctx, OblivionService_CloseConnections_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) AddInbound(ctx context.Context, in *AddInboundRequest, opts ...grpc.CallOption) (*InboundResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InboundResponse)
+	err := c.cc.Invoke(ctx, OblivionService_AddInbound_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) RemoveInbound(ctx context.Context, in *RemoveInboundRequest, opts ...grpc.CallOption) (*InboundResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InboundResponse)
+	err := c.cc.Invoke(ctx, OblivionService_RemoveInbound_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) SetInboundPort(ctx context.Context, in *SetInboundPortRequest, opts ...grpc.CallOption) (*SetInboundPortResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetInboundPortResponse)
+	err := c.cc.Invoke(ctx, OblivionService_SetInboundPort_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) GetSystemInfo(ctx context.Context, in *GetSystemInfoRequest, opts ...grpc.CallOption) (*SystemInfoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SystemInfoResponse)
+	err := c.cc.Invoke(ctx, OblivionService_GetSystemInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) GetVersion(ctx context.Context, in *GetVersionRequest, opts ...grpc.CallOption) (*VersionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VersionResponse)
+	err := c.cc.Invoke(ctx, OblivionService_GetVersion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) GetResourceUsage(ctx context.Context, in *GetResourceUsageRequest, opts ...grpc.CallOption) (*ResourceUsage, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResourceUsage)
+	err := c.cc.Invoke(ctx, OblivionService_GetResourceUsage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) StreamResourceUsage(ctx context.Context, in *StreamResourceUsageRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ResourceUsage], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &OblivionService_ServiceDesc.Streams[2], OblivionService_StreamResourceUsage_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamResourceUsageRequest, ResourceUsage]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OblivionService_StreamResourceUsageClient = grpc.ServerStreamingClient[ResourceUsage]
+
+func (c *oblivionServiceClient) Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PingResponse)
+	err := c.cc.Invoke(ctx, OblivionService_Ping_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) TestConfig(ctx context.Context, in *TestConfigRequest, opts ...grpc.CallOption) (*TestConfigResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TestConfigResponse)
+	err := c.cc.Invoke(ctx, OblivionService_TestConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) SetConfig(ctx context.Context, in *SetConfigRequest, opts ...grpc.CallOption) (*SetConfigResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetConfigResponse)
+	err := c.cc.Invoke(ctx, OblivionService_SetConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) GetConfig(ctx context.Context, in *GetConfigRequest, opts ...grpc.CallOption) (*GetConfigResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetConfigResponse)
+	err := c.cc.Invoke(ctx, OblivionService_GetConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) ListProfiles(ctx context.Context, in *ListProfilesRequest, opts ...grpc.CallOption) (*ListProfilesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListProfilesResponse)
+	err := c.cc.Invoke(ctx, OblivionService_ListProfiles_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) SaveProfile(ctx context.Context, in *SaveProfileRequest, opts ...grpc.CallOption) (*SaveProfileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SaveProfileResponse)
+	err := c.cc.Invoke(ctx, OblivionService_SaveProfile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) DeleteProfile(ctx context.Context, in *DeleteProfileRequest, opts ...grpc.CallOption) (*DeleteProfileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteProfileResponse)
+	err := c.cc.Invoke(ctx, OblivionService_DeleteProfile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) SwitchProfile(ctx context.Context, in *SwitchProfileRequest, opts ...grpc.CallOption) (*SwitchProfileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SwitchProfileResponse)
+	err := c.cc.Invoke(ctx, OblivionService_SwitchProfile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) ExportLogsBundle(ctx context.Context, in *ExportLogsBundleRequest, opts ...grpc.CallOption) (*ExportLogsBundleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExportLogsBundleResponse)
+	err := c.cc.Invoke(ctx, OblivionService_ExportLogsBundle_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) SetPACServer(ctx context.Context, in *SetPACServerRequest, opts ...grpc.CallOption) (*PACServerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PACServerResponse)
+	err := c.cc.Invoke(ctx, OblivionService_SetPACServer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) SetPortMapping(ctx context.Context, in *SetPortMappingRequest, opts ...grpc.CallOption) (*PortMappingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PortMappingResponse)
+	err := c.cc.Invoke(ctx, OblivionService_SetPortMapping_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) GetPortMappings(ctx context.Context, in *GetPortMappingsRequest, opts ...grpc.CallOption) (*PortMappingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PortMappingResponse)
+	err := c.cc.Invoke(ctx, OblivionService_GetPortMappings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) ProbeInbound(ctx context.Context, in *ProbeInboundRequest, opts ...grpc.CallOption) (*ProbeInboundResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProbeInboundResponse)
+	err := c.cc.Invoke(ctx, OblivionService_ProbeInbound_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) TestConnectivity(ctx context.Context, in *TestConnectivityRequest, opts ...grpc.CallOption) (*TestConnectivityResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TestConnectivityResponse)
+	err := c.cc.Invoke(ctx, OblivionService_TestConnectivity_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) URLTest(ctx context.Context, in *URLTestRequest, opts ...grpc.CallOption) (*URLTestResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(URLTestResponse)
+	err := c.cc.Invoke(ctx, OblivionService_URLTest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) GetPublicIP(ctx context.Context, in *GetPublicIPRequest, opts ...grpc.CallOption) (*GetPublicIPResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetPublicIPResponse)
+	err := c.cc.Invoke(ctx, OblivionService_GetPublicIP_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) SetBindInterface(ctx context.Context, in *SetBindInterfaceRequest, opts ...grpc.CallOption) (*SetBindInterfaceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetBindInterfaceResponse)
+	err := c.cc.Invoke(ctx, OblivionService_SetBindInterface_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) AddSplitTunnelRule(ctx context.Context, in *SplitTunnelRule, opts ...grpc.CallOption) (*SplitTunnelResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SplitTunnelResponse)
+	err := c.cc.Invoke(ctx, OblivionService_AddSplitTunnelRule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) RemoveSplitTunnelRule(ctx context.Context, in *RemoveSplitTunnelRuleRequest, opts ...grpc.CallOption) (*SplitTunnelResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SplitTunnelResponse)
+	err := c.cc.Invoke(ctx, OblivionService_RemoveSplitTunnelRule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) ListSplitTunnelRules(ctx context.Context, in *ListSplitTunnelRulesRequest, opts ...grpc.CallOption) (*SplitTunnelResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SplitTunnelResponse)
+	err := c.cc.Invoke(ctx, OblivionService_ListSplitTunnelRules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) SetDNSHijack(ctx context.Context, in *SetDNSHijackRequest, opts ...grpc.CallOption) (*SetDNSHijackResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetDNSHijackResponse)
+	err := c.cc.Invoke(ctx, OblivionService_SetDNSHijack_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) ApplyRoutingPreset(ctx context.Context, in *ApplyRoutingPresetRequest, opts ...grpc.CallOption) (*ApplyRoutingPresetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ApplyRoutingPresetResponse)
+	err := c.cc.Invoke(ctx, OblivionService_ApplyRoutingPreset_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) StreamTraffic(ctx context.Context, in *TrafficRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TrafficSample], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &OblivionService_ServiceDesc.Streams[3], OblivionService_StreamTraffic_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[TrafficRequest, TrafficSample]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OblivionService_StreamTrafficClient = grpc.ServerStreamingClient[TrafficSample]
+
+func (c *oblivionServiceClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatsResponse)
+	err := c.cc.Invoke(ctx, OblivionService_GetStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) SetLogLevel(ctx context.Context, in *SetLogLevelRequest, opts ...grpc.CallOption) (*SetLogLevelResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetLogLevelResponse)
+	err := c.cc.Invoke(ctx, OblivionService_SetLogLevel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) StreamLogs(ctx context.Context, in *StreamLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogEntry], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &OblivionService_ServiceDesc.Streams[4], OblivionService_StreamLogs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamLogsRequest, LogEntry]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OblivionService_StreamLogsClient = grpc.ServerStreamingClient[LogEntry]
+
+func (c *oblivionServiceClient) RegisterParent(ctx context.Context, in *RegisterParentRequest, opts ...grpc.CallOption) (*RegisterParentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegisterParentResponse)
+	err := c.cc.Invoke(ctx, OblivionService_RegisterParent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) LookupRule(ctx context.Context, in *LookupRuleRequest, opts ...grpc.CallOption) (*LookupRuleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LookupRuleResponse)
+	err := c.cc.Invoke(ctx, OblivionService_LookupRule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) TraceRoute(ctx context.Context, in *TraceRouteRequest, opts ...grpc.CallOption) (*TraceRouteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TraceRouteResponse)
+	err := c.cc.Invoke(ctx, OblivionService_TraceRoute_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) SetEndpointPool(ctx context.Context, in *SetEndpointPoolRequest, opts ...grpc.CallOption) (*EndpointPoolResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EndpointPoolResponse)
+	err := c.cc.Invoke(ctx, OblivionService_SetEndpointPool_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) GetEndpointPool(ctx context.Context, in *GetEndpointPoolRequest, opts ...grpc.CallOption) (*EndpointPoolResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EndpointPoolResponse)
+	err := c.cc.Invoke(ctx, OblivionService_GetEndpointPool_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) GetCacheFile(ctx context.Context, in *GetCacheFileRequest, opts ...grpc.CallOption) (*CacheFileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CacheFileResponse)
+	err := c.cc.Invoke(ctx, OblivionService_GetCacheFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) ClearCacheFile(ctx context.Context, in *ClearCacheFileRequest, opts ...grpc.CallOption) (*CacheFileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CacheFileResponse)
+	err := c.cc.Invoke(ctx, OblivionService_ClearCacheFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) SetCacheFile(ctx context.Context, in *SetCacheFileRequest, opts ...grpc.CallOption) (*CacheFileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CacheFileResponse)
+	err := c.cc.Invoke(ctx, OblivionService_SetCacheFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) RegisterHook(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[HookMessage, HookEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &OblivionService_ServiceDesc.Streams[5], OblivionService_RegisterHook_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[HookMessage, HookEvent]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OblivionService_RegisterHookClient = grpc.BidiStreamingClient[HookMessage, HookEvent]
+
+func (c *oblivionServiceClient) GetRulesetInfo(ctx context.Context, in *GetRulesetInfoRequest, opts ...grpc.CallOption) (*RulesetInfoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RulesetInfoResponse)
+	err := c.cc.Invoke(ctx, OblivionService_GetRulesetInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oblivionServiceClient) RepairTunDriver(ctx context.Context, in *RepairTunDriverRequest, opts ...grpc.CallOption) (*RepairTunDriverResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RepairTunDriverResponse)
+	err := c.cc.Invoke(ctx, OblivionService_RepairTunDriver_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// OblivionServiceServer is the server API for OblivionService service.
+// All implementations must embed UnimplementedOblivionServiceServer
+// for forward compatibility.
+type OblivionServiceServer interface {
+	Start(context.Context, *StartRequest) (*StartResponse, error)
+	Stop(context.Context, *StopRequest) (*StopResponse, error)
+	Restart(context.Context, *RestartRequest) (*RestartResponse, error)
+	Reload(context.Context, *ReloadRequest) (*ReloadResponse, error)
+	StreamStatus(*StatusRequest, grpc.ServerStreamingServer[StatusResponse]) error
+	StreamEvents(*StreamEventsRequest, grpc.ServerStreamingServer[Event]) error
+	Exit(context.Context, *ExitRequest) (*ExitResponse, error)
+	GetStatus(context.Context, *GetStatusRequest) (*GetStatusResponse, error)
+	GetReliabilityHistory(context.Context, *ReliabilityHistoryRequest) (*ReliabilityHistoryResponse, error)
+	Pause(context.Context, *PauseRequest) (*PauseResponse, error)
+	Resume(context.Context, *ResumeRequest) (*ResumeResponse, error)
+	SetSystemProxy(context.Context, *SetSystemProxyRequest) (*SystemProxyResponse, error)
+	ClearSystemProxy(context.Context, *ClearSystemProxyRequest) (*SystemProxyResponse, error)
+	GetClashMode(context.Context, *GetClashModeRequest) (*ClashModeResponse, error)
+	SetClashMode(context.Context, *SetClashModeRequest) (*ClashModeResponse, error)
+	GetOutboundGroups(context.Context, *GetOutboundGroupsRequest) (*OutboundGroupsResponse, error)
+	SelectOutbound(context.Context, *SelectOutboundRequest) (*SelectOutboundResponse, error)
+	ListOutbounds(context.Context, *ListOutboundsRequest) (*ListOutboundsResponse, error)
+	GetUsageHistory(context.Context, *UsageHistoryRequest) (*UsageHistoryResponse, error)
+	GetUsageThresholds(context.Context, *GetUsageThresholdsRequest) (*UsageThresholdsResponse, error)
+	SetUsageThresholds(context.Context, *SetUsageThresholdsRequest) (*UsageThresholdsResponse, error)
+	SetRateLimit(context.Context, *SetRateLimitRequest) (*RateLimitResponse, error)
+	SetConnectionLog(context.Context, *SetConnectionLogRequest) (*ConnectionLogResponse, error)
+	QueryDNS(context.Context, *QueryDNSRequest) (*QueryDNSResponse, error)
+	SetDNSServers(context.Context, *SetDNSServersRequest) (*DNSServersResponse, error)
+	FlushDNSCache(context.Context, *FlushDNSCacheRequest) (*FlushDNSCacheResponse, error)
+	GetFakeIPMappings(context.Context, *FakeIPMappingsRequest) (*FakeIPMappingsResponse, error)
+	ResetFakeIP(context.Context, *ResetFakeIPRequest) (*ResetFakeIPResponse, error)
+	GetConnections(context.Context, *GetConnectionsRequest) (*GetConnectionsResponse, error)
+	CloseConnections(context.Context, *CloseConnectionsRequest) (*CloseConnectionsResponse, error)
+	AddInbound(context.Context, *AddInboundRequest) (*InboundResponse, error)
+	RemoveInbound(context.Context, *RemoveInboundRequest) (*InboundResponse, error)
+	SetInboundPort(context.Context, *SetInboundPortRequest) (*SetInboundPortResponse, error)
+	GetSystemInfo(context.Context, *GetSystemInfoRequest) (*SystemInfoResponse, error)
+	GetVersion(context.Context, *GetVersionRequest) (*VersionResponse, error)
+	GetResourceUsage(context.Context, *GetResourceUsageRequest) (*ResourceUsage, error)
+	StreamResourceUsage(*StreamResourceUsageRequest, grpc.ServerStreamingServer[ResourceUsage]) error
+	Ping(context.Context, *PingRequest) (*PingResponse, error)
+	TestConfig(context.Context, *TestConfigRequest) (*TestConfigResponse, error)
+	SetConfig(context.Context, *SetConfigRequest) (*SetConfigResponse, error)
+	GetConfig(context.Context, *GetConfigRequest) (*GetConfigResponse, error)
+	ListProfiles(context.Context, *ListProfilesRequest) (*ListProfilesResponse, error)
+	SaveProfile(context.Context, *SaveProfileRequest) (*SaveProfileResponse, error)
+	DeleteProfile(context.Context, *DeleteProfileRequest) (*DeleteProfileResponse, error)
+	SwitchProfile(context.Context, *SwitchProfileRequest) (*SwitchProfileResponse, error)
+	ExportLogsBundle(context.Context, *ExportLogsBundleRequest) (*ExportLogsBundleResponse, error)
+	SetPACServer(context.Context, *SetPACServerRequest) (*PACServerResponse, error)
+	SetPortMapping(context.Context, *SetPortMappingRequest) (*PortMappingResponse, error)
+	GetPortMappings(context.Context, *GetPortMappingsRequest) (*PortMappingResponse, error)
+	ProbeInbound(context.Context, *ProbeInboundRequest) (*ProbeInboundResponse, error)
+	TestConnectivity(context.Context, *TestConnectivityRequest) (*TestConnectivityResponse, error)
+	URLTest(context.Context, *URLTestRequest) (*URLTestResponse, error)
+	GetPublicIP(context.Context, *GetPublicIPRequest) (*GetPublicIPResponse, error)
+	SetBindInterface(context.Context, *SetBindInterfaceRequest) (*SetBindInterfaceResponse, error)
+	AddSplitTunnelRule(context.Context, *SplitTunnelRule) (*SplitTunnelResponse, error)
+	RemoveSplitTunnelRule(context.Context, *RemoveSplitTunnelRuleRequest) (*SplitTunnelResponse, error)
+	ListSplitTunnelRules(context.Context, *ListSplitTunnelRulesRequest) (*SplitTunnelResponse, error)
+	SetDNSHijack(context.Context, *SetDNSHijackRequest) (*SetDNSHijackResponse, error)
+	ApplyRoutingPreset(context.Context, *ApplyRoutingPresetRequest) (*ApplyRoutingPresetResponse, error)
+	StreamTraffic(*TrafficRequest, grpc.ServerStreamingServer[TrafficSample]) error
+	GetStats(context.Context, *GetStatsRequest) (*StatsResponse, error)
+	SetLogLevel(context.Context, *SetLogLevelRequest) (*SetLogLevelResponse, error)
+	StreamLogs(*StreamLogsRequest, grpc.ServerStreamingServer[LogEntry]) error
+	RegisterParent(context.Context, *RegisterParentRequest) (*RegisterParentResponse, error)
+	LookupRule(context.Context, *LookupRuleRequest) (*LookupRuleResponse, error)
+	TraceRoute(context.Context, *TraceRouteRequest) (*TraceRouteResponse, error)
+	SetEndpointPool(context.Context, *SetEndpointPoolRequest) (*EndpointPoolResponse, error)
+	GetEndpointPool(context.Context, *GetEndpointPoolRequest) (*EndpointPoolResponse, error)
+	GetCacheFile(context.Context, *GetCacheFileRequest) (*CacheFileResponse, error)
+	ClearCacheFile(context.Context, *ClearCacheFileRequest) (*CacheFileResponse, error)
+	SetCacheFile(context.Context, *SetCacheFileRequest) (*CacheFileResponse, error)
+	RegisterHook(grpc.BidiStreamingServer[HookMessage, HookEvent]) error
+	GetRulesetInfo(context.Context, *GetRulesetInfoRequest) (*RulesetInfoResponse, error)
+	RepairTunDriver(context.Context, *RepairTunDriverRequest) (*RepairTunDriverResponse, error)
+	mustEmbedUnimplementedOblivionServiceServer()
+}
+
+// UnimplementedOblivionServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedOblivionServiceServer struct{}
+
+func (UnimplementedOblivionServiceServer) Start(context.Context, *StartRequest) (*StartResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Start not implemented")
+}
+func (UnimplementedOblivionServiceServer) Stop(context.Context, *StopRequest) (*StopResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stop not implemented")
+}
+func (UnimplementedOblivionServiceServer) Restart(context.Context, *RestartRequest) (*RestartResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Restart not implemented")
+}
+func (UnimplementedOblivionServiceServer) Reload(context.Context, *ReloadRequest) (*ReloadResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reload not implemented")
+}
+func (UnimplementedOblivionServiceServer) StreamStatus(*StatusRequest, grpc.ServerStreamingServer[StatusResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamStatus not implemented")
+}
+func (UnimplementedOblivionServiceServer) StreamEvents(*StreamEventsRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamEvents not implemented")
+}
+func (UnimplementedOblivionServiceServer) Exit(context.Context, *ExitRequest) (*ExitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Exit not implemented")
+}
+func (UnimplementedOblivionServiceServer) GetStatus(context.Context, *GetStatusRequest) (*GetStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedOblivionServiceServer) GetReliabilityHistory(context.Context, *ReliabilityHistoryRequest) (*ReliabilityHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReliabilityHistory not implemented")
+}
+func (UnimplementedOblivionServiceServer) Pause(context.Context, *PauseRequest) (*PauseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Pause not implemented")
+}
+func (UnimplementedOblivionServiceServer) Resume(context.Context, *ResumeRequest) (*ResumeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Resume not implemented")
+}
+func (UnimplementedOblivionServiceServer) SetSystemProxy(context.Context, *SetSystemProxyRequest) (*SystemProxyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSystemProxy not implemented")
+}
+func (UnimplementedOblivionServiceServer) ClearSystemProxy(context.Context, *ClearSystemProxyRequest) (*SystemProxyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClearSystemProxy not implemented")
+}
+func (UnimplementedOblivionServiceServer) GetClashMode(context.Context, *GetClashModeRequest) (*ClashModeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetClashMode not implemented")
+}
+func (UnimplementedOblivionServiceServer) SetClashMode(context.Context, *SetClashModeRequest) (*ClashModeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetClashMode not implemented")
+}
+func (UnimplementedOblivionServiceServer) GetOutboundGroups(context.Context, *GetOutboundGroupsRequest) (*OutboundGroupsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOutboundGroups not implemented")
+}
+func (UnimplementedOblivionServiceServer) SelectOutbound(context.Context, *SelectOutboundRequest) (*SelectOutboundResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SelectOutbound not implemented")
+}
+func (UnimplementedOblivionServiceServer) ListOutbounds(context.Context, *ListOutboundsRequest) (*ListOutboundsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListOutbounds not implemented")
+}
+func (UnimplementedOblivionServiceServer) GetUsageHistory(context.Context, *UsageHistoryRequest) (*UsageHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUsageHistory not implemented")
+}
+func (UnimplementedOblivionServiceServer) GetUsageThresholds(context.Context, *GetUsageThresholdsRequest) (*UsageThresholdsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUsageThresholds not implemented")
+}
+func (UnimplementedOblivionServiceServer) SetUsageThresholds(context.Context, *SetUsageThresholdsRequest) (*UsageThresholdsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetUsageThresholds not implemented")
+}
+func (UnimplementedOblivionServiceServer) SetRateLimit(context.Context, *SetRateLimitRequest) (*RateLimitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetRateLimit not implemented")
+}
+func (UnimplementedOblivionServiceServer) SetConnectionLog(context.Context, *SetConnectionLogRequest) (*ConnectionLogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetConnectionLog not implemented")
+}
+func (UnimplementedOblivionServiceServer) QueryDNS(context.Context, *QueryDNSRequest) (*QueryDNSResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryDNS not implemented")
+}
+func (UnimplementedOblivionServiceServer) SetDNSServers(context.Context, *SetDNSServersRequest) (*DNSServersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetDNSServers not implemented")
+}
+func (UnimplementedOblivionServiceServer) FlushDNSCache(context.Context, *FlushDNSCacheRequest) (*FlushDNSCacheResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FlushDNSCache not implemented")
+}
+func (UnimplementedOblivionServiceServer) GetFakeIPMappings(context.Context, *FakeIPMappingsRequest) (*FakeIPMappingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFakeIPMappings not implemented")
+}
+func (UnimplementedOblivionServiceServer) ResetFakeIP(context.Context, *ResetFakeIPRequest) (*ResetFakeIPResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResetFakeIP not implemented")
+}
+func (UnimplementedOblivionServiceServer) GetConnections(context.Context, *GetConnectionsRequest) (*GetConnectionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConnections not implemented")
+}
+func (UnimplementedOblivionServiceServer) CloseConnections(context.Context, *CloseConnectionsRequest) (*CloseConnectionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CloseConnections not implemented")
+}
+func (UnimplementedOblivionServiceServer) AddInbound(context.Context, *AddInboundRequest) (*InboundResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddInbound not implemented")
+}
+func (UnimplementedOblivionServiceServer) RemoveInbound(context.Context, *RemoveInboundRequest) (*InboundResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveInbound not implemented")
+}
+func (UnimplementedOblivionServiceServer) SetInboundPort(context.Context, *SetInboundPortRequest) (*SetInboundPortResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetInboundPort not implemented")
+}
+func (UnimplementedOblivionServiceServer) GetSystemInfo(context.Context, *GetSystemInfoRequest) (*SystemInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSystemInfo not implemented")
+}
+func (UnimplementedOblivionServiceServer) GetVersion(context.Context, *GetVersionRequest) (*VersionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVersion not implemented")
+}
+func (UnimplementedOblivionServiceServer) GetResourceUsage(context.Context, *GetResourceUsageRequest) (*ResourceUsage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetResourceUsage not implemented")
+}
+func (UnimplementedOblivionServiceServer) StreamResourceUsage(*StreamResourceUsageRequest, grpc.ServerStreamingServer[ResourceUsage]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamResourceUsage not implemented")
+}
+func (UnimplementedOblivionServiceServer) Ping(context.Context, *PingRequest) (*PingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Ping not implemented")
+}
+func (UnimplementedOblivionServiceServer) TestConfig(context.Context, *TestConfigRequest) (*TestConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TestConfig not implemented")
+}
+func (UnimplementedOblivionServiceServer) SetConfig(context.Context, *SetConfigRequest) (*SetConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetConfig not implemented")
+}
+func (UnimplementedOblivionServiceServer) GetConfig(context.Context, *GetConfigRequest) (*GetConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConfig not implemented")
+}
+func (UnimplementedOblivionServiceServer) ListProfiles(context.Context, *ListProfilesRequest) (*ListProfilesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListProfiles not implemented")
+}
+func (UnimplementedOblivionServiceServer) SaveProfile(context.Context, *SaveProfileRequest) (*SaveProfileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SaveProfile not implemented")
+}
+func (UnimplementedOblivionServiceServer) DeleteProfile(context.Context, *DeleteProfileRequest) (*DeleteProfileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteProfile not implemented")
+}
+func (UnimplementedOblivionServiceServer) SwitchProfile(context.Context, *SwitchProfileRequest) (*SwitchProfileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SwitchProfile not implemented")
+}
+func (UnimplementedOblivionServiceServer) ExportLogsBundle(context.Context, *ExportLogsBundleRequest) (*ExportLogsBundleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportLogsBundle not implemented")
+}
+func (UnimplementedOblivionServiceServer) SetPACServer(context.Context, *SetPACServerRequest) (*PACServerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetPACServer not implemented")
+}
+func (UnimplementedOblivionServiceServer) SetPortMapping(context.Context, *SetPortMappingRequest) (*PortMappingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetPortMapping not implemented")
+}
+func (UnimplementedOblivionServiceServer) GetPortMappings(context.Context, *GetPortMappingsRequest) (*PortMappingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPortMappings not implemented")
+}
+func (UnimplementedOblivionServiceServer) ProbeInbound(context.Context, *ProbeInboundRequest) (*ProbeInboundResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ProbeInbound not implemented")
+}
+func (UnimplementedOblivionServiceServer) TestConnectivity(context.Context, *TestConnectivityRequest) (*TestConnectivityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TestConnectivity not implemented")
+}
+func (UnimplementedOblivionServiceServer) URLTest(context.Context, *URLTestRequest) (*URLTestResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method URLTest not implemented")
+}
+func (UnimplementedOblivionServiceServer) GetPublicIP(context.Context, *GetPublicIPRequest) (*GetPublicIPResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPublicIP not implemented")
+}
+func (UnimplementedOblivionServiceServer) SetBindInterface(context.Context, *SetBindInterfaceRequest) (*SetBindInterfaceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetBindInterface not implemented")
+}
+func (UnimplementedOblivionServiceServer) AddSplitTunnelRule(context.Context, *SplitTunnelRule) (*SplitTunnelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddSplitTunnelRule not implemented")
+}
+func (UnimplementedOblivionServiceServer) RemoveSplitTunnelRule(context.Context, *RemoveSplitTunnelRuleRequest) (*SplitTunnelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveSplitTunnelRule not implemented")
+}
+func (UnimplementedOblivionServiceServer) ListSplitTunnelRules(context.Context, *ListSplitTunnelRulesRequest) (*SplitTunnelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSplitTunnelRules not implemented")
+}
+func (UnimplementedOblivionServiceServer) SetDNSHijack(context.Context, *SetDNSHijackRequest) (*SetDNSHijackResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetDNSHijack not implemented")
+}
+func (UnimplementedOblivionServiceServer) ApplyRoutingPreset(context.Context, *ApplyRoutingPresetRequest) (*ApplyRoutingPresetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ApplyRoutingPreset not implemented")
+}
+func (UnimplementedOblivionServiceServer) StreamTraffic(*TrafficRequest, grpc.ServerStreamingServer[TrafficSample]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamTraffic not implemented")
+}
+func (UnimplementedOblivionServiceServer) GetStats(context.Context, *GetStatsRequest) (*StatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedOblivionServiceServer) SetLogLevel(context.Context, *SetLogLevelRequest) (*SetLogLevelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLogLevel not implemented")
+}
+func (UnimplementedOblivionServiceServer) StreamLogs(*StreamLogsRequest, grpc.ServerStreamingServer[LogEntry]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamLogs not implemented")
+}
+func (UnimplementedOblivionServiceServer) RegisterParent(context.Context, *RegisterParentRequest) (*RegisterParentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterParent not implemented")
+}
+func (UnimplementedOblivionServiceServer) LookupRule(context.Context, *LookupRuleRequest) (*LookupRuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LookupRule not implemented")
+}
+func (UnimplementedOblivionServiceServer) TraceRoute(context.Context, *TraceRouteRequest) (*TraceRouteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TraceRoute not implemented")
+}
+func (UnimplementedOblivionServiceServer) SetEndpointPool(context.Context, *SetEndpointPoolRequest) (*EndpointPoolResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetEndpointPool not implemented")
+}
+func (UnimplementedOblivionServiceServer) GetEndpointPool(context.Context, *GetEndpointPoolRequest) (*EndpointPoolResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEndpointPool not implemented")
+}
+func (UnimplementedOblivionServiceServer) GetCacheFile(context.Context, *GetCacheFileRequest) (*CacheFileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCacheFile not implemented")
+}
+func (UnimplementedOblivionServiceServer) ClearCacheFile(context.Context, *ClearCacheFileRequest) (*CacheFileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClearCacheFile not implemented")
+}
+func (UnimplementedOblivionServiceServer) SetCacheFile(context.Context, *SetCacheFileRequest) (*CacheFileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetCacheFile not implemented")
+}
+func (UnimplementedOblivionServiceServer) RegisterHook(grpc.BidiStreamingServer[HookMessage, HookEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method RegisterHook not implemented")
+}
+func (UnimplementedOblivionServiceServer) GetRulesetInfo(context.Context, *GetRulesetInfoRequest) (*RulesetInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRulesetInfo not implemented")
+}
+func (UnimplementedOblivionServiceServer) RepairTunDriver(context.Context, *RepairTunDriverRequest) (*RepairTunDriverResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RepairTunDriver not implemented")
+}
+func (UnimplementedOblivionServiceServer) mustEmbedUnimplementedOblivionServiceServer() {}
+func (UnimplementedOblivionServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafeOblivionServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to OblivionServiceServer will
+// result in compilation errors.
+type UnsafeOblivionServiceServer interface {
+	mustEmbedUnimplementedOblivionServiceServer()
+}
+
+func RegisterOblivionServiceServer(s grpc.ServiceRegistrar, srv OblivionServiceServer) {
+	// If the following call pancis, it indicates UnimplementedOblivionServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&OblivionService_ServiceDesc, srv)
+}
+
+func _OblivionService_Start_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).Start(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_Start_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).Start(ctx, req.(*StartRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_Stop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StopRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).Stop(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_Stop_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).Stop(ctx, req.(*StopRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_Restart_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestartRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).Restart(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_Restart_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).Restart(ctx, req.(*RestartRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_Reload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReloadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).Reload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_Reload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).Reload(ctx, req.(*ReloadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_StreamStatus_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StatusRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OblivionServiceServer).StreamStatus(m, &grpc.GenericServerStream[StatusRequest, StatusResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OblivionService_StreamStatusServer = grpc.ServerStreamingServer[StatusResponse]
+
+func _OblivionService_StreamEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OblivionServiceServer).StreamEvents(m, &grpc.GenericServerStream[StreamEventsRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OblivionService_StreamEventsServer = grpc.ServerStreamingServer[Event]
+
+func _OblivionService_Exit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).Exit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_Exit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).Exit(ctx, req.(*ExitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).GetStatus(ctx, req.(*GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_GetReliabilityHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReliabilityHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).GetReliabilityHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_GetReliabilityHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).GetReliabilityHistory(ctx, req.(*ReliabilityHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_Pause_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PauseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).Pause(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_Pause_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).Pause(ctx, req.(*PauseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_Resume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResumeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).Resume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_Resume_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).Resume(ctx, req.(*ResumeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_SetSystemProxy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetSystemProxyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).SetSystemProxy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_SetSystemProxy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).SetSystemProxy(ctx, req.(*SetSystemProxyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_ClearSystemProxy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClearSystemProxyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).ClearSystemProxy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_ClearSystemProxy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).ClearSystemProxy(ctx, req.(*ClearSystemProxyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_GetClashMode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetClashModeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).GetClashMode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_GetClashMode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).GetClashMode(ctx, req.(*GetClashModeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_SetClashMode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetClashModeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).SetClashMode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_SetClashMode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).SetClashMode(ctx, req.(*SetClashModeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_GetOutboundGroups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOutboundGroupsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).GetOutboundGroups(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_GetOutboundGroups_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).GetOutboundGroups(ctx, req.(*GetOutboundGroupsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_SelectOutbound_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SelectOutboundRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).SelectOutbound(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_SelectOutbound_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).SelectOutbound(ctx, req.(*SelectOutboundRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_ListOutbounds_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListOutboundsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).ListOutbounds(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_ListOutbounds_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).ListOutbounds(ctx, req.(*ListOutboundsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_GetUsageHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UsageHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).GetUsageHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_GetUsageHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).GetUsageHistory(ctx, req.(*UsageHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_GetUsageThresholds_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUsageThresholdsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).GetUsageThresholds(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_GetUsageThresholds_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).GetUsageThresholds(ctx, req.(*GetUsageThresholdsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_SetUsageThresholds_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetUsageThresholdsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).SetUsageThresholds(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_SetUsageThresholds_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).SetUsageThresholds(ctx, req.(*SetUsageThresholdsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_SetRateLimit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetRateLimitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).SetRateLimit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_SetRateLimit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).SetRateLimit(ctx, req.(*SetRateLimitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_SetConnectionLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetConnectionLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).SetConnectionLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_SetConnectionLog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).SetConnectionLog(ctx, req.(*SetConnectionLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_QueryDNS_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryDNSRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).QueryDNS(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_QueryDNS_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).QueryDNS(ctx, req.(*QueryDNSRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_SetDNSServers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetDNSServersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).SetDNSServers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_SetDNSServers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).SetDNSServers(ctx, req.(*SetDNSServersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_FlushDNSCache_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FlushDNSCacheRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).FlushDNSCache(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_FlushDNSCache_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).FlushDNSCache(ctx, req.(*FlushDNSCacheRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_GetFakeIPMappings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FakeIPMappingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).GetFakeIPMappings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_GetFakeIPMappings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).GetFakeIPMappings(ctx, req.(*FakeIPMappingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_ResetFakeIP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResetFakeIPRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).ResetFakeIP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_ResetFakeIP_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).ResetFakeIP(ctx, req.(*ResetFakeIPRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_GetConnections_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConnectionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).GetConnections(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_GetConnections_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).GetConnections(ctx, req.(*GetConnectionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_CloseConnections_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CloseConnectionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).CloseConnections(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_CloseConnections_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).CloseConnections(ctx, req.(*CloseConnectionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_AddInbound_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddInboundRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).AddInbound(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_AddInbound_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).AddInbound(ctx, req.(*AddInboundRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_RemoveInbound_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveInboundRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).RemoveInbound(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_RemoveInbound_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).RemoveInbound(ctx, req.(*RemoveInboundRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_SetInboundPort_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetInboundPortRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).SetInboundPort(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_SetInboundPort_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).SetInboundPort(ctx, req.(*SetInboundPortRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_GetSystemInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSystemInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).GetSystemInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_GetSystemInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).GetSystemInfo(ctx, req.(*GetSystemInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_GetVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetVersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).GetVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_GetVersion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).GetVersion(ctx, req.(*GetVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_GetResourceUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetResourceUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).GetResourceUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_GetResourceUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).GetResourceUsage(ctx, req.(*GetResourceUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_StreamResourceUsage_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamResourceUsageRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OblivionServiceServer).StreamResourceUsage(m, &grpc.GenericServerStream[StreamResourceUsageRequest, ResourceUsage]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OblivionService_StreamResourceUsageServer = grpc.ServerStreamingServer[ResourceUsage]
+
+func _OblivionService_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).Ping(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_Ping_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).Ping(ctx, req.(*PingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_TestConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TestConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).TestConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_TestConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).TestConfig(ctx, req.(*TestConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_SetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).SetConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_SetConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).SetConfig(ctx, req.(*SetConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_GetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).GetConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_GetConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).GetConfig(ctx, req.(*GetConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_ListProfiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListProfilesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).ListProfiles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_ListProfiles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).ListProfiles(ctx, req.(*ListProfilesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_SaveProfile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SaveProfileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).SaveProfile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_SaveProfile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).SaveProfile(ctx, req.(*SaveProfileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_DeleteProfile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteProfileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).DeleteProfile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_DeleteProfile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).DeleteProfile(ctx, req.(*DeleteProfileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_SwitchProfile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SwitchProfileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).SwitchProfile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_SwitchProfile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).SwitchProfile(ctx, req.(*SwitchProfileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_ExportLogsBundle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportLogsBundleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).ExportLogsBundle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_ExportLogsBundle_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).ExportLogsBundle(ctx, req.(*ExportLogsBundleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_SetPACServer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetPACServerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).SetPACServer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_SetPACServer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).SetPACServer(ctx, req.(*SetPACServerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_SetPortMapping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetPortMappingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).SetPortMapping(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_SetPortMapping_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).SetPortMapping(ctx, req.(*SetPortMappingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_GetPortMappings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPortMappingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).GetPortMappings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_GetPortMappings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).GetPortMappings(ctx, req.(*GetPortMappingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_ProbeInbound_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProbeInboundRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).ProbeInbound(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_ProbeInbound_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).ProbeInbound(ctx, req.(*ProbeInboundRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_TestConnectivity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TestConnectivityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).TestConnectivity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_TestConnectivity_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).TestConnectivity(ctx, req.(*TestConnectivityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_URLTest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(URLTestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).URLTest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_URLTest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).URLTest(ctx, req.(*URLTestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_GetPublicIP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPublicIPRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).GetPublicIP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_GetPublicIP_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).GetPublicIP(ctx, req.(*GetPublicIPRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_SetBindInterface_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetBindInterfaceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).SetBindInterface(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_SetBindInterface_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).SetBindInterface(ctx, req.(*SetBindInterfaceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_AddSplitTunnelRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SplitTunnelRule)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).AddSplitTunnelRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_AddSplitTunnelRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).AddSplitTunnelRule(ctx, req.(*SplitTunnelRule))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_RemoveSplitTunnelRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveSplitTunnelRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).RemoveSplitTunnelRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_RemoveSplitTunnelRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).RemoveSplitTunnelRule(ctx, req.(*RemoveSplitTunnelRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_ListSplitTunnelRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSplitTunnelRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).ListSplitTunnelRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_ListSplitTunnelRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).ListSplitTunnelRules(ctx, req.(*ListSplitTunnelRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_SetDNSHijack_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetDNSHijackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).SetDNSHijack(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_SetDNSHijack_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).SetDNSHijack(ctx, req.(*SetDNSHijackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_ApplyRoutingPreset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApplyRoutingPresetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).ApplyRoutingPreset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_ApplyRoutingPreset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).ApplyRoutingPreset(ctx, req.(*ApplyRoutingPresetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_StreamTraffic_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TrafficRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OblivionServiceServer).StreamTraffic(m, &grpc.GenericServerStream[TrafficRequest, TrafficSample]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OblivionService_StreamTrafficServer = grpc.ServerStreamingServer[TrafficSample]
+
+func _OblivionService_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_GetStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_SetLogLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLogLevelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).SetLogLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_SetLogLevel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).SetLogLevel(ctx, req.(*SetLogLevelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_StreamLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamLogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OblivionServiceServer).StreamLogs(m, &grpc.GenericServerStream[StreamLogsRequest, LogEntry]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OblivionService_StreamLogsServer = grpc.ServerStreamingServer[LogEntry]
+
+func _OblivionService_RegisterParent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterParentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).RegisterParent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_RegisterParent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).RegisterParent(ctx, req.(*RegisterParentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_LookupRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LookupRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).LookupRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_LookupRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).LookupRule(ctx, req.(*LookupRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_TraceRoute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TraceRouteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).TraceRoute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_TraceRoute_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).TraceRoute(ctx, req.(*TraceRouteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_SetEndpointPool_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetEndpointPoolRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).SetEndpointPool(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_SetEndpointPool_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).SetEndpointPool(ctx, req.(*SetEndpointPoolRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_GetEndpointPool_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEndpointPoolRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).GetEndpointPool(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_GetEndpointPool_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).GetEndpointPool(ctx, req.(*GetEndpointPoolRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_GetCacheFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCacheFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).GetCacheFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_GetCacheFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).GetCacheFile(ctx, req.(*GetCacheFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_ClearCacheFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClearCacheFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).ClearCacheFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_ClearCacheFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).ClearCacheFile(ctx, req.(*ClearCacheFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_SetCacheFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetCacheFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).SetCacheFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_SetCacheFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).SetCacheFile(ctx, req.(*SetCacheFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_RegisterHook_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(OblivionServiceServer).RegisterHook(&grpc.GenericServerStream[HookMessage, HookEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OblivionService_RegisterHookServer = grpc.BidiStreamingServer[HookMessage, HookEvent]
+
+func _OblivionService_GetRulesetInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRulesetInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).GetRulesetInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_GetRulesetInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).GetRulesetInfo(ctx, req.(*GetRulesetInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OblivionService_RepairTunDriver_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RepairTunDriverRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OblivionServiceServer).RepairTunDriver(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OblivionService_RepairTunDriver_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OblivionServiceServer).RepairTunDriver(ctx, req.(*RepairTunDriverRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// OblivionService_ServiceDesc is the grpc.ServiceDesc for OblivionService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var OblivionService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "oblivionHelper.OblivionService",
+	HandlerType: (*OblivionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Start",
+			Handler:    _OblivionService_Start_Handler,
+		},
+		{
+			MethodName: "Stop",
+			Handler:    _OblivionService_Stop_Handler,
+		},
+		{
+			MethodName: "Restart",
+			Handler:    _OblivionService_Restart_Handler,
+		},
+		{
+			MethodName: "Reload",
+			Handler:    _OblivionService_Reload_Handler,
+		},
+		{
+			MethodName: "Exit",
+			Handler:    _OblivionService_Exit_Handler,
+		},
+		{
+			MethodName: "GetStatus",
+			Handler:    _OblivionService_GetStatus_Handler,
+		},
+		{
+			MethodName: "GetReliabilityHistory",
+			Handler:    _OblivionService_GetReliabilityHistory_Handler,
+		},
+		{
+			MethodName: "Pause",
+			Handler:    _OblivionService_Pause_Handler,
+		},
+		{
+			MethodName: "Resume",
+			Handler:    _OblivionService_Resume_Handler,
+		},
+		{
+			MethodName: "SetSystemProxy",
+			Handler:    _OblivionService_SetSystemProxy_Handler,
+		},
+		{
+			MethodName: "ClearSystemProxy",
+			Handler:    _OblivionService_ClearSystemProxy_Handler,
+		},
+		{
+			MethodName: "GetClashMode",
+			Handler:    _OblivionService_GetClashMode_Handler,
+		},
+		{
+			MethodName: "SetClashMode",
+			Handler:    _OblivionService_SetClashMode_Handler,
+		},
+		{
+			MethodName: "GetOutboundGroups",
+			Handler:    _OblivionService_GetOutboundGroups_Handler,
+		},
+		{
+			MethodName: "SelectOutbound",
+			Handler:    _OblivionService_SelectOutbound_Handler,
+		},
+		{
+			MethodName: "ListOutbounds",
+			Handler:    _OblivionService_ListOutbounds_Handler,
+		},
+		{
+			MethodName: "GetUsageHistory",
+			Handler:    _OblivionService_GetUsageHistory_Handler,
+		},
+		{
+			MethodName: "GetUsageThresholds",
+			Handler:    _OblivionService_GetUsageThresholds_Handler,
+		},
+		{
+			MethodName: "SetUsageThresholds",
+			Handler:    _OblivionService_SetUsageThresholds_Handler,
+		},
+		{
+			MethodName: "SetRateLimit",
+			Handler:    _OblivionService_SetRateLimit_Handler,
+		},
+		{
+			MethodName: "SetConnectionLog",
+			Handler:    _OblivionService_SetConnectionLog_Handler,
+		},
+		{
+			MethodName: "QueryDNS",
+			Handler:    _OblivionService_QueryDNS_Handler,
+		},
+		{
+			MethodName: "SetDNSServers",
+			Handler:    _OblivionService_SetDNSServers_Handler,
+		},
+		{
+			MethodName: "FlushDNSCache",
+			Handler:    _OblivionService_FlushDNSCache_Handler,
+		},
+		{
+			MethodName: "GetFakeIPMappings",
+			Handler:    _OblivionService_GetFakeIPMappings_Handler,
+		},
+		{
+			MethodName: "ResetFakeIP",
+			Handler:    _OblivionService_ResetFakeIP_Handler,
+		},
+		{
+			MethodName: "GetConnections",
+			Handler:    _OblivionService_GetConnections_Handler,
+		},
+		{
+			MethodName: "CloseConnections",
+			Handler:    _OblivionService_CloseConnections_Handler,
+		},
+		{
+			MethodName: "AddInbound",
+			Handler:    _OblivionService_AddInbound_Handler,
+		},
+		{
+			MethodName: "RemoveInbound",
+			Handler:    _OblivionService_RemoveInbound_Handler,
+		},
+		{
+			MethodName: "SetInboundPort",
+			Handler:    _OblivionService_SetInboundPort_Handler,
+		},
+		{
+			MethodName: "GetSystemInfo",
+			Handler:    _OblivionService_GetSystemInfo_Handler,
+		},
+		{
+			MethodName: "GetVersion",
+			Handler:    _OblivionService_GetVersion_Handler,
+		},
+		{
+			MethodName: "GetResourceUsage",
+			Handler:    _OblivionService_GetResourceUsage_Handler,
+		},
+		{
+			MethodName: "Ping",
+			Handler:    _OblivionService_Ping_Handler,
+		},
+		{
+			MethodName: "TestConfig",
+			Handler:    _OblivionService_TestConfig_Handler,
+		},
+		{
+			MethodName: "SetConfig",
+			Handler:    _OblivionService_SetConfig_Handler,
+		},
+		{
+			MethodName: "GetConfig",
+			Handler:    _OblivionService_GetConfig_Handler,
+		},
+		{
+			MethodName: "ListProfiles",
+			Handler:    _OblivionService_ListProfiles_Handler,
+		},
+		{
+			MethodName: "SaveProfile",
+			Handler:    _OblivionService_SaveProfile_Handler,
+		},
+		{
+			MethodName: "DeleteProfile",
+			Handler:    _OblivionService_DeleteProfile_Handler,
+		},
+		{
+			MethodName: "SwitchProfile",
+			Handler:    _OblivionService_SwitchProfile_Handler,
+		},
+		{
+			MethodName: "ExportLogsBundle",
+			Handler:    _OblivionService_ExportLogsBundle_Handler,
+		},
+		{
+			MethodName: "SetPACServer",
+			Handler:    _OblivionService_SetPACServer_Handler,
+		},
+		{
+			MethodName: "SetPortMapping",
+			Handler:    _OblivionService_SetPortMapping_Handler,
+		},
+		{
+			MethodName: "GetPortMappings",
+			Handler:    _OblivionService_GetPortMappings_Handler,
+		},
+		{
+			MethodName: "ProbeInbound",
+			Handler:    _OblivionService_ProbeInbound_Handler,
+		},
+		{
+			MethodName: "TestConnectivity",
+			Handler:    _OblivionService_TestConnectivity_Handler,
+		},
+		{
+			MethodName: "URLTest",
+			Handler:    _OblivionService_URLTest_Handler,
+		},
+		{
+			MethodName: "GetPublicIP",
+			Handler:    _OblivionService_GetPublicIP_Handler,
+		},
+		{
+			MethodName: "SetBindInterface",
+			Handler:    _OblivionService_SetBindInterface_Handler,
+		},
+		{
+			MethodName: "AddSplitTunnelRule",
+			Handler:    _OblivionService_AddSplitTunnelRule_Handler,
+		},
+		{
+			MethodName: "RemoveSplitTunnelRule",
+			Handler:    _OblivionService_RemoveSplitTunnelRule_Handler,
+		},
+		{
+			MethodName: "ListSplitTunnelRules",
+			Handler:    _OblivionService_ListSplitTunnelRules_Handler,
+		},
+		{
+			MethodName: "SetDNSHijack",
+			Handler:    _OblivionService_SetDNSHijack_Handler,
+		},
+		{
+			MethodName: "ApplyRoutingPreset",
+			Handler:    _OblivionService_ApplyRoutingPreset_Handler,
+		},
+		{
+			MethodName: "GetStats",
+			Handler:    _OblivionService_GetStats_Handler,
+		},
+		{
+			MethodName: "SetLogLevel",
+			Handler:    _OblivionService_SetLogLevel_Handler,
+		},
+		{
+			MethodName: "RegisterParent",
+			Handler:    _OblivionService_RegisterParent_Handler,
+		},
+		{
+			MethodName: "LookupRule",
+			Handler:    _OblivionService_LookupRule_Handler,
+		},
+		{
+			MethodName: "TraceRoute",
+			Handler:    _OblivionService_TraceRoute_Handler,
+		},
+		{
+			MethodName: "SetEndpointPool",
+			Handler:    _OblivionService_SetEndpointPool_Handler,
+		},
+		{
+			MethodName: "GetEndpointPool",
+			Handler:    _OblivionService_GetEndpointPool_Handler,
+		},
+		{
+			MethodName: "GetCacheFile",
+			Handler:    _OblivionService_GetCacheFile_Handler,
+		},
+		{
+			MethodName: "ClearCacheFile",
+			Handler:    _OblivionService_ClearCacheFile_Handler,
+		},
+		{
+			MethodName: "SetCacheFile",
+			Handler:    _OblivionService_SetCacheFile_Handler,
+		},
+		{
+			MethodName: "GetRulesetInfo",
+			Handler:    _OblivionService_GetRulesetInfo_Handler,
+		},
+		{
+			MethodName: "RepairTunDriver",
+			Handler:    _OblivionService_RepairTunDriver_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamStatus",
+			Handler:       _OblivionService_StreamStatus_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamEvents",
+			Handler:       _OblivionService_StreamEvents_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamResourceUsage",
+			Handler:       _OblivionService_StreamResourceUsage_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamTraffic",
+			Handler:       _OblivionService_StreamTraffic_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamLogs",
+			Handler:       _OblivionService_StreamLogs_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "RegisterHook",
+			Handler:       _OblivionService_RegisterHook_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "proto/oblivion.proto",
+}
@@ -0,0 +1,244 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package broadcaster fans status updates of the helper out to the status stream
+package broadcaster
+
+import (
+	"sync"
+	"time"
+)
+
+// Event is a single update sent on the status stream
+type Event struct {
+	Status string
+	Detail string // Optional context, e.g. the crossed threshold of a usage alert
+
+	CorrelationID string // ID of the request that caused the update, empty for background changes
+}
+
+// Broadcaster delivers status updates to every subscribed status stream and remembers the current status.
+// Transient statuses published in quick succession are coalesced so only the latest one is delivered,
+// while terminal statuses are always delivered.
+type Broadcaster struct {
+	mu          sync.Mutex
+	current     string
+	capacity    int                        // Updates buffered per subscriber
+	subscribers map[*Subscription]struct{} // Open subscriptions
+	window      time.Duration              // Time a transient status is held back for a newer one
+	terminal    map[string]bool            // Statuses that are never coalesced
+	pending     *Event                     // Transient status waiting for the window to end
+	timer       *time.Timer
+	closed      bool
+	onDrop      func(Event)
+}
+
+// New creates a broadcaster that buffers up to capacity updates per subscriber, with the given initial status.
+// Statuses other than the terminal ones are held back for window, and replaced if a newer status arrives meanwhile.
+// onDrop, if not nil, is called with the oldest update when a slow subscriber's buffer overflows.
+func New(capacity int, initial string, window time.Duration, terminal []string, onDrop func(Event)) *Broadcaster {
+	b := &Broadcaster{
+		current:     initial,
+		capacity:    capacity,
+		subscribers: make(map[*Subscription]struct{}),
+		window:      window,
+		terminal:    make(map[string]bool, len(terminal)),
+		onDrop:      onDrop,
+	}
+	for _, status := range terminal {
+		b.terminal[status] = true
+	}
+	return b
+}
+
+// Publish changes the current status and queues the event, coalescing it with other transient statuses
+func (b *Broadcaster) Publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.current = event.Status
+
+	if b.window <= 0 || b.terminal[event.Status] {
+		// A terminal status supersedes any transient one still waiting
+		b.pending = nil
+		if b.timer != nil {
+			b.timer.Stop()
+		}
+		b.send(event)
+		return
+	}
+
+	if b.pending == nil {
+		if b.timer == nil {
+			b.timer = time.AfterFunc(b.window, b.flush)
+		} else {
+			b.timer.Reset(b.window)
+		}
+	}
+	b.pending = &event
+}
+
+// Notify queues an update right away without changing the current status, used for notifications such as usage alerts
+func (b *Broadcaster) Notify(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.closed {
+		b.send(event)
+	}
+}
+
+// flush delivers the pending transient status once its window has passed
+func (b *Broadcaster) flush() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.pending != nil && !b.closed {
+		b.send(*b.pending)
+		b.pending = nil
+	}
+}
+
+// send queues an event for every subscriber; the caller must hold b.mu
+func (b *Broadcaster) send(event Event) {
+	for subscription := range b.subscribers {
+		subscription.push(event)
+	}
+}
+
+// Current returns the last published status
+func (b *Broadcaster) Current() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.current
+}
+
+// Subscribe starts delivering updates to a new subscription. Call Close on it when the stream ends.
+func (b *Broadcaster) Subscribe() *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	subscription := &Subscription{
+		broadcaster: b,
+		ready:       make(chan struct{}, 1),
+	}
+	if b.closed {
+		subscription.closed = true
+		subscription.ready <- struct{}{}
+		return subscription
+	}
+	b.subscribers[subscription] = struct{}{}
+	return subscription
+}
+
+// Close delivers the pending status and ends every subscription
+func (b *Broadcaster) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+	if b.pending != nil {
+		b.send(*b.pending)
+		b.pending = nil
+	}
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	b.closed = true
+	for subscription := range b.subscribers {
+		subscription.end()
+	}
+	b.subscribers = nil
+}
+
+// Subscription buffers the updates for one status stream. When the stream falls behind, the oldest updates
+// are dropped, so the most recent status is never lost.
+type Subscription struct {
+	broadcaster *Broadcaster
+	mu          sync.Mutex
+	queue       []Event
+	ready       chan struct{} // Signaled when updates are queued or the subscription ends
+	closed      bool
+}
+
+// push queues an event, dropping the oldest one if the buffer is full
+func (s *Subscription) push(event Event) {
+	s.mu.Lock()
+	if len(s.queue) >= s.broadcaster.capacity {
+		dropped := s.queue[0]
+		s.queue = s.queue[1:]
+		if s.broadcaster.onDrop != nil {
+			s.broadcaster.onDrop(dropped)
+		}
+	}
+	s.queue = append(s.queue, event)
+	s.mu.Unlock()
+
+	s.signal()
+}
+
+// end marks the subscription as finished once the queued updates are read
+func (s *Subscription) end() {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+
+	s.signal()
+}
+
+// signal wakes up the reader without blocking
+func (s *Subscription) signal() {
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Ready is signaled when updates are available or the subscription has ended
+func (s *Subscription) Ready() <-chan struct{} {
+	return s.ready
+}
+
+// Next returns the oldest queued update. ok is false when the queue is empty.
+func (s *Subscription) Next() (event Event, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.queue) == 0 {
+		return Event{}, false
+	}
+	event = s.queue[0]
+	s.queue = s.queue[1:]
+	return event, true
+}
+
+// Done reports whether the broadcaster has closed and every queued update was read
+func (s *Subscription) Done() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed && len(s.queue) == 0
+}
+
+// Close stops delivering updates to the subscription
+func (s *Subscription) Close() {
+	s.broadcaster.mu.Lock()
+	defer s.broadcaster.mu.Unlock()
+	delete(s.broadcaster.subscribers, s)
+}
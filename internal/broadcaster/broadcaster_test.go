@@ -0,0 +1,145 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package broadcaster
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testWindow is the coalescing window of the tests, short enough to keep them fast
+const testWindow = 20 * time.Millisecond
+
+// drain returns the statuses queued for a subscription
+func drain(subscription *Subscription) []string {
+	var statuses []string
+	for {
+		event, ok := subscription.Next()
+		if !ok {
+			return statuses
+		}
+		statuses = append(statuses, event.Status)
+	}
+}
+
+func TestPublish(t *testing.T) {
+	tests := []struct {
+		name     string
+		window   time.Duration
+		capacity int
+		publish  []string
+		want     []string
+		dropped  []string
+	}{
+		{
+			name:     "transient statuses are coalesced",
+			window:   testWindow,
+			capacity: 8,
+			publish:  []string{"starting", "preparing", "reloading"},
+			want:     []string{"reloading"},
+		},
+		{
+			name:     "terminal status is delivered and supersedes pending ones",
+			window:   testWindow,
+			capacity: 8,
+			publish:  []string{"starting", "preparing", "started"},
+			want:     []string{"started"},
+		},
+		{
+			name:     "terminal statuses are never coalesced",
+			window:   testWindow,
+			capacity: 8,
+			publish:  []string{"started", "stopped", "started"},
+			want:     []string{"started", "stopped", "started"},
+		},
+		{
+			name:     "without a window every status is delivered",
+			capacity: 8,
+			publish:  []string{"starting", "preparing", "started"},
+			want:     []string{"starting", "preparing", "started"},
+		},
+		{
+			name:     "a full buffer drops the oldest update",
+			capacity: 2,
+			publish:  []string{"starting", "preparing", "started"},
+			want:     []string{"preparing", "started"},
+			dropped:  []string{"starting"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var dropped []string
+			b := New(test.capacity, "stopped", test.window, []string{"started", "stopped"}, func(event Event) {
+				dropped = append(dropped, event.Status)
+			})
+			subscription := b.Subscribe()
+			defer subscription.Close()
+
+			for _, status := range test.publish {
+				b.Publish(Event{Status: status})
+			}
+			time.Sleep(4 * test.window)
+
+			if got := drain(subscription); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("delivered %v, want %v", got, test.want)
+			}
+			if !reflect.DeepEqual(dropped, test.dropped) {
+				t.Errorf("dropped %v, want %v", dropped, test.dropped)
+			}
+			if current, want := b.Current(), test.publish[len(test.publish)-1]; current != want {
+				t.Errorf("current status %q, want %q", current, want)
+			}
+		})
+	}
+}
+
+func TestNotifyKeepsCurrentStatus(t *testing.T) {
+	b := New(8, "started", testWindow, nil, nil)
+	subscription := b.Subscribe()
+	defer subscription.Close()
+
+	b.Notify(Event{Status: "usage-alert", Detail: "daily:100:120"})
+
+	if got, want := drain(subscription), []string{"usage-alert"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("delivered %v, want %v", got, want)
+	}
+	if current := b.Current(); current != "started" {
+		t.Errorf("current status %q, want %q", current, "started")
+	}
+}
+
+func TestCloseDeliversPending(t *testing.T) {
+	b := New(8, "stopped", time.Hour, nil, nil)
+	subscription := b.Subscribe()
+
+	b.Publish(Event{Status: "starting"})
+	if subscription.Done() {
+		t.Fatal("subscription done before Close")
+	}
+	b.Close()
+
+	if got, want := drain(subscription), []string{"starting"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("delivered %v, want %v", got, want)
+	}
+	if !subscription.Done() {
+		t.Error("subscription not done after Close")
+	}
+	if late := b.Subscribe(); !late.Done() {
+		t.Error("subscription after Close is not done")
+	}
+}
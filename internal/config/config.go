@@ -0,0 +1,64 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package config loads the sing-box configuration managed by the helper
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	option "github.com/sagernet/sing-box/option"
+)
+
+// Errors returned by Load, so callers can tell a missing config from a broken one
+var (
+	ErrNotFound = errors.New("sing-box config not found")
+	ErrInvalid  = errors.New("failed to parse sing-box config")
+)
+
+// Transform rewrites the raw config before it is parsed, e.g. to apply settings changed at runtime
+type Transform func(content []byte) ([]byte, error)
+
+// Load reads and parses the sing-box config at path, applying the transforms in order
+func Load(path string, transforms ...Transform) (*option.Options, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w at %s", ErrNotFound, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sing-box config: %w", err)
+	}
+	return Parse(content, transforms...)
+}
+
+// Parse parses a sing-box config, applying the transforms in order
+func Parse(content []byte, transforms ...Transform) (*option.Options, error) {
+	var err error
+	for _, transform := range transforms {
+		content, err = transform(content)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+	}
+
+	var options option.Options
+	if err := json.Unmarshal(content, &options); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	return &options, nil
+}
@@ -0,0 +1,108 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// replace returns a transform replacing old with new in the config
+func replace(old, new string) Transform {
+	return func(content []byte) ([]byte, error) {
+		return bytes.ReplaceAll(content, []byte(old), []byte(new)), nil
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string // Config file content, no file if empty
+		transforms []Transform
+		wantErr    error
+		wantLevel  string
+	}{
+		{
+			name:    "missing file",
+			wantErr: ErrNotFound,
+		},
+		{
+			name:      "config without transforms",
+			content:   `{"log": {"level": "info"}}`,
+			wantLevel: "info",
+		},
+		{
+			name:       "override applied before parsing",
+			content:    `{"log": {"level": "info"}}`,
+			transforms: []Transform{replace(`"info"`, `"debug"`)},
+			wantLevel:  "debug",
+		},
+		{
+			name:    "transforms applied in order",
+			content: `{"log": {"level": "info"}}`,
+			// The fallback sees the override's output
+			transforms: []Transform{replace(`"info"`, `"warn"`), replace(`"warn"`, `"error"`)},
+			wantLevel:  "error",
+		},
+		{
+			name:    "failing transform",
+			content: `{"log": {"level": "info"}}`,
+			transforms: []Transform{func([]byte) ([]byte, error) {
+				return nil, errors.New("broken override")
+			}},
+			wantErr: ErrInvalid,
+		},
+		{
+			name:    "invalid JSON",
+			content: `{"log": `,
+			wantErr: ErrInvalid,
+		},
+		{
+			name:       "transform producing invalid JSON",
+			content:    `{"log": {"level": "info"}}`,
+			transforms: []Transform{replace(`}}`, `}`)},
+			wantErr:    ErrInvalid,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "sbConfig.json")
+			if test.content != "" {
+				if err := os.WriteFile(path, []byte(test.content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			options, err := Load(path, test.transforms...)
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("error %v, want %v", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if options.Log == nil || options.Log.Level != test.wantLevel {
+				t.Errorf("log options %+v, want level %q", options.Log, test.wantLevel)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package core manages the lifecycle of the embedded sing-box instance
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	box "github.com/sagernet/sing-box"
+	"github.com/sagernet/sing-box/adapter"
+	"github.com/sagernet/sing-box/log"
+	option "github.com/sagernet/sing-box/option"
+	"github.com/sagernet/sing/common"
+	"github.com/sagernet/sing/service"
+)
+
+// instance is the part of a sing-box instance the manager drives
+type instance interface {
+	Start() error
+	Close() error
+}
+
+// newInstance creates a sing-box instance, replaced by a stub in tests
+var newInstance = func(options box.Options) (instance, error) {
+	return box.New(options)
+}
+
+// Manager owns the running sing-box instance and the options it was started with.
+// It is not safe for concurrent use; callers serialize access.
+type Manager struct {
+	LogWriter log.PlatformWriter // Receives the log messages of every instance, may be nil
+
+	instance   instance
+	options    *option.Options
+	ctx        context.Context // Service registry of the running instance
+	logFactory log.Factory     // Logs components added to the running instance like the instance logs its own
+}
+
+// Start creates and starts an instance from options. prepare, if not nil, is called after the instance
+// is created and before it starts, e.g. to attach services to its router. If ctx ends before the instance
+// has started, the instance is closed and the context error is returned.
+func (m *Manager) Start(ctx context.Context, options *option.Options, prepare func(instance *box.Box)) error {
+	if m.instance != nil {
+		return fmt.Errorf("sing-box is already running")
+	}
+
+	// The instance registers its services, like the cache file, in this context
+	instanceCtx := service.ContextWithDefaultRegistry(context.Background())
+	instance, err := newInstance(box.Options{
+		Options:           *options,
+		Context:           instanceCtx,
+		PlatformLogWriter: m.LogWriter,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create sing-box instance: %w", err)
+	}
+
+	if prepare != nil {
+		sbInstance, _ := instance.(*box.Box)
+		prepare(sbInstance)
+	}
+
+	started := make(chan error, 1)
+	go func() {
+		started <- instance.Start()
+	}()
+
+	select {
+	case err = <-started:
+	case <-ctx.Done():
+		instance.Close() // Unblocks a start stuck on the network
+		<-started
+		return fmt.Errorf("sing-box did not start in time: %w", ctx.Err())
+	}
+	if err != nil {
+		instance.Close()
+		return fmt.Errorf("failed to start sing-box: %w", err)
+	}
+
+	// The instance keeps its log factory to itself, so added components log through one with the same options
+	logFactory, err := log.New(log.Options{
+		Context:        instanceCtx,
+		Options:        common.PtrValueOrDefault(options.Log),
+		BaseTime:       time.Now(),
+		PlatformWriter: m.LogWriter,
+	})
+	if err == nil {
+		err = logFactory.Start()
+	}
+	if err != nil {
+		logFactory = log.NewNOPFactory()
+	}
+
+	m.instance = instance
+	m.options = options
+	m.ctx = instanceCtx
+	m.logFactory = logFactory
+	return nil
+}
+
+// Stop closes the running instance
+func (m *Manager) Stop() error {
+	if m.instance == nil {
+		return fmt.Errorf("sing-box is not running")
+	}
+
+	if err := m.instance.Close(); err != nil {
+		return fmt.Errorf("failed to stop sing-box: %w", err)
+	}
+
+	m.logFactory.Close()
+	m.instance = nil
+	m.options = nil
+	m.ctx = nil
+	m.logFactory = nil
+	return nil
+}
+
+// Running reports whether an instance is running
+func (m *Manager) Running() bool {
+	return m.instance != nil
+}
+
+// Instance returns the running instance, or nil
+func (m *Manager) Instance() *box.Box {
+	sbInstance, _ := m.instance.(*box.Box)
+	return sbInstance
+}
+
+// CacheFile returns the cache file of the running instance, or nil if it has none
+func (m *Manager) CacheFile() adapter.CacheFile {
+	if m.ctx == nil {
+		return nil
+	}
+	return service.FromContext[adapter.CacheFile](m.ctx)
+}
+
+// Context returns the service context of the running instance, for components added to it, or nil
+func (m *Manager) Context() context.Context {
+	return m.ctx
+}
+
+// NewLogger returns a logger for a component added to the running instance, writing like the instance's own
+func (m *Manager) NewLogger(tag string) log.ContextLogger {
+	if m.logFactory == nil {
+		return log.NewNOPFactory().Logger()
+	}
+	return m.logFactory.NewLogger(tag)
+}
+
+// Options returns the options of the running instance, or nil
+func (m *Manager) Options() *option.Options {
+	return m.options
+}
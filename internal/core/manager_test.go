@@ -0,0 +1,216 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	box "github.com/sagernet/sing-box"
+	option "github.com/sagernet/sing-box/option"
+)
+
+// stubInstance stands in for a sing-box instance
+type stubInstance struct {
+	startErr error
+	closeErr error
+	block    bool // Start waits until Close is called
+	closed   chan struct{}
+	closes   int
+}
+
+func newStubInstance() *stubInstance {
+	return &stubInstance{closed: make(chan struct{})}
+}
+
+func (s *stubInstance) Start() error {
+	if s.block {
+		<-s.closed
+		return errors.New("closed while starting")
+	}
+	return s.startErr
+}
+
+func (s *stubInstance) Close() error {
+	if s.closes == 0 {
+		close(s.closed)
+	}
+	s.closes++
+	return s.closeErr
+}
+
+// useStub makes the manager create stub instead of a sing-box instance until the test ends
+func useStub(t *testing.T, stub *stubInstance, createErr error) {
+	original := newInstance
+	newInstance = func(box.Options) (instance, error) {
+		if createErr != nil {
+			return nil, createErr
+		}
+		return stub, nil
+	}
+	t.Cleanup(func() { newInstance = original })
+}
+
+// testOptions returns options with logging disabled
+func testOptions() *option.Options {
+	return &option.Options{Log: &option.LogOptions{Disabled: true}}
+}
+
+func TestManagerStart(t *testing.T) {
+	tests := []struct {
+		name        string
+		stub        *stubInstance
+		createErr   error
+		running     bool // An instance is already running
+		cancel      bool // The context ends before the instance has started
+		wantErr     bool
+		wantRunning bool
+		wantCloses  int
+		wantPrepare bool
+	}{
+		{
+			name:        "started",
+			stub:        newStubInstance(),
+			wantRunning: true,
+			wantPrepare: true,
+		},
+		{
+			name:    "already running",
+			stub:    newStubInstance(),
+			running: true,
+			wantErr: true,
+			// The running instance is kept
+			wantRunning: true,
+		},
+		{
+			name:      "creation fails",
+			stub:      newStubInstance(),
+			createErr: errors.New("invalid options"),
+			wantErr:   true,
+		},
+		{
+			name:        "start fails",
+			stub:        &stubInstance{startErr: errors.New("address in use"), closed: make(chan struct{})},
+			wantErr:     true,
+			wantCloses:  1,
+			wantPrepare: true,
+		},
+		{
+			name:        "context ends while starting",
+			stub:        &stubInstance{block: true, closed: make(chan struct{})},
+			cancel:      true,
+			wantErr:     true,
+			wantCloses:  1,
+			wantPrepare: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m Manager
+			if tt.running {
+				useStub(t, newStubInstance(), nil)
+				if err := m.Start(context.Background(), testOptions(), nil); err != nil {
+					t.Fatalf("Start() of the first instance error = %v", err)
+				}
+			}
+			useStub(t, tt.stub, tt.createErr)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			if tt.cancel {
+				cancel()
+			} else {
+				defer cancel()
+			}
+			prepared := false
+			err := m.Start(ctx, testOptions(), func(*box.Box) { prepared = true })
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Start() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.cancel && !errors.Is(err, context.Canceled) {
+				t.Errorf("Start() error = %v, want the context error", err)
+			}
+			if m.Running() != tt.wantRunning {
+				t.Errorf("Running() = %v, want %v", m.Running(), tt.wantRunning)
+			}
+			if (m.Options() != nil) != tt.wantRunning || (m.Context() != nil) != tt.wantRunning {
+				t.Errorf("Options() = %v, Context() = %v, want them set only while running", m.Options(), m.Context())
+			}
+			if prepared != tt.wantPrepare {
+				t.Errorf("prepare called = %v", prepared)
+			}
+			if tt.stub.closes != tt.wantCloses {
+				t.Errorf("Close() called %d times, want %d", tt.stub.closes, tt.wantCloses)
+			}
+		})
+	}
+}
+
+func TestManagerStop(t *testing.T) {
+	tests := []struct {
+		name        string
+		started     bool
+		closeErr    error
+		wantErr     bool
+		wantRunning bool
+	}{
+		{
+			name:    "not running",
+			wantErr: true,
+		},
+		{
+			name:    "stopped",
+			started: true,
+		},
+		{
+			name:     "close fails",
+			started:  true,
+			closeErr: errors.New("busy"),
+			wantErr:  true,
+			// The instance could not be closed and keeps running
+			wantRunning: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m Manager
+			stub := &stubInstance{closeErr: tt.closeErr, closed: make(chan struct{})}
+			useStub(t, stub, nil)
+			if tt.started {
+				if err := m.Start(context.Background(), testOptions(), nil); err != nil {
+					t.Fatalf("Start() error = %v", err)
+				}
+			}
+
+			err := m.Stop()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Stop() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if m.Running() != tt.wantRunning {
+				t.Errorf("Running() = %v, want %v", m.Running(), tt.wantRunning)
+			}
+			if !tt.wantRunning && (m.Options() != nil || m.Context() != nil || m.CacheFile() != nil) {
+				t.Error("the stopped manager still exposes the instance state")
+			}
+			if m.NewLogger("test") == nil {
+				t.Error("NewLogger() = nil")
+			}
+		})
+	}
+}
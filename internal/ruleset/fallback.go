@@ -0,0 +1,34 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package ruleset
+
+import "embed"
+
+// fallbackFiles holds compact snapshots of essential rule-sets in source format, named by tag.
+// They only cover the core of each list (e.g. the country's top-level domain), enough for sensible routing
+// until the full rule-set can be downloaded.
+//
+//go:embed fallback/*.json
+var fallbackFiles embed.FS
+
+// Fallback returns the bundled snapshot of the rule-set with the given tag, in sing-box source format
+func Fallback(tag string) ([]byte, bool) {
+	content, err := fallbackFiles.ReadFile("fallback/" + tag + ".json")
+	if err != nil {
+		return nil, false
+	}
+	return content, true
+}
@@ -0,0 +1,472 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package ruleset keeps the rule-set files listed in the export config up to date
+package ruleset
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ExportConfig holds the structure for the export config file
+type ExportConfig struct {
+	Interval int               `json:"interval"` // Update interval in days
+	URLs     map[string]string `json:"urls"`     // Download URL of each rule-set file
+}
+
+// ErrNotAllowed is returned for URLs outside the allowlist of a downloader
+var ErrNotAllowed = errors.New("URL is not in the ruleset source allowlist")
+
+// Source is a scheme and host rule-sets may be downloaded from
+type Source struct {
+	Scheme string
+	Host   string // Also matches its subdomains, "*" matches any host
+}
+
+// String returns the source as scheme://host
+func (s Source) String() string {
+	return s.Scheme + "://" + s.Host
+}
+
+// ParseSource parses a source given as "scheme://host" or a bare host, which defaults to HTTPS
+func ParseSource(value string) (Source, error) {
+	scheme, host, found := strings.Cut(value, "://")
+	if !found {
+		scheme, host = "https", value
+	}
+	source := Source{Scheme: strings.ToLower(scheme), Host: strings.ToLower(strings.TrimSuffix(host, "/"))}
+	if source.Scheme != "https" && source.Scheme != "http" {
+		return Source{}, fmt.Errorf("unsupported scheme %q, expected http or https", scheme)
+	}
+	if source.Host == "" || strings.ContainsAny(source.Host, "/?#") {
+		return Source{}, fmt.Errorf("invalid host %q", host)
+	}
+	return source, nil
+}
+
+// Allowlist restricts the URLs rule-set files are downloaded from
+type Allowlist []Source
+
+// DefaultAllowlist holds the official rule-set sources: GitHub, including the raw and release download
+// hosts it redirects to, and the jsDelivr CDN mirroring it
+var DefaultAllowlist = Allowlist{
+	{Scheme: "https", Host: "github.com"},
+	{Scheme: "https", Host: "githubusercontent.com"},
+	{Scheme: "https", Host: "jsdelivr.net"},
+}
+
+// Check returns ErrNotAllowed unless rawURL matches a source of the allowlist
+func (a Allowlist) Check(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	scheme, host := strings.ToLower(u.Scheme), strings.ToLower(u.Hostname())
+	for _, source := range a {
+		if source.Scheme != scheme {
+			continue
+		}
+		if source.Host == "*" || host == source.Host || strings.HasSuffix(host, "."+source.Host) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s://%s", ErrNotAllowed, scheme, host)
+}
+
+// Rejected returns the files of config whose URL is outside the allowlist, sorted by name
+func (a Allowlist) Rejected(config ExportConfig) []string {
+	var rejected []string
+	for filename, rawURL := range config.URLs {
+		if a.Check(rawURL) != nil {
+			rejected = append(rejected, filename)
+		}
+	}
+	sort.Strings(rejected)
+	return rejected
+}
+
+// Logger receives the progress of a download run
+type Logger interface {
+	Infof(format string, args ...any)
+	Warnf(format string, args ...any)
+	Errorf(format string, args ...any)
+}
+
+// LoadExportConfig reads the export config at path. A missing or empty file yields an empty config.
+func LoadExportConfig(path string, logger Logger) (ExportConfig, error) {
+	var config ExportConfig
+
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		logger.Warnf("Export config not found at %s, skipping...", path)
+		return config, nil // Skip if the config doesn't exist
+	}
+	if err != nil {
+		logger.Errorf("Failed to read export config: %v", err)
+		return config, fmt.Errorf("failed to read export config: %w", err)
+	}
+
+	if len(content) == 0 {
+		logger.Warnf("Export config is empty, skipping...")
+		return config, nil
+	}
+
+	if err := json.Unmarshal(content, &config); err != nil {
+		logger.Errorf("Failed to parse export config: %v", err)
+		return ExportConfig{}, fmt.Errorf("failed to parse export config: %w", err)
+	}
+
+	if len(config.URLs) == 0 {
+		logger.Warnf("No URLs found in export config, skipping...")
+	}
+	return config, nil
+}
+
+// Downloader stores rule-set files in a directory, refreshing them once they are older than the export interval
+type Downloader struct {
+	Dir     string // Directory the rule-set files are stored in
+	Logger  Logger
+	Context context.Context // Cancels the downloads in progress, may be nil
+
+	// TrackTemp is called with the path of every temporary file before it is written. The returned function
+	// is called once the file has been renamed or removed, so an interrupted download can be cleaned up.
+	TrackTemp func(path string) (release func())
+
+	// Failures remembers the last download error of each file across downloaders, may be nil
+	Failures *Failures
+
+	// Allowlist restricts the URLs files are downloaded from, including redirects. Nil allows every URL.
+	Allowlist Allowlist
+
+	// Workers is the number of files downloaded at once, DefaultWorkers if not positive
+	Workers int
+}
+
+// DefaultWorkers is the number of files a downloader fetches at once by default
+const DefaultWorkers = 4
+
+// Failure is the last failed download of a file
+type Failure struct {
+	Err string    // Error of the download
+	At  time.Time // Time the download failed
+}
+
+// Failures records the last download error of each file. A successful download clears it.
+// The zero value is ready to use.
+type Failures struct {
+	mu     sync.Mutex
+	byName map[string]Failure
+}
+
+// record stores the outcome of a download of a file, nil on success
+func (f *Failures) record(name string, err error) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err == nil {
+		delete(f.byName, name)
+		return
+	}
+	if f.byName == nil {
+		f.byName = make(map[string]Failure)
+	}
+	f.byName[name] = Failure{Err: err.Error(), At: time.Now()}
+}
+
+// All returns the last download error of every file whose last download failed
+func (f *Failures) All() map[string]Failure {
+	if f == nil {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	failures := make(map[string]Failure, len(f.byName))
+	for name, failure := range f.byName {
+		failures[name] = failure
+	}
+	return failures
+}
+
+// Get returns the last download error of a file, if the last download failed
+func (f *Failures) Get(name string) (Failure, bool) {
+	if f == nil {
+		return Failure{}, false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	failure, found := f.byName[name]
+	return failure, found
+}
+
+// Update downloads the missing files of config and refreshes the outdated ones, Workers at a time.
+// A failed file is logged and skipped so a single broken URL does not block the others.
+// If the context ends, the remaining files are skipped and its error is returned.
+func (d *Downloader) Update(config ExportConfig) error {
+	if _, err := os.Stat(d.Dir); os.IsNotExist(err) {
+		if err := os.MkdirAll(d.Dir, os.ModePerm); err != nil {
+			return fmt.Errorf("failed to create ruleset directory: %w", err)
+		}
+		d.Logger.Infof("Created ruleset directory: %s", d.Dir)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		failedMu sync.Mutex
+		failed   []string
+	)
+	filenames := make(chan string)
+	for i := 0; i < min(d.workers(), len(config.URLs)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for filename := range filenames {
+				if !d.updateFile(config, filename) {
+					failedMu.Lock()
+					failed = append(failed, filename)
+					failedMu.Unlock()
+				}
+			}
+		}()
+	}
+
+queue:
+	for filename := range config.URLs {
+		select {
+		case filenames <- filename:
+		case <-d.context().Done():
+			break queue
+		}
+	}
+	close(filenames)
+	wg.Wait()
+
+	if err := d.context().Err(); err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		d.Logger.Warnf("%d of %d rulesets could not be downloaded: %s", len(failed), len(config.URLs), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// updateFile downloads a file of config if it is missing or outdated, and reports whether it is usable
+func (d *Downloader) updateFile(config ExportConfig, filename string) bool {
+	url := config.URLs[filename]
+	filePath := filepath.Join(d.Dir, filename)
+
+	fileInfo, err := os.Stat(filePath)
+	if os.IsNotExist(err) {
+		if err := d.Download(url, filePath); err != nil {
+			d.Logger.Errorf("Error downloading file %s: %v", filename, err)
+			return false
+		}
+		d.Logger.Infof("Downloaded file %s from %s", filename, url)
+		return true
+	} else if err != nil {
+		d.Logger.Errorf("Error checking file %s: %v", filename, err)
+		return false
+	}
+
+	if config.Interval <= 0 {
+		d.Logger.Infof("Skipping interval check for file %s due to invalid interval in config", filename)
+		return true
+	}
+
+	if !expired(config, fileInfo) {
+		d.Logger.Infof("File %s is up to date", filename)
+		return true
+	}
+	if err := d.Download(url, filePath); err != nil {
+		d.Logger.Errorf("Error updating file %s: %v", filename, err)
+		return false
+	}
+	d.Logger.Infof("Updated file %s from %s", filename, url)
+	return true
+}
+
+// workers returns the number of files downloaded at once
+func (d *Downloader) workers() int {
+	if d.Workers <= 0 {
+		return DefaultWorkers
+	}
+	return d.Workers
+}
+
+// context returns the context of the downloads
+func (d *Downloader) context() context.Context {
+	if d.Context == nil {
+		return context.Background()
+	}
+	return d.Context
+}
+
+// Stale returns the files of config that are missing or older than the export interval
+func (d *Downloader) Stale(config ExportConfig) []string {
+	var stale []string
+	for filename := range config.URLs {
+		fileInfo, err := os.Stat(filepath.Join(d.Dir, filename))
+		if err != nil || expired(config, fileInfo) {
+			stale = append(stale, filename)
+		}
+	}
+	sort.Strings(stale)
+	return stale
+}
+
+// expired reports whether a file is older than the export interval
+func expired(config ExportConfig, fileInfo os.FileInfo) bool {
+	return config.Interval > 0 && time.Since(fileInfo.ModTime()) > time.Duration(config.Interval)*24*time.Hour
+}
+
+// FileInfo describes a rule-set file managed by the export config
+type FileInfo struct {
+	Name       string    // File name in the ruleset directory
+	URL        string    // Download URL
+	Path       string    // Path of the file
+	Size       int64     // Size in bytes, 0 if missing
+	SHA256     string    // Hex-encoded SHA-256 of the content, empty if missing
+	Downloaded time.Time // Time the file was last written, zero if missing
+	Missing    bool      // Whether the file does not exist
+	Stale      bool      // Whether the file is missing or older than the export interval
+	LastError  *Failure  // Last download error, nil if the last download succeeded or none was tried
+}
+
+// Inspect describes the files of config, sorted by name
+func (d *Downloader) Inspect(config ExportConfig) []FileInfo {
+	var files []FileInfo
+	for filename, url := range config.URLs {
+		file := FileInfo{Name: filename, URL: url, Path: filepath.Join(d.Dir, filename)}
+		if failure, found := d.Failures.Get(filename); found {
+			file.LastError = &failure
+		}
+
+		fileInfo, err := os.Stat(file.Path)
+		if err != nil {
+			file.Missing, file.Stale = true, true
+			files = append(files, file)
+			continue
+		}
+		file.Size = fileInfo.Size()
+		file.Downloaded = fileInfo.ModTime()
+		file.Stale = expired(config, fileInfo)
+		file.SHA256, _ = hashFile(file.Path)
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	return files
+}
+
+// hashFile returns the hex-encoded SHA-256 of a file
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Download fetches url into filePath through a temporary file, so a failed download never leaves a truncated file.
+// The outcome is recorded in Failures, except when the context ended.
+func (d *Downloader) Download(url, filePath string) error {
+	err := d.download(url, filePath)
+	if d.context().Err() == nil {
+		d.Failures.record(filepath.Base(filePath), err)
+	}
+	return err
+}
+
+// httpClient returns the client downloads are made with, refusing redirects outside the allowlist
+func (d *Downloader) httpClient() *http.Client {
+	if d.Allowlist == nil {
+		return http.DefaultClient
+	}
+	return &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			return d.Allowlist.Check(req.URL.String())
+		},
+	}
+}
+
+// download fetches url into filePath
+func (d *Downloader) download(url, filePath string) error {
+	if d.Allowlist != nil {
+		if err := d.Allowlist.Check(url); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(d.context(), http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	resp, err := d.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to get URL: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server returned non-200 status code: %d", resp.StatusCode)
+	}
+
+	tmpPath := filePath + ".tmp"
+	if d.TrackTemp != nil {
+		defer d.TrackTemp(tmpPath)()
+	}
+
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+
+	_, err = io.Copy(out, resp.Body)
+	out.Close()
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to copy response body: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace file: %w", err)
+	}
+	return nil
+}
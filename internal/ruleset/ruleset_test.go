@@ -0,0 +1,233 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package ruleset
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testLogger forwards the downloader's log to the test
+type testLogger struct{ t *testing.T }
+
+func (l testLogger) Infof(format string, args ...any)  { l.t.Logf(format, args...) }
+func (l testLogger) Warnf(format string, args ...any)  { l.t.Logf(format, args...) }
+func (l testLogger) Errorf(format string, args ...any) { l.t.Logf(format, args...) }
+
+func TestParseSource(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Source
+		wantErr bool
+	}{
+		{value: "rules.example.com", want: Source{Scheme: "https", Host: "rules.example.com"}},
+		{value: "HTTP://Rules.Example.com/", want: Source{Scheme: "http", Host: "rules.example.com"}},
+		{value: "*", want: Source{Scheme: "https", Host: "*"}},
+		{value: "ftp://example.com", wantErr: true},
+		{value: "https://", wantErr: true},
+		{value: "example.com/rules", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			source, err := ParseSource(test.value)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("error %v, want error %t", err, test.wantErr)
+			}
+			if source != test.want {
+				t.Errorf("source %+v, want %+v", source, test.want)
+			}
+		})
+	}
+}
+
+func TestAllowlistCheck(t *testing.T) {
+	allowlist := append(Allowlist{{Scheme: "http", Host: "192.168.1.10"}}, DefaultAllowlist...)
+	tests := []struct {
+		url     string
+		allowed bool
+	}{
+		{url: "https://raw.githubusercontent.com/SagerNet/sing-geosite/rule-set/geosite-cn.srs", allowed: true},
+		{url: "https://github.com/SagerNet/sing-geoip/releases/download/latest/geoip-ir.srs", allowed: true},
+		{url: "https://cdn.jsdelivr.net/gh/SagerNet/sing-geosite@rule-set/geosite-ir.srs", allowed: true},
+		{url: "HTTPS://GITHUB.COM/rules.srs", allowed: true},
+		{url: "http://192.168.1.10:8080/rules.srs", allowed: true},
+		{url: "http://github.com/rules.srs", allowed: false},
+		{url: "https://192.168.1.10/rules.srs", allowed: false},
+		{url: "https://notgithub.com/rules.srs", allowed: false},
+		{url: "https://github.com.example.com/rules.srs", allowed: false},
+		{url: "file:///etc/shadow", allowed: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			err := allowlist.Check(test.url)
+			if test.allowed && err != nil {
+				t.Errorf("rejected: %v", err)
+			}
+			if !test.allowed && !errors.Is(err, ErrNotAllowed) {
+				t.Errorf("error %v, want ErrNotAllowed", err)
+			}
+		})
+	}
+
+	if err := (Allowlist{{Scheme: "https", Host: "*"}}).Check("https://anything.example/rules.srs"); err != nil {
+		t.Errorf("wildcard host rejected: %v", err)
+	}
+}
+
+func TestDownloaderUpdate(t *testing.T) {
+	var requestsMu sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestsMu.Lock()
+		requests = append(requests, r.URL.Path)
+		requestsMu.Unlock()
+		switch r.URL.Path {
+		case "/missing.srs":
+			http.NotFound(w, r)
+		case "/redirect.srs":
+			http.Redirect(w, r, "https://elsewhere.example/a.srs", http.StatusFound)
+		default:
+			w.Write([]byte("content of " + r.URL.Path))
+		}
+	}))
+	defer server.Close()
+	local := Allowlist{{Scheme: "http", Host: "127.0.0.1"}}
+
+	tests := []struct {
+		name       string
+		urls       map[string]string
+		existing   map[string]time.Duration // Files already on disk, by age
+		allowlist  Allowlist
+		workers    int
+		wantFiles  map[string]string
+		wantFailed []string
+		wantFetch  []string
+	}{
+		{
+			name:      "missing files are downloaded concurrently",
+			urls:      map[string]string{"a.srs": server.URL + "/a.srs", "b.srs": server.URL + "/b.srs", "c.srs": server.URL + "/c.srs"},
+			workers:   2,
+			wantFiles: map[string]string{"a.srs": "content of /a.srs", "b.srs": "content of /b.srs", "c.srs": "content of /c.srs"},
+			wantFetch: []string{"/a.srs", "/b.srs", "/c.srs"},
+		},
+		{
+			name:      "fresh files are kept and stale ones refreshed",
+			urls:      map[string]string{"fresh.srs": server.URL + "/fresh.srs", "stale.srs": server.URL + "/stale.srs"},
+			existing:  map[string]time.Duration{"fresh.srs": time.Hour, "stale.srs": 48 * time.Hour},
+			wantFiles: map[string]string{"fresh.srs": "old", "stale.srs": "content of /stale.srs"},
+			wantFetch: []string{"/stale.srs"},
+		},
+		{
+			name:       "a failed file does not block the others",
+			urls:       map[string]string{"a.srs": server.URL + "/a.srs", "missing.srs": server.URL + "/missing.srs"},
+			wantFiles:  map[string]string{"a.srs": "content of /a.srs"},
+			wantFailed: []string{"missing.srs"},
+			wantFetch:  []string{"/a.srs", "/missing.srs"},
+		},
+		{
+			name:       "URLs outside the allowlist are not fetched",
+			urls:       map[string]string{"a.srs": server.URL + "/a.srs"},
+			allowlist:  DefaultAllowlist,
+			wantFailed: []string{"a.srs"},
+		},
+		{
+			name:       "redirects outside the allowlist are refused",
+			urls:       map[string]string{"redirect.srs": server.URL + "/redirect.srs"},
+			allowlist:  local,
+			wantFailed: []string{"redirect.srs"},
+			wantFetch:  []string{"/redirect.srs"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			requests = nil
+			dir := t.TempDir()
+			for name, age := range test.existing {
+				path := filepath.Join(dir, name)
+				if err := os.WriteFile(path, []byte("old"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				modTime := time.Now().Add(-age)
+				if err := os.Chtimes(path, modTime, modTime); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			failures := &Failures{}
+			downloader := &Downloader{Dir: dir, Logger: testLogger{t}, Failures: failures, Allowlist: test.allowlist, Workers: test.workers}
+			if err := downloader.Update(ExportConfig{Interval: 1, URLs: test.urls}); err != nil {
+				t.Fatalf("Update: %v", err)
+			}
+
+			for name, want := range test.wantFiles {
+				content, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil || string(content) != want {
+					t.Errorf("%s holds %q (%v), want %q", name, content, err, want)
+				}
+			}
+			var failed []string
+			for name := range failures.All() {
+				failed = append(failed, name)
+			}
+			if !sameSet(failed, test.wantFailed) {
+				t.Errorf("failed files %v, want %v", failed, test.wantFailed)
+			}
+			if !sameSet(requests, test.wantFetch) {
+				t.Errorf("fetched %v, want %v", requests, test.wantFetch)
+			}
+			if entries, _ := os.ReadDir(dir); hasTemp(entries) {
+				t.Error("temporary files left behind")
+			}
+		})
+	}
+}
+
+// sameSet reports whether two lists hold the same strings in any order
+func sameSet(a, b []string) bool {
+	count := make(map[string]int)
+	for _, s := range a {
+		count[s]++
+	}
+	for _, s := range b {
+		count[s]--
+	}
+	for _, n := range count {
+		if n != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// hasTemp reports whether a download left a temporary file
+func hasTemp(entries []os.DirEntry) bool {
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".tmp") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,242 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package client is a small Go client for the Oblivion-Helper gRPC API, used by the helper's own
+// subcommands and by other Go programs controlling the helper
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultAddress is the address the helper listens on
+const DefaultAddress = "127.0.0.1:50051"
+
+// Defaults for retrying calls while the helper is unreachable
+const (
+	defaultRetries      = 3
+	defaultRetryBackoff = 500 * time.Millisecond
+	maxRetryBackoff     = 10 * time.Second
+)
+
+// Client controls a running helper. All RPCs of the service are available through the embedded client.
+type Client struct {
+	pb.OblivionServiceClient
+	conn    *grpc.ClientConn
+	options options
+}
+
+// Status is an update received from the status stream
+type Status struct {
+	Status string
+	Detail string
+}
+
+type options struct {
+	retries      int
+	retryBackoff time.Duration
+	dialOptions  []grpc.DialOption
+	tlsDir       string
+}
+
+// Option customizes a client created by Dial
+type Option func(*options)
+
+// WithRetries sets how often a call failing with Unavailable is retried, and the initial delay between attempts
+// (doubled after every attempt). Zero retries disables retrying. Start, Stop, Restart, Reload, Exit, Pause and
+// Resume are never retried.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(o *options) {
+		o.retries = retries
+		o.retryBackoff = backoff
+	}
+}
+
+// WithDialOptions adds gRPC dial options
+func WithDialOptions(dialOptions ...grpc.DialOption) Option {
+	return func(o *options) {
+		o.dialOptions = append(o.dialOptions, dialOptions...)
+	}
+}
+
+// WithTLS connects over TLS for helpers started with -tls, using the ca.crt, client.crt and client.key files
+// written to dir by the "tls-export" command
+func WithTLS(dir string) Option {
+	return func(o *options) {
+		o.tlsDir = dir
+	}
+}
+
+// Dial connects to the helper. The target is a TCP address ("127.0.0.1:50051"), a unix socket ("unix:///path")
+// or, on Windows, a named pipe ("pipe:\\.\pipe\name"). An empty target dials DefaultAddress.
+func Dial(target string, opts ...Option) (*Client, error) {
+	o := options{retries: defaultRetries, retryBackoff: defaultRetryBackoff}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if target == "" {
+		target = DefaultAddress
+	}
+
+	creds := insecure.NewCredentials()
+	if o.tlsDir != "" {
+		var err error
+		if creds, err = tlsCredentials(o.tlsDir); err != nil {
+			return nil, err
+		}
+	}
+
+	dialOptions := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithChainUnaryInterceptor(retryInterceptor(o.retries, o.retryBackoff)),
+	}
+	if pipe, ok := strings.CutPrefix(target, "pipe:"); ok {
+		dialOptions = append(dialOptions, grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return dialPipe(ctx, pipe)
+		}))
+		target = "passthrough:///" + pipe
+	} else if !strings.HasPrefix(target, "unix:") {
+		target = "passthrough:///" + target
+	}
+	dialOptions = append(dialOptions, o.dialOptions...)
+
+	conn, err := grpc.NewClient(target, dialOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial helper: %w", err)
+	}
+
+	return &Client{
+		OblivionServiceClient: pb.NewOblivionServiceClient(conn),
+		conn:                  conn,
+		options:               o,
+	}, nil
+}
+
+// tlsCredentials loads the client credentials exported by the helper, trusting only its local CA
+func tlsCredentials(dir string) (credentials.TransportCredentials, error) {
+	certificate, err := tls.LoadX509KeyPair(filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client certificate: %w", err)
+	}
+	caPEM, err := os.ReadFile(filepath.Join(dir, "ca.crt"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+	}
+	rootCAs := x509.NewCertPool()
+	if !rootCAs.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("invalid CA certificate in %s", dir)
+	}
+
+	// The server certificate is issued for localhost and the loopback addresses; pipes and sockets have no
+	// host name, so the name to verify is fixed
+	return credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		RootCAs:      rootCAs,
+		ServerName:   "localhost",
+		MinVersion:   tls.VersionTLS13,
+	}), nil
+}
+
+// Close closes the connection to the helper
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// SubscribeStatus streams status updates until ctx is done, re-subscribing with backoff when the stream breaks.
+// Note that the helper stops sing-box when the last status stream closes, so keep a subscription open while connected.
+func (c *Client) SubscribeStatus(ctx context.Context) <-chan Status {
+	updates := make(chan Status)
+
+	go func() {
+		defer close(updates)
+
+		backoff := c.options.retryBackoff
+		for ctx.Err() == nil {
+			stream, err := c.OblivionServiceClient.StreamStatus(ctx, &pb.StatusRequest{})
+			if err == nil {
+				for {
+					resp, err := stream.Recv()
+					if err != nil {
+						break
+					}
+					backoff = c.options.retryBackoff
+
+					select {
+					case updates <- Status{Status: resp.Status, Detail: resp.Detail}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+
+			select {
+			case <-time.After(backoff):
+				backoff = min(backoff*2, maxRetryBackoff)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return updates
+}
+
+// nonIdempotentMethods are never retried: the helper may have acted on the call before it failed, and repeating it
+// would start, stop or switch the tunnel again
+var nonIdempotentMethods = map[string]bool{
+	pb.OblivionService_Start_FullMethodName:   true,
+	pb.OblivionService_Stop_FullMethodName:    true,
+	pb.OblivionService_Restart_FullMethodName: true,
+	pb.OblivionService_Reload_FullMethodName:  true,
+	pb.OblivionService_Exit_FullMethodName:    true,
+	pb.OblivionService_Pause_FullMethodName:   true,
+	pb.OblivionService_Resume_FullMethodName:  true,
+}
+
+// retryInterceptor retries idempotent unary calls that fail because the helper is not reachable (yet)
+func retryInterceptor(retries int, backoff time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if nonIdempotentMethods[method] {
+			return err
+		}
+		for attempt := 0; attempt < retries && status.Code(err) == codes.Unavailable; attempt++ {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return err
+			}
+			backoff = min(backoff*2, maxRetryBackoff)
+			err = invoker(ctx, method, req, reply, cc, opts...)
+		}
+		return err
+	}
+}
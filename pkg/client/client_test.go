@@ -0,0 +1,202 @@
+// Copyright (C) 2024 ShadowZagrosDev
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/ShadowZagrosDev/oblivion-helper/gRPC"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeHelper is an in-process helper whose calls fail with Unavailable a given number of times before succeeding
+type fakeHelper struct {
+	pb.UnimplementedOblivionServiceServer
+
+	mu       sync.Mutex
+	failures int            // Failures left for every method
+	calls    map[string]int // Calls received by method
+	statuses []string       // Statuses sent on StreamStatus
+}
+
+// call records a call and returns the error it fails with, if any
+func (f *fakeHelper) call(method string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls[method]++
+	if f.calls[method] <= f.failures {
+		return status.Error(codes.Unavailable, "helper is starting")
+	}
+	return nil
+}
+
+func (f *fakeHelper) GetStatus(ctx context.Context, req *pb.GetStatusRequest) (*pb.GetStatusResponse, error) {
+	if err := f.call("GetStatus"); err != nil {
+		return nil, err
+	}
+	return &pb.GetStatusResponse{Status: "stopped"}, nil
+}
+
+func (f *fakeHelper) Start(ctx context.Context, req *pb.StartRequest) (*pb.StartResponse, error) {
+	if err := f.call("Start"); err != nil {
+		return nil, err
+	}
+	return &pb.StartResponse{}, nil
+}
+
+func (f *fakeHelper) Pause(ctx context.Context, req *pb.PauseRequest) (*pb.PauseResponse, error) {
+	if err := f.call("Pause"); err != nil {
+		return nil, err
+	}
+	return &pb.PauseResponse{}, nil
+}
+
+func (f *fakeHelper) StreamStatus(req *pb.StatusRequest, stream pb.OblivionService_StreamStatusServer) error {
+	for _, name := range f.statuses {
+		if err := stream.Send(&pb.StatusResponse{Status: name}); err != nil {
+			return err
+		}
+	}
+	<-stream.Context().Done()
+	return nil
+}
+
+// serve starts the fake helper on a loopback port and returns a client connected to it
+func serve(t *testing.T, helper *fakeHelper, opts ...Option) *Client {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	pb.RegisterOblivionServiceServer(server, helper)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	c, err := Dial(lis.Addr().String(), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		call      func(ctx context.Context, c *Client) error
+		failures  int
+		wantCalls int
+		wantCode  codes.Code
+	}{
+		{
+			name:   "idempotent call retried until it succeeds",
+			method: "GetStatus",
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.GetStatus(ctx, &pb.GetStatusRequest{})
+				return err
+			},
+			failures:  2,
+			wantCalls: 3,
+			wantCode:  codes.OK,
+		},
+		{
+			name:   "idempotent call given up after the retries",
+			method: "GetStatus",
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.GetStatus(ctx, &pb.GetStatusRequest{})
+				return err
+			},
+			failures:  5,
+			wantCalls: 4,
+			wantCode:  codes.Unavailable,
+		},
+		{
+			name:   "start not retried",
+			method: "Start",
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.Start(ctx, &pb.StartRequest{})
+				return err
+			},
+			failures:  1,
+			wantCalls: 1,
+			wantCode:  codes.Unavailable,
+		},
+		{
+			name:   "pause not retried",
+			method: "Pause",
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.Pause(ctx, &pb.PauseRequest{})
+				return err
+			},
+			failures:  1,
+			wantCalls: 1,
+			wantCode:  codes.Unavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := &fakeHelper{failures: tt.failures, calls: make(map[string]int)}
+			c := serve(t, helper, WithRetries(3, time.Millisecond))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			err := tt.call(ctx, c)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("got code %v (%v), want %v", code, err, tt.wantCode)
+			}
+			helper.mu.Lock()
+			defer helper.mu.Unlock()
+			if got := helper.calls[tt.method]; got != tt.wantCalls {
+				t.Errorf("got %d calls, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestSubscribeStatus(t *testing.T) {
+	helper := &fakeHelper{calls: make(map[string]int), statuses: []string{"starting", "started"}}
+	c := serve(t, helper)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	updates := c.SubscribeStatus(ctx)
+	for _, want := range helper.statuses {
+		select {
+		case update := <-updates:
+			if update.Status != want {
+				t.Fatalf("got status %q, want %q", update.Status, want)
+			}
+		case <-ctx.Done():
+			t.Fatalf("no %q status received", want)
+		}
+	}
+
+	cancel()
+	for range updates {
+	}
+}